@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"checkout-go/internal/infrastructure/di"
+	"checkout-go/internal/usecases/abandonedcarts"
+)
+
+// The abandoned cart job marks ACCESSED checkouts that were not visited for
+// ABANDONED_CART_IDLE_THRESHOLD as ABANDONED_CART. It runs as a Lambda on an
+// EventBridge schedule, or locally with -once or -loop.
+func main() {
+	once := flag.Bool("once", false, "run the job once and exit instead of running as a Lambda")
+	loop := flag.Bool("loop", false, "run the job every ABANDONED_CART_INTERVAL until interrupted instead of running as a Lambda")
+	flag.Parse()
+
+	container, err := di.NewContainer()
+	if err != nil {
+		log.Fatalf("Failed to initialize DI container: %v", err)
+	}
+	defer container.Close()
+
+	config := container.GetConfig()
+	job := container.GetAbandonedCartsJob()
+
+	switch {
+	case *once:
+		result, err := run(context.Background(), job)
+		if err != nil {
+			log.Fatalf("Abandoned cart job failed: %v", err)
+		}
+		json.NewEncoder(os.Stdout).Encode(result)
+	case *loop:
+		log.Printf("Abandoned cart job running every %s - Environment: %s", config.AbandonedCartInterval, config.AppEnv)
+		runLoop(job, config.AbandonedCartInterval)
+	default:
+		log.Printf("Abandoned cart job initialized - Environment: %s", config.AppEnv)
+		lambda.Start(func(ctx context.Context, event events.CloudWatchEvent) (*abandonedcarts.Result, error) {
+			return run(ctx, job)
+		})
+	}
+}
+
+// run executes the job once and logs its result
+func run(ctx context.Context, job *abandonedcarts.Job) (*abandonedcarts.Result, error) {
+	result, err := job.Run(ctx)
+	log.Printf("Abandoned cart job: scanned %d, abandoned %d, skipped %d, failed %d, completed %t",
+		result.Scanned, result.Abandoned, result.Skipped, result.Failed, result.Completed)
+	return result, err
+}
+
+// runLoop runs the job right away and then on every tick until SIGINT or
+// SIGTERM; a run in progress is cancelled and resumes on the next start
+func runLoop(job *abandonedcarts.Job, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := run(ctx, job); err != nil && ctx.Err() == nil {
+			log.Printf("Abandoned cart job failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Abandoned cart job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"checkout-go/internal/config"
+	"checkout-go/internal/infrastructure/aws"
+	"checkout-go/internal/infrastructure/dynamodb"
+)
+
+const usage = `Usage: dbctl <command> [flags]
+
+Commands:
+  create-tables   create the service tables and indexes if they do not exist
+  verify          compare sampled items with the attribute names and types of the models
+  backfill-status-shards
+                  set the status index shard of checkouts written before it was sharded
+
+Run "dbctl <command> -h" for the flags of a command.
+`
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "create-tables":
+		err = createTables(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	case "backfill-status-shards":
+		err = backfillStatusShards(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("dbctl %s: %v", os.Args[1], err)
+	}
+}
+
+// createTables creates every table of TablesFor, skipping existing ones
+func createTables(args []string) error {
+	flags := flag.NewFlagSet("create-tables", flag.ExitOnError)
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local (default: AWS_ENDPOINT_URL_DYNAMODB)")
+	only := flags.String("tables", "", "comma-separated base table names to create (default: all)")
+	flags.Parse(args)
+
+	cfg, client, err := connect(*endpoint)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, table := range selectTables(cfg, *only) {
+		created, err := dynamodb.CreateTable(ctx, client, table)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("created %s (%d indexes)", table.Name, len(table.Indexes))
+		} else {
+			log.Printf("exists  %s", table.Name)
+		}
+	}
+
+	return nil
+}
+
+// verify reports the drift between the models and the items of every table
+func verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local (default: AWS_ENDPOINT_URL_DYNAMODB)")
+	only := flags.String("tables", "", "comma-separated base table names to verify (default: all)")
+	sample := flags.Int("sample", 100, "number of items to sample per table")
+	flags.Parse(args)
+
+	if *sample < 1 {
+		return fmt.Errorf("-sample must be at least 1")
+	}
+
+	cfg, client, err := connect(*endpoint)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	tables := selectTables(cfg, *only)
+	drifted := 0
+	for _, table := range tables {
+		report, err := dynamodb.DetectDrift(ctx, client, table, *sample)
+		if err != nil {
+			return err
+		}
+
+		if !report.HasDrift() {
+			log.Printf("ok      %s (%d items sampled)", report.Table, report.Sampled)
+			continue
+		}
+
+		drifted++
+		log.Printf("DRIFT   %s (%d items sampled)", report.Table, report.Sampled)
+		for _, drift := range report.Drifts {
+			log.Printf("          %s", drift)
+		}
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("drift found in %d of %d tables", drifted, len(tables))
+	}
+	return nil
+}
+
+// backfillStatusShards sets status_shard on the checkouts that lack it
+func backfillStatusShards(args []string) error {
+	flags := flag.NewFlagSet("backfill-status-shards", flag.ExitOnError)
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local (default: AWS_ENDPOINT_URL_DYNAMODB)")
+	flags.Parse(args)
+
+	cfg, client, err := connect(*endpoint)
+	if err != nil {
+		return err
+	}
+
+	tableName := aws.GetTableName(cfg, dynamodb.TableCheckouts)
+	updated, err := dynamodb.BackfillStatusShards(context.Background(), client, tableName)
+	log.Printf("updated %d checkouts in %s", updated, tableName)
+	return err
+}
+
+// selectTables returns the tables of TablesFor, restricted to the given
+// comma-separated base names when any are given
+func selectTables(cfg *config.Config, only string) []dynamodb.TableSchema {
+	selected := map[string]bool{}
+	for _, name := range strings.Split(only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[aws.GetTableName(cfg, name)] = true
+		}
+	}
+
+	var tables []dynamodb.TableSchema
+	for _, table := range dynamodb.TablesFor(cfg) {
+		if len(selected) == 0 || selected[table.Name] {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// connect loads the configuration and builds a DynamoDB client, optionally
+// pointed at a custom endpoint instead of the configured one
+func connect(endpoint string) (*config.Config, *dynamodb.Client, error) {
+	cfg, err := config.LoadForTools()
+	if err != nil {
+		return nil, nil, err
+	}
+	if endpoint != "" {
+		cfg.AWSDynamoDBEndpoint = endpoint
+	}
+	if err := cfg.ValidateTableNames(dynamodb.BaseTableNames(cfg)); err != nil {
+		return nil, nil, err
+	}
+
+	awsConfig, err := aws.NewConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize AWS config: %w", err)
+	}
+
+	// DynamoDB Local accepts any credentials but the SDK still needs some
+	endpoint = cfg.DynamoDBEndpoint()
+	if endpoint != "" && !cfg.HasDynamoDBCredentials() && os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		awsConfig.Credentials = credentials.NewStaticCredentialsProvider("local", "local", "")
+	}
+
+	log.Printf("AWS client settings: %s", aws.DescribeClientSettings(cfg))
+	client, err := dynamodb.NewClient(awsConfig, dynamodb.WithEndpoint(endpoint))
+	return cfg, client, err
+}
@@ -36,6 +36,10 @@ func main() {
 	}
 	log.Println("ShowCheckoutUseCase retrieved successfully")
 
+	// Start the cache invalidation poller
+	container.StartBackgroundWork()
+	defer container.Close()
+
 	// Set gin mode based on configuration
 	gin.SetMode(config.GetGinMode())
 
@@ -67,6 +71,7 @@ func main() {
 	go func() {
 		log.Printf("Server starting on port %s", config.Port)
 		log.Printf("Environment: %s", config.AppEnv)
+		log.Printf("Storage backend: %s", config.StorageBackend)
 		log.Printf("Gin mode: %s", config.GetGinMode())
 		
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -87,7 +92,7 @@ func main() {
 
 	// Shutdown server
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Server forced to shutdown: %v", err)
 	}
 
 	log.Println("Server exiting")
@@ -106,8 +111,19 @@ func setupRoutes(router *gin.Engine, handlers *handlers.CheckoutHandlers) {
 		{
 			checkout.GET("/:uuid", handlers.ShowCheckout)
 		}
+
+		// Routes addressing a checkout by its own UUID
+		checkouts := v1.Group("/checkouts")
+		{
+			checkouts.PATCH("/:uuid", handlers.CaptureLead)
+			checkouts.POST("/:uuid/coupon", handlers.ApplyCoupon)
+		}
 	}
 
 	// Root checkout route for backward compatibility
 	router.GET("/checkout/:uuid", handlers.ShowCheckout)
+
+	// Root lead capture route, next to the root checkout route
+	router.PATCH("/checkouts/:uuid", handlers.CaptureLead)
+	router.POST("/checkouts/:uuid/coupon", handlers.ApplyCoupon)
 } 
\ No newline at end of file
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	stdErrors "errors"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -13,6 +17,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"checkout-go/internal/infrastructure/di"
+	"checkout-go/internal/usecases/capturelead"
 	"checkout-go/internal/usecases/showcheckout"
 	"checkout-go/pkg/serverless"
 )
@@ -40,6 +45,32 @@ func init() {
 func handleCheckoutEntrypoint(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log.Printf("Processing request for path: %s", event.Path)
 
+	// Apply catalog changes published since the previous invocation, before
+	// any route reads the cached catalog
+	container.PollInvalidations(ctx)
+
+	// PATCH /checkouts/{checkoutUuid} captures the buyer's details
+	if isCheckoutsRoute(event.Path) {
+		if event.HTTPMethod != http.MethodPatch {
+			return methodNotAllowed(http.MethodPatch), nil
+		}
+		return handleCaptureLead(ctx, event), nil
+	}
+
+	// POST /checkouts/{checkoutUuid}/coupon applies a coupon code
+	if isCheckoutsRoute(event.Path, "coupon") {
+		if event.HTTPMethod != http.MethodPost {
+			return methodNotAllowed(http.MethodPost), nil
+		}
+		return handleApplyCoupon(ctx, event), nil
+	}
+
+	// Every other path shows the checkout of an offer, which is only read
+	if event.HTTPMethod == http.MethodPatch || event.HTTPMethod == http.MethodPost {
+		log.Printf("No %s route for path: %s", event.HTTPMethod, event.Path)
+		return serverless.SendErrorJSON(fmt.Errorf("route not found"), 404), nil
+	}
+
 	// Initialize DI container
 	// container, err := di.NewContainer() // This line is removed as per the new_code
 	// if err != nil {
@@ -47,6 +78,9 @@ func handleCheckoutEntrypoint(ctx context.Context, event events.APIGatewayProxyR
 	// 	return serverless.SendErrorJSON(fmt.Errorf("internal server error"), 500), nil
 	// }
 
+	// Write the checkout counts buffered by this invocation before it returns
+	defer container.FlushCheckoutCounters(ctx)
+
 	// Get use case from container
 	useCase := container.GetShowCheckoutUseCase()
 
@@ -96,7 +130,137 @@ func handleCheckoutEntrypoint(ctx context.Context, event events.APIGatewayProxyR
 	}
 
 	log.Printf("Successfully processed checkout request for offer: %s", offerUUID)
-	return serverless.SendJSON(result, 200), nil
+	response := serverless.SendJSON(result, 200)
+
+	// Let the next visit resume this checkout
+	if result.CheckoutCookie != nil {
+		response.Headers["Set-Cookie"] = result.CheckoutCookie.HTTPCookie().String()
+	}
+	return response, nil
+}
+
+// handleCaptureLead stores the buyer's details sent in the body on the
+// checkout named in the path
+func handleCaptureLead(ctx context.Context, event events.APIGatewayProxyRequest) events.APIGatewayProxyResponse {
+	checkoutUUID := event.PathParameters["checkoutUuid"]
+	if checkoutUUID == "" {
+		checkoutUUID = extractUUIDFromPath(event.Path)
+	}
+	if checkoutUUID == "" {
+		log.Printf("Missing checkoutUuid in path: %s, pathParams: %v", event.Path, event.PathParameters)
+		return serverless.SendErrorJSON(fmt.Errorf("missing checkoutUuid parameter"), 400)
+	}
+
+	body, err := requestBody(event)
+	if err != nil {
+		return serverless.SendErrorJSON(fmt.Errorf("invalid request body: %v", err), 400)
+	}
+
+	var req capturelead.CaptureLeadRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Printf("Failed to parse request body: %v", err)
+		return serverless.SendErrorJSON(fmt.Errorf("invalid request body: %v", err), 400)
+	}
+	req.CheckoutUUID = checkoutUUID
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		log.Printf("Request validation failed: %v", err)
+		return serverless.SendErrorJSON(fmt.Errorf("validation failed: %v", err), 400)
+	}
+
+	result, err := container.GetCaptureLeadUseCase().Execute(ctx, &req)
+	if err != nil {
+		log.Printf("Lead capture failed: %v", err)
+		return serverless.SendErrorJSON(err, errorStatus(err, 400))
+	}
+
+	log.Printf("Captured lead for checkout: %s", checkoutUUID)
+	return serverless.SendJSON(result, 200)
+}
+
+// handleApplyCoupon applies the coupon code sent in the body to the checkout
+// named in the path and returns the recalculated prices
+func handleApplyCoupon(ctx context.Context, event events.APIGatewayProxyRequest) events.APIGatewayProxyResponse {
+	checkoutUUID := event.PathParameters["checkoutUuid"]
+	if checkoutUUID == "" {
+		checkoutUUID = extractUUIDFromPath(event.Path)
+	}
+	if checkoutUUID == "" {
+		log.Printf("Missing checkoutUuid in path: %s, pathParams: %v", event.Path, event.PathParameters)
+		return serverless.SendErrorJSON(fmt.Errorf("missing checkoutUuid parameter"), 400)
+	}
+
+	body, err := requestBody(event)
+	if err != nil {
+		return serverless.SendErrorJSON(fmt.Errorf("invalid request body: %v", err), 400)
+	}
+
+	var req showcheckout.ApplyCouponRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Printf("Failed to parse request body: %v", err)
+		return serverless.SendErrorJSON(fmt.Errorf("invalid request body: %v", err), 400)
+	}
+	req.CheckoutUUID = checkoutUUID
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		log.Printf("Request validation failed: %v", err)
+		return serverless.SendErrorJSON(fmt.Errorf("validation failed: %v", err), 400)
+	}
+
+	result, err := container.GetShowCheckoutUseCase().ApplyCoupon(ctx, &req)
+	if err != nil {
+		log.Printf("Coupon application failed: %v", err)
+		return serverless.SendErrorJSON(err, errorStatus(err, 400))
+	}
+
+	log.Printf("Applied coupon %s to checkout: %s", result.Coupon.Code, checkoutUUID)
+	return serverless.SendJSON(result, 200)
+}
+
+// isCheckoutsRoute reports whether path ends in /checkouts/{checkoutUuid}
+// followed by the given segments, so that it matches behind any base path
+// such as /api/v1
+func isCheckoutsRoute(path string, segments ...string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	start := len(parts) - len(segments) - 2
+	if start < 0 || parts[start] != "checkouts" || extractUUIDFromPath(parts[start+1]) == "" {
+		return false
+	}
+	for i, segment := range segments {
+		if parts[start+2+i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// methodNotAllowed answers a request for a known path sent with another
+// method than allowed
+func methodNotAllowed(allowed string) events.APIGatewayProxyResponse {
+	response := serverless.SendErrorJSON(fmt.Errorf("method not allowed"), 405)
+	response.Headers["Allow"] = allowed
+	return response
+}
+
+// requestBody returns the body of the event, decoding it when API Gateway
+// sent it base64 encoded
+func requestBody(event events.APIGatewayProxyRequest) ([]byte, error) {
+	if !event.IsBase64Encoded {
+		return []byte(event.Body), nil
+	}
+	return base64.StdEncoding.DecodeString(event.Body)
+}
+
+// errorStatus returns the HTTP status of a core error, or fallback for any
+// other error
+func errorStatus(err error, fallback int) int {
+	var coreErr interface{ GetHTTPCode() int }
+	if stdErrors.As(err, &coreErr) {
+		return coreErr.GetHTTPCode()
+	}
+	return fallback
 }
 
 // buildShowCheckoutRequest constructs the request from Lambda event data
@@ -189,6 +353,21 @@ func buildShowCheckoutRequest(offerUUID string, queryParams map[string]string, h
 	if clickId := queryParams["clickId"]; clickId != "" {
 		req.ClickID = &clickId
 	}
+	if checkoutUUID := queryParams["checkout"]; checkoutUUID != "" {
+		req.CheckoutUUID = &checkoutUUID
+	}
+	if recoveryToken := queryParams["recovery"]; recoveryToken != "" {
+		req.RecoveryToken = &recoveryToken
+	}
+	if prefillToken := queryParams["prefill"]; prefillToken != "" {
+		req.PrefillToken = &prefillToken
+	}
+	if coupon := queryParams["coupon"]; coupon != "" {
+		req.Coupon = &coupon
+	}
+	if planUUID := queryParams["plan"]; planUUID != "" {
+		req.PlanUUID = &planUUID
+	}
 	if originalUrl := queryParams["originalUrl"]; originalUrl != "" {
 		// Validate URL
 		if _, err := url.Parse(originalUrl); err == nil {
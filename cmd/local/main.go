@@ -16,6 +16,7 @@ import (
 )
 
 var validate *validator.Validate
+var container *di.Container
 
 func init() {
 	validate = validator.New()
@@ -26,7 +27,8 @@ func main() {
 	
 	// Initialize dependency injection container (which loads configuration)
 	log.Println("Initializing dependency injection container...")
-	container, err := di.NewContainer()
+	var err error
+	container, err = di.NewContainer()
 	if err != nil {
 		log.Fatalf("Failed to initialize DI container: %v", err)
 	}
@@ -42,6 +44,10 @@ func main() {
 	}
 	log.Println("ShowCheckoutUseCase retrieved successfully")
 	
+	// Start the cache invalidation poller
+	container.StartBackgroundWork()
+	defer container.Close()
+	
 	http.HandleFunc("/checkout/", handleCheckout)
 	http.HandleFunc("/health", handleHealth)
 	
@@ -50,6 +56,7 @@ func main() {
 	log.Println("  - GET /checkout/{uuid}")
 	log.Println("")
 	log.Printf("Environment: %s", config.AppEnv)
+	log.Printf("Storage backend: %s", config.StorageBackend)
 	log.Printf("Server running at http://localhost:%s", config.Port)
 	log.Printf("Example: http://localhost:%s/checkout/123e4567-e89b-12d3-a456-426614174000", config.Port)
 	log.Println("")
@@ -93,14 +100,6 @@ func handleCheckout(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Processing request for offer: %s", path)
 
-	// Initialize DI container
-	container, err := di.NewContainer()
-	if err != nil {
-		log.Printf("Failed to initialize DI container: %v", err)
-		sendError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
 	// Get use case from container
 	useCase := container.GetShowCheckoutUseCase()
 
@@ -127,6 +126,11 @@ func handleCheckout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Let the next visit resume this checkout
+	if result.CheckoutCookie != nil {
+		http.SetCookie(w, result.CheckoutCookie.HTTPCookie())
+	}
+
 	// Send response
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(result); err != nil {
@@ -223,6 +227,21 @@ func buildRequestFromHTTP(offerUUID string, r *http.Request) (*showcheckout.Show
 	if clickId := queryParams.Get("clickId"); clickId != "" {
 		req.ClickID = &clickId
 	}
+	if checkoutUUID := queryParams.Get("checkout"); checkoutUUID != "" {
+		req.CheckoutUUID = &checkoutUUID
+	}
+	if recoveryToken := queryParams.Get("recovery"); recoveryToken != "" {
+		req.RecoveryToken = &recoveryToken
+	}
+	if prefillToken := queryParams.Get("prefill"); prefillToken != "" {
+		req.PrefillToken = &prefillToken
+	}
+	if coupon := queryParams.Get("coupon"); coupon != "" {
+		req.Coupon = &coupon
+	}
+	if planUUID := queryParams.Get("plan"); planUUID != "" {
+		req.PlanUUID = &planUUID
+	}
 	if originalUrl := queryParams.Get("originalUrl"); originalUrl != "" {
 		// Validate URL
 		if _, err := url.Parse(originalUrl); err == nil {
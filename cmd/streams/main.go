@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"checkout-go/internal/infrastructure/di"
+	"checkout-go/internal/infrastructure/invalidation"
+)
+
+// The streams consumer subscribes to the DynamoDB Streams of the catalog
+// tables and publishes one invalidation event per changed record. Locally,
+// -event replays a captured stream batch against the in-process transport.
+func main() {
+	eventFile := flag.String("event", "", "replay a DynamoDB Streams event from a JSON file instead of running as a Lambda")
+	flag.Parse()
+
+	container, err := di.NewContainer()
+	if err != nil {
+		log.Fatalf("Failed to initialize DI container: %v", err)
+	}
+	defer container.Close()
+
+	config := container.GetConfig()
+	publisher := container.GetInvalidationPublisher()
+	if publisher == nil {
+		log.Fatalf("Cache invalidation is disabled (INVALIDATION_TRANSPORT=%s)", config.InvalidationTransport)
+	}
+	handler := invalidation.NewStreamHandler(publisher, container.StreamTableResolver())
+
+	if *eventFile == "" {
+		log.Printf("Streams consumer initialized - Environment: %s, transport: %s", config.AppEnv, config.InvalidationTransport)
+		lambda.Start(handler.Handle)
+		return
+	}
+
+	if err := replay(handler, *eventFile); err != nil {
+		log.Fatalf("Failed to replay stream event: %v", err)
+	}
+}
+
+// replay feeds a captured stream batch to the handler
+func replay(handler *invalidation.StreamHandler, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var event events.DynamoDBEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+
+	log.Printf("Replaying %d stream records from %s", len(event.Records), path)
+	return handler.Handle(context.Background(), event)
+}
@@ -2,11 +2,61 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Supported storage backends
+const (
+	StorageBackendDynamoDB = "dynamodb"
+	StorageBackendMemory   = "memory"
+)
+
+// Supported table naming strategies
+const (
+	TableNamingNone   = "none"
+	TableNamingPrefix = "prefix"
+	TableNamingSuffix = "suffix"
+)
+
+// Supported AWS SDK retry modes
+const (
+	AWSRetryModeStandard = "standard"
+	AWSRetryModeAdaptive = "adaptive"
+)
+
+// tableOverridePrefix starts the per-table override variables (TABLE_OFFERS)
+const tableOverridePrefix = "TABLE_"
+
+// Supported cache invalidation transports
+const (
+	InvalidationTransportNone     = "none"
+	InvalidationTransportLocal    = "local"
+	InvalidationTransportDynamoDB = "dynamodb"
+)
+
+// Supported checkout counter modes
+const (
+	CheckoutCounterTransactional = "transactional"
+	CheckoutCounterAggregated    = "aggregated"
+)
+
+// minRecoveryTokenSecretLength is the shortest accepted recovery link secret,
+// the output size of HMAC-SHA256
+const minRecoveryTokenSecretLength = 32
+
+// CacheConfig configures the read-through cache of one catalog entity type
+type CacheConfig struct {
+	Enabled     bool
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
 // Config holds all application configuration
 type Config struct {
 	// Application Environment
@@ -17,6 +67,17 @@ type Config struct {
 	GinMode  string
 	LogLevel string
 
+	// Storage Configuration
+	StorageBackend string
+	FixturesPath   string
+
+	// Table naming: base names get the affix as a prefix or suffix, unless
+	// TableOverrides names the table explicitly
+	TableNaming        string
+	TableNameAffix     string
+	TableNameSeparator string
+	TableOverrides     map[string]string
+
 	// AWS Configuration
 	AWSRegion                    string
 	AWSDynamoDBAccessKeyID       string
@@ -24,10 +85,71 @@ type Config struct {
 	AWSS3Bucket                  string
 	AWSS3BasePath                string
 
+	// AWS client tuning: endpoint overrides (DynamoDB Local, LocalStack),
+	// retries and a timeout per operation covering all of its attempts
+	AWSEndpoint                  string
+	AWSDynamoDBEndpoint          string
+	AWSS3Endpoint                string
+	AWSRetryMode                 string
+	AWSMaxAttempts               int
+	AWSMaxBackoff                time.Duration
+	AWSOperationTimeout          time.Duration
+
 	// Google Pay Configuration
 	GooglePayMerchantIDD15 string
 	GooglePayMerchantIDD2  string
 
+	// ShowCheckout load budgets (0 means bounded by the request context only)
+	LoadBudgetRequired        time.Duration
+	LoadBudgetOrderBumps      time.Duration
+	LoadBudgetReviews         time.Duration
+	LoadBudgetPixels          time.Duration
+	LoadBudgetPlans           time.Duration
+	LoadBudgetDiscounts       time.Duration
+	LoadBudgetResponseReserve time.Duration
+
+	// Catalog cache configuration, per entity type
+	CacheOffers          CacheConfig
+	CacheProducts        CacheConfig
+	CacheUsers           CacheConfig
+	CacheCompanies       CacheConfig
+	CacheFormats         CacheConfig
+	CacheCheckoutConfigs CacheConfig
+
+	// Cache invalidation configuration
+	InvalidationTransport    string
+	InvalidationTable        string
+	InvalidationPollInterval time.Duration
+	InvalidationPollOverlap  time.Duration
+	InvalidationRetention    time.Duration
+
+	// Offer checkout counter: incremented in the checkout transaction, or
+	// buffered and flushed periodically, optionally sharded for hot offers
+	CheckoutCounterMode          string
+	CheckoutCounterFlushInterval time.Duration
+	CheckoutCounterShards        int
+	CheckoutCounterShardedOffers string
+
+	// Checkout resume window: a visitor's checkout of an offer is reused
+	// within it instead of creating a new one (0 disables resuming)
+	CheckoutResumeWindow time.Duration
+
+	// Abandoned cart job: ACCESSED checkouts not visited for the idle
+	// threshold are marked ABANDONED_CART, a batch at a time; the local loop
+	// runs the job every interval
+	AbandonedCartIdleThreshold time.Duration
+	AbandonedCartBatchSize     int
+	AbandonedCartInterval      time.Duration
+
+	// Cart recovery links: tokens are signed with the secret (empty disables
+	// recovery links) and expire after the TTL
+	RecoveryTokenSecret string
+	RecoveryTokenTTL    time.Duration
+
+	// Installments: monthly interest percentage charged on installments
+	// above the interest-free count, for companies without a rate of their own
+	InstallmentInterestRate float64
+
 	// Legacy Environment Variables (for backward compatibility)
 	Environment string // maps to AppEnv
 	S3Bucket    string // maps to AWSS3Bucket
@@ -35,6 +157,30 @@ type Config struct {
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
+	config := load()
+
+	// Validate required configuration
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return config, nil
+}
+
+// LoadForTools loads configuration for operational commands such as dbctl,
+// which only talk to DynamoDB and do not need the service-only settings
+func LoadForTools() (*Config, error) {
+	config := load()
+
+	if err := config.validateAWS(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return config, nil
+}
+
+// load reads the configuration without validating it
+func load() *Config {
 	config := &Config{
 		// Application defaults
 		AppEnv:   getEnvWithDefault("APP_ENV", getEnvWithDefault("ENVIRONMENT", "development")),
@@ -42,32 +188,193 @@ func Load() (*Config, error) {
 		GinMode:  getEnvWithDefault("GIN_MODE", ""),
 		LogLevel: getEnvWithDefault("LOG_LEVEL", "info"),
 
+		// Storage defaults
+		StorageBackend: strings.ToLower(getEnvWithDefault("STORAGE_BACKEND", StorageBackendDynamoDB)),
+		FixturesPath:   getEnvWithDefault("FIXTURES_PATH", "fixtures"),
+
+		// Table naming defaults (base names unchanged)
+		TableNaming:        strings.ToLower(getEnvWithDefault("TABLE_NAMING", TableNamingNone)),
+		TableNameSeparator: getEnvWithDefault("TABLE_NAME_SEPARATOR", "-"),
+		TableOverrides:     loadTableOverrides(),
+
 		// AWS defaults
 		AWSRegion:                    getEnvWithDefault("AWS_REGION", "us-east-1"),
 		AWSDynamoDBAccessKeyID:       os.Getenv("AWS_DYNAMODB_ACCESS_KEY_ID"),
 		AWSDynamoDBSecretAccessKey:   os.Getenv("AWS_DYNAMODB_SECRET_ACCESS_KEY"),
 		AWSS3Bucket:                  getEnvWithDefault("AWS_S3_BUCKET", getEnvWithDefault("S3_BUCKET", "")),
 		AWSS3BasePath:                os.Getenv("S3_BASE_PATH"),
+		AWSEndpoint:                  os.Getenv("AWS_ENDPOINT_URL"),
+		AWSDynamoDBEndpoint:          os.Getenv("AWS_ENDPOINT_URL_DYNAMODB"),
+		AWSS3Endpoint:                os.Getenv("AWS_ENDPOINT_URL_S3"),
+		AWSRetryMode:                 strings.ToLower(getEnvWithDefault("AWS_RETRY_MODE", AWSRetryModeStandard)),
+		AWSMaxAttempts:               getEnvInt("AWS_MAX_ATTEMPTS", 3),
+		AWSMaxBackoff:                getEnvDuration("AWS_MAX_BACKOFF", 20*time.Second),
+		AWSOperationTimeout:          getEnvDuration("AWS_OPERATION_TIMEOUT", 0),
 
 		// Google Pay defaults
 		GooglePayMerchantIDD15: os.Getenv("GOOGLE_PAY_MERCHANT_ID_D15"),
 		GooglePayMerchantIDD2:  os.Getenv("GOOGLE_PAY_MERCHANT_ID_D2"),
 
+		// Load budget defaults
+		LoadBudgetRequired:        getEnvDuration("LOAD_BUDGET_REQUIRED", 3*time.Second),
+		LoadBudgetOrderBumps:      getEnvDuration("LOAD_BUDGET_ORDER_BUMPS", 1500*time.Millisecond),
+		LoadBudgetReviews:         getEnvDuration("LOAD_BUDGET_REVIEWS", 800*time.Millisecond),
+		LoadBudgetPixels:          getEnvDuration("LOAD_BUDGET_PIXELS", 800*time.Millisecond),
+		LoadBudgetPlans:           getEnvDuration("LOAD_BUDGET_PLANS", 800*time.Millisecond),
+		LoadBudgetDiscounts:       getEnvDuration("LOAD_BUDGET_DISCOUNTS", 500*time.Millisecond),
+		LoadBudgetResponseReserve: getEnvDuration("LOAD_BUDGET_RESPONSE_RESERVE", 250*time.Millisecond),
+
+		// Catalog cache defaults
+		CacheOffers:          loadCacheConfig("OFFERS", 10000),
+		CacheProducts:        loadCacheConfig("PRODUCTS", 10000),
+		CacheUsers:           loadCacheConfig("USERS", 10000),
+		CacheCompanies:       loadCacheConfig("COMPANIES", 5000),
+		CacheFormats:         loadCacheConfig("FORMATS", 100),
+		CacheCheckoutConfigs: loadCacheConfig("CHECKOUT_CONFIGS", 10000),
+
+		// Cache invalidation defaults
+		InvalidationTransport:    strings.ToLower(os.Getenv("INVALIDATION_TRANSPORT")),
+		InvalidationTable:        getEnvWithDefault("INVALIDATION_TABLE", "cache_invalidations"),
+		InvalidationPollInterval: getEnvDuration("INVALIDATION_POLL_INTERVAL", 2*time.Second),
+		InvalidationPollOverlap:  getEnvDuration("INVALIDATION_POLL_OVERLAP", 30*time.Second),
+		InvalidationRetention:    getEnvDuration("INVALIDATION_RETENTION", time.Hour),
+
+		// Checkout counter defaults
+		CheckoutCounterMode:          strings.ToLower(getEnvWithDefault("CHECKOUT_COUNTER_MODE", CheckoutCounterTransactional)),
+		CheckoutCounterFlushInterval: getEnvDuration("CHECKOUT_COUNTER_FLUSH_INTERVAL", 5*time.Second),
+		CheckoutCounterShards:        getEnvInt("CHECKOUT_COUNTER_SHARDS", 10),
+		CheckoutCounterShardedOffers: os.Getenv("CHECKOUT_COUNTER_SHARDED_OFFERS"),
+
+		// Checkout resume defaults
+		CheckoutResumeWindow: getEnvDuration("CHECKOUT_RESUME_WINDOW", 24*time.Hour),
+
+		// Abandoned cart job defaults
+		AbandonedCartIdleThreshold: getEnvDuration("ABANDONED_CART_IDLE_THRESHOLD", time.Hour),
+		AbandonedCartBatchSize:     getEnvInt("ABANDONED_CART_BATCH_SIZE", 100),
+		AbandonedCartInterval:      getEnvDuration("ABANDONED_CART_INTERVAL", 5*time.Minute),
+
+		// Recovery link defaults
+		RecoveryTokenSecret: os.Getenv("RECOVERY_TOKEN_SECRET"),
+		RecoveryTokenTTL:    getEnvDuration("RECOVERY_TOKEN_TTL", 7*24*time.Hour),
+
+		// Installment defaults
+		InstallmentInterestRate: getEnvFloat("INSTALLMENT_INTEREST_RATE", 0),
+
 		// Legacy compatibility
 		Environment: getEnvWithDefault("ENVIRONMENT", getEnvWithDefault("APP_ENV", "development")),
 		S3Bucket:    getEnvWithDefault("S3_BUCKET", getEnvWithDefault("AWS_S3_BUCKET", "")),
 	}
 
-	// Validate required configuration
-	if err := config.validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	// Tables are named after the environment unless an affix is given
+	config.TableNameAffix = getEnvWithDefault("TABLE_NAME_AFFIX", config.AppEnv)
+
+	// The memory backend applies invalidations in process; DynamoDB deployments
+	// opt into the shared transport once the streams consumer is deployed
+	if config.InvalidationTransport == "" {
+		config.InvalidationTransport = InvalidationTransportNone
+		if config.UsesMemoryStorage() {
+			config.InvalidationTransport = InvalidationTransportLocal
+		}
 	}
 
-	return config, nil
+	return config
 }
 
 // validate checks that required configuration values are present
 func (c *Config) validate() error {
+	errors := c.awsErrors()
+
+	// Validate storage backend
+	switch c.StorageBackend {
+	case StorageBackendDynamoDB, StorageBackendMemory:
+	default:
+		errors = append(errors, fmt.Sprintf("STORAGE_BACKEND must be %q or %q, got %q", StorageBackendDynamoDB, StorageBackendMemory, c.StorageBackend))
+	}
+
+	// Validate cache invalidation transport
+	switch c.InvalidationTransport {
+	case InvalidationTransportNone, InvalidationTransportLocal:
+	case InvalidationTransportDynamoDB:
+		if c.UsesMemoryStorage() {
+			errors = append(errors, "INVALIDATION_TRANSPORT=dynamodb requires STORAGE_BACKEND=dynamodb")
+		}
+		if c.InvalidationPollInterval <= 0 {
+			errors = append(errors, "INVALIDATION_POLL_INTERVAL must be positive")
+		}
+		if c.InvalidationPollOverlap < 0 || c.InvalidationPollOverlap >= c.InvalidationRetention {
+			errors = append(errors, "INVALIDATION_POLL_OVERLAP must be at least 0 and shorter than INVALIDATION_RETENTION")
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("INVALIDATION_TRANSPORT must be %q, %q or %q, got %q", InvalidationTransportNone, InvalidationTransportLocal, InvalidationTransportDynamoDB, c.InvalidationTransport))
+	}
+
+	// Validate checkout counter
+	switch c.CheckoutCounterMode {
+	case CheckoutCounterTransactional:
+	case CheckoutCounterAggregated:
+		if c.CheckoutCounterFlushInterval <= 0 {
+			errors = append(errors, "CHECKOUT_COUNTER_FLUSH_INTERVAL must be positive")
+		}
+		if c.CheckoutCounterShards < 1 {
+			errors = append(errors, "CHECKOUT_COUNTER_SHARDS must be at least 1")
+		}
+		if _, _, err := c.ShardedCheckoutCounterOffers(); err != nil {
+			errors = append(errors, err.Error())
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("CHECKOUT_COUNTER_MODE must be %q or %q, got %q", CheckoutCounterTransactional, CheckoutCounterAggregated, c.CheckoutCounterMode))
+	}
+
+	if c.CheckoutResumeWindow < 0 {
+		errors = append(errors, "CHECKOUT_RESUME_WINDOW must not be negative")
+	}
+
+	// Validate abandoned cart job
+	if c.AbandonedCartIdleThreshold <= 0 {
+		errors = append(errors, "ABANDONED_CART_IDLE_THRESHOLD must be positive")
+	}
+	if c.AbandonedCartBatchSize < 1 || c.AbandonedCartBatchSize > 500 {
+		errors = append(errors, "ABANDONED_CART_BATCH_SIZE must be between 1 and 500")
+	}
+	if c.AbandonedCartInterval <= 0 {
+		errors = append(errors, "ABANDONED_CART_INTERVAL must be positive")
+	}
+
+	// Validate recovery links
+	if c.RecoveryTokenSecret != "" && len(c.RecoveryTokenSecret) < minRecoveryTokenSecretLength {
+		errors = append(errors, fmt.Sprintf("RECOVERY_TOKEN_SECRET must be at least %d characters", minRecoveryTokenSecretLength))
+	}
+	if c.RecoveryTokenTTL <= 0 {
+		errors = append(errors, "RECOVERY_TOKEN_TTL must be positive")
+	}
+
+	// Validate installments
+	if c.InstallmentInterestRate < 0 || c.InstallmentInterestRate > 100 {
+		errors = append(errors, "INSTALLMENT_INTEREST_RATE must be between 0 and 100")
+	}
+
+	// Validate S3 bucket configuration (the memory backend runs fully offline)
+	if c.AWSS3Bucket == "" && !c.UsesMemoryStorage() {
+		errors = append(errors, "AWS_S3_BUCKET is required")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+	}
+
+	return nil
+}
+
+// validateAWS checks the AWS settings only
+func (c *Config) validateAWS() error {
+	if errors := c.awsErrors(); len(errors) > 0 {
+		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// awsErrors lists the problems of the AWS settings
+func (c *Config) awsErrors() []string {
 	var errors []string
 
 	// Validate required AWS configuration if DynamoDB credentials are provided
@@ -78,16 +385,34 @@ func (c *Config) validate() error {
 		errors = append(errors, "AWS_DYNAMODB_ACCESS_KEY_ID is required when AWS_DYNAMODB_SECRET_ACCESS_KEY is provided")
 	}
 
-	// Validate S3 bucket configuration
-	if c.AWSS3Bucket == "" {
-		errors = append(errors, "AWS_S3_BUCKET is required")
+	// Validate AWS client tuning
+	for name, endpoint := range map[string]string{
+		"AWS_ENDPOINT_URL":          c.AWSEndpoint,
+		"AWS_ENDPOINT_URL_DYNAMODB": c.AWSDynamoDBEndpoint,
+		"AWS_ENDPOINT_URL_S3":       c.AWSS3Endpoint,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errors = append(errors, fmt.Sprintf("%s must be an absolute URL, got %q", name, endpoint))
+		}
 	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+	if c.AWSRetryMode != AWSRetryModeStandard && c.AWSRetryMode != AWSRetryModeAdaptive {
+		errors = append(errors, fmt.Sprintf("AWS_RETRY_MODE must be %q or %q, got %q", AWSRetryModeStandard, AWSRetryModeAdaptive, c.AWSRetryMode))
+	}
+	if c.AWSMaxAttempts < 1 {
+		errors = append(errors, "AWS_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.AWSMaxBackoff <= 0 {
+		errors = append(errors, "AWS_MAX_BACKOFF must be positive")
+	}
+	if c.AWSOperationTimeout < 0 {
+		errors = append(errors, "AWS_OPERATION_TIMEOUT must not be negative")
 	}
+	sort.Strings(errors)
 
-	return nil
+	return errors
 }
 
 // IsProduction returns true if the application is running in production
@@ -123,9 +448,88 @@ func (c *Config) GetGinMode() string {
 	return "debug"
 }
 
-// GetTableName returns the DynamoDB table name with environment prefix
+// GetTableName returns the physical DynamoDB table name of a base table:
+// its TABLE_<NAME> override if set, otherwise the base name with the
+// environment affix applied by the naming strategy
 func (c *Config) GetTableName(baseName string) string {
-	return baseName
+	if name, ok := c.TableOverrides[baseName]; ok {
+		return name
+	}
+
+	switch c.TableNaming {
+	case TableNamingPrefix:
+		return c.TableNameAffix + c.TableNameSeparator + baseName
+	case TableNamingSuffix:
+		return baseName + c.TableNameSeparator + c.TableNameAffix
+	default:
+		return baseName
+	}
+}
+
+// ValidateTableNames checks the naming strategy, that every base table
+// resolves to a valid and distinct physical name, and that every TABLE_<NAME>
+// override refers to one of them, which catches typos such as TABLE_OFFER
+func (c *Config) ValidateTableNames(baseNames []string) error {
+	var errors []string
+
+	switch c.TableNaming {
+	case TableNamingNone:
+	case TableNamingPrefix, TableNamingSuffix:
+		if c.TableNameAffix == "" {
+			errors = append(errors, fmt.Sprintf("TABLE_NAME_AFFIX (or APP_ENV) is required when TABLE_NAMING=%s", c.TableNaming))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("TABLE_NAMING must be %q, %q or %q, got %q", TableNamingNone, TableNamingPrefix, TableNamingSuffix, c.TableNaming))
+	}
+
+	known := make(map[string]bool, len(baseNames))
+	resolved := make(map[string]string, len(baseNames))
+	for _, baseName := range baseNames {
+		known[baseName] = true
+
+		name := c.GetTableName(baseName)
+		if !isValidTableName(name) {
+			errors = append(errors, fmt.Sprintf("table %s resolves to invalid name %q", baseName, name))
+			continue
+		}
+		if other, taken := resolved[name]; taken && other != baseName {
+			errors = append(errors, fmt.Sprintf("tables %s and %s both resolve to %q", other, baseName, name))
+			continue
+		}
+		resolved[name] = baseName
+	}
+
+	for baseName := range c.TableOverrides {
+		if !known[baseName] {
+			errors = append(errors, fmt.Sprintf("%s%s does not match any table", tableOverridePrefix, strings.ToUpper(baseName)))
+		}
+	}
+
+	if len(errors) > 0 {
+		sort.Strings(errors)
+		return fmt.Errorf("invalid table names: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// isValidTableName applies the DynamoDB table naming rules
+func isValidTableName(name string) bool {
+	if len(name) < 3 || len(name) > 255 {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// UsesMemoryStorage returns true if repositories are backed by the in-memory store
+func (c *Config) UsesMemoryStorage() bool {
+	return c.StorageBackend == StorageBackendMemory
 }
 
 // HasDynamoDBCredentials returns true if explicit DynamoDB credentials are configured
@@ -133,6 +537,44 @@ func (c *Config) HasDynamoDBCredentials() bool {
 	return c.AWSDynamoDBAccessKeyID != "" && c.AWSDynamoDBSecretAccessKey != ""
 }
 
+// DynamoDBEndpoint returns the endpoint override of the DynamoDB client, if any
+func (c *Config) DynamoDBEndpoint() string {
+	if c.AWSDynamoDBEndpoint != "" {
+		return c.AWSDynamoDBEndpoint
+	}
+	return c.AWSEndpoint
+}
+
+// S3Endpoint returns the endpoint override of S3, if any
+func (c *Config) S3Endpoint() string {
+	if c.AWSS3Endpoint != "" {
+		return c.AWSS3Endpoint
+	}
+	return c.AWSEndpoint
+}
+
+// ShardedCheckoutCounterOffers parses CHECKOUT_COUNTER_SHARDED_OFFERS, a
+// comma-separated list of offer IDs or "*" for every offer
+func (c *Config) ShardedCheckoutCounterOffers() (offerIDs []int, all bool, err error) {
+	for _, value := range strings.Split(c.CheckoutCounterShardedOffers, ",") {
+		value = strings.TrimSpace(value)
+		switch value {
+		case "":
+			continue
+		case "*":
+			all = true
+			continue
+		}
+
+		offerID, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("CHECKOUT_COUNTER_SHARDED_OFFERS must list offer IDs or \"*\", got %q", value)
+		}
+		offerIDs = append(offerIDs, offerID)
+	}
+	return offerIDs, all, nil
+}
+
 // GetS3BasePath returns the S3 base path, generating it if not explicitly set
 func (c *Config) GetS3BasePath() string {
 	if c.AWSS3BasePath != "" {
@@ -140,13 +582,48 @@ func (c *Config) GetS3BasePath() string {
 	}
 	
 	if c.AWSS3Bucket != "" {
-		path := "https://s3.amazonaws.com/" + c.AWSS3Bucket + "/"
+		// Custom endpoints such as LocalStack serve buckets path-style
+		endpoint := "https://s3.amazonaws.com"
+		if custom := c.S3Endpoint(); custom != "" {
+			endpoint = strings.TrimSuffix(custom, "/")
+		}
+		path := endpoint + "/" + c.AWSS3Bucket + "/"
 		return path
 	}
 	
 	return ""
 }
 
+// loadTableOverrides reads the TABLE_<NAME> variables, keyed by base table
+// name (TABLE_CHECKOUT_CONFIGS overrides checkout_configs)
+func loadTableOverrides() map[string]string {
+	overrides := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(key, tableOverridePrefix) || value == "" {
+			continue
+		}
+		switch key {
+		case "TABLE_NAMING", "TABLE_NAME_AFFIX", "TABLE_NAME_SEPARATOR":
+			continue
+		}
+		overrides[strings.ToLower(strings.TrimPrefix(key, tableOverridePrefix))] = value
+	}
+	return overrides
+}
+
+// loadCacheConfig reads the CACHE_<ENTITY>_* variables of one entity type,
+// falling back to the global CACHE_* defaults
+func loadCacheConfig(entity string, defaultSize int) CacheConfig {
+	prefix := "CACHE_" + entity + "_"
+	return CacheConfig{
+		Enabled:     getEnvBool(prefix+"ENABLED", getEnvBool("CACHE_ENABLED", true)),
+		Size:        getEnvInt(prefix+"SIZE", defaultSize),
+		TTL:         getEnvDuration(prefix+"TTL", getEnvDuration("CACHE_TTL", 30*time.Second)),
+		NegativeTTL: getEnvDuration(prefix+"NEGATIVE_TTL", getEnvDuration("CACHE_NEGATIVE_TTL", 10*time.Second)),
+	}
+}
+
 // getEnvWithDefault returns the environment variable value or the default if not set
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -173,4 +650,26 @@ func getEnvInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
-} 
\ No newline at end of file
+} 
+
+// getEnvFloat returns the environment variable as a float or the default if
+// not set
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration returns the environment variable as a duration (e.g. "750ms")
+// or the default if not set
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
\ No newline at end of file
@@ -3,6 +3,7 @@ package entities
 import (
 	"time"
 
+	"checkout-go/internal/core/errors"
 	"checkout-go/internal/core/valueobjects"
 )
 
@@ -22,6 +23,7 @@ type Checkout struct {
 	OfferID                    *int                   `json:"offer_id,omitempty" dynamodb:"offer_id,omitempty"`
 	ProductID                  int                    `json:"product_id" dynamodb:"product_id"`
 	AffiliateID                *int                   `json:"affiliate_id,omitempty" dynamodb:"affiliate_id,omitempty"`
+	CustomerID                 *int                   `json:"customer_id,omitempty" dynamodb:"customer_id,omitempty"`
 	Status                     CheckoutStatus         `json:"status" dynamodb:"status"`
 	UserAgent                  *string                `json:"user_agent,omitempty" dynamodb:"user_agent,omitempty"`
 	OS                         *string                `json:"os,omitempty" dynamodb:"os,omitempty"`
@@ -49,6 +51,30 @@ type Checkout struct {
 	OriginalURL                *string                `json:"original_url,omitempty" dynamodb:"original_url,omitempty"`
 	CreatedAt                  time.Time              `json:"created_at" dynamodb:"created_at"`
 	UpdatedAt                  time.Time              `json:"updated_at" dynamodb:"updated_at"`
+	// AbandonedAt, RecoveredAt and SaleFinalizedAt record when the checkout
+	// last entered each status
+	AbandonedAt     *time.Time `json:"abandoned_at,omitempty" dynamodb:"abandoned_at,omitempty"`
+	RecoveredAt     *time.Time `json:"recovered_at,omitempty" dynamodb:"recovered_at,omitempty"`
+	SaleFinalizedAt *time.Time `json:"sale_finalized_at,omitempty" dynamodb:"sale_finalized_at,omitempty"`
+	// The buyer's details as typed in the checkout form, captured before the
+	// sale so abandoned carts can be recovered; LeadCapturedAt is the time of
+	// the last capture
+	CustomerName     *string    `json:"customer_name,omitempty" dynamodb:"customer_name,omitempty"`
+	CustomerEmail    *string    `json:"customer_email,omitempty" dynamodb:"customer_email,omitempty"`
+	CustomerPhone    *string    `json:"customer_phone,omitempty" dynamodb:"customer_phone,omitempty"`
+	CustomerDocument *string    `json:"customer_document,omitempty" dynamodb:"customer_document,omitempty"`
+	LeadCapturedAt   *time.Time `json:"lead_captured_at,omitempty" dynamodb:"lead_captured_at,omitempty"`
+	// CouponCode is the code of the coupon the buyer applied
+	CouponCode *string `json:"coupon_code,omitempty" dynamodb:"coupon_code,omitempty"`
+	// PlanUUID is the subscription plan the buyer chose through a plan link;
+	// nil charges the default plan of the offer
+	PlanUUID *string `json:"plan_uuid,omitempty" dynamodb:"plan_uuid,omitempty"`
+	// Version is incremented on every update and guards against lost updates;
+	// checkouts written before versioning have version 0
+	Version int `json:"version" dynamodb:"version"`
+
+	// events holds the domain events recorded since the last PullEvents
+	events []CheckoutEvent
 }
 
 type CheckoutProps struct {
@@ -56,6 +82,9 @@ type CheckoutProps struct {
 	OfferID                    *int
 	ProductID                  int
 	AffiliateID                *int
+	CustomerID                 *int
+	CouponCode                 *string
+	PlanUUID                   *string
 	UserAgent                  *string
 	OS                         *string
 	Browser                    *string
@@ -81,7 +110,9 @@ type CheckoutProps struct {
 }
 
 func NewCheckout(props CheckoutProps) *Checkout {
-	now := time.Now()
+	// Timestamps are kept in UTC so that their stored RFC 3339 form sorts
+	// chronologically in the created_at indexes
+	now := time.Now().UTC()
 	uuid := valueobjects.NewRandomUUID()
 
 	return &Checkout{
@@ -90,6 +121,9 @@ func NewCheckout(props CheckoutProps) *Checkout {
 		OfferID:                    props.OfferID,
 		ProductID:                  props.ProductID,
 		AffiliateID:                props.AffiliateID,
+		CustomerID:                 props.CustomerID,
+		CouponCode:                 props.CouponCode,
+		PlanUUID:                   props.PlanUUID,
 		Status:                     CheckoutStatusAccessed,
 		UserAgent:                  props.UserAgent,
 		OS:                         props.OS,
@@ -117,6 +151,7 @@ func NewCheckout(props CheckoutProps) *Checkout {
 		OriginalURL:                props.OriginalURL,
 		CreatedAt:                  now,
 		UpdatedAt:                  now,
+		Version:                    1,
 	}
 }
 
@@ -137,7 +172,81 @@ func (c *Checkout) SetID(id int) {
 
 // UpdateTimestamp updates the UpdatedAt field
 func (c *Checkout) UpdateTimestamp() {
-	c.UpdatedAt = time.Now()
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// RefreshTracking records the tracking data of a new visit to the checkout.
+// The UTM parameters and original URL present in props replace the stored
+// ones, and the pixel data is merged into the stored data.
+func (c *Checkout) RefreshTracking(props CheckoutProps) {
+	if props.Src != nil || props.UTMSource != nil || props.UTMMedium != nil ||
+		props.UTMCampaign != nil || props.UTMTerm != nil || props.UTMContent != nil {
+		c.Src = props.Src
+		c.UTMSource = props.UTMSource
+		c.UTMMedium = props.UTMMedium
+		c.UTMCampaign = props.UTMCampaign
+		c.UTMTerm = props.UTMTerm
+		c.UTMContent = props.UTMContent
+	}
+	if props.OriginalURL != nil {
+		c.OriginalURL = props.OriginalURL
+	}
+	for key, value := range props.PixelData {
+		c.SetPixelValue(key, value)
+	}
+}
+
+// LinkCustomer records the customer the checkout belongs to
+func (c *Checkout) LinkCustomer(customerID int) {
+	c.CustomerID = &customerID
+}
+
+// SelectPlan records the subscription plan the buyer chose
+func (c *Checkout) SelectPlan(planUUID string) {
+	c.PlanUUID = &planUUID
+}
+
+// CheckoutLead holds the buyer's details captured from the checkout form; nil
+// fields were not sent and keep their stored value
+type CheckoutLead struct {
+	Name     *string
+	Email    *string
+	Phone    *string
+	Document *string
+}
+
+// CaptureLead records the buyer's details, refusing once the sale is
+// finalized
+func (c *Checkout) CaptureLead(lead CheckoutLead, at time.Time) error {
+	if c.IsSaleFinalizedStatus() {
+		return errors.NewCheckoutFinalizedError(c.UUID)
+	}
+
+	if lead.Name != nil {
+		c.CustomerName = lead.Name
+	}
+	if lead.Email != nil {
+		c.CustomerEmail = lead.Email
+	}
+	if lead.Phone != nil {
+		c.CustomerPhone = lead.Phone
+	}
+	if lead.Document != nil {
+		c.CustomerDocument = lead.Document
+	}
+	at = at.UTC()
+	c.LeadCapturedAt = &at
+	return nil
+}
+
+// ApplyCoupon records the code of the coupon the buyer applied, refusing
+// once the sale is finalized
+func (c *Checkout) ApplyCoupon(code string) error {
+	if c.IsSaleFinalizedStatus() {
+		return errors.NewCheckoutFinalizedError(c.UUID)
+	}
+	c.CouponCode = &code
+	return nil
 }
 
 // IsAccessedStatus checks if checkout status is ACCESSED
@@ -160,6 +269,65 @@ func (c *Checkout) IsSaleFinalizedStatus() bool {
 	return c.Status == CheckoutStatusSaleFinalized
 }
 
+// checkoutTransitions lists the statuses each status can move to. A sale can
+// be finalized from any open status. Abandoned carts are recovered, and so
+// are accessed checkouts reopened from a recovery link before the abandoned
+// cart job reached them.
+var checkoutTransitions = map[CheckoutStatus][]CheckoutStatus{
+	CheckoutStatusAccessed:      {CheckoutStatusAbandonedCart, CheckoutStatusRecovered, CheckoutStatusSaleFinalized},
+	CheckoutStatusAbandonedCart: {CheckoutStatusRecovered, CheckoutStatusSaleFinalized},
+	CheckoutStatusRecovered:     {CheckoutStatusSaleFinalized},
+}
+
+// CanTransitionTo reports whether the checkout can move to status
+func (c *Checkout) CanTransitionTo(status CheckoutStatus) bool {
+	for _, allowed := range checkoutTransitions[c.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkAbandoned moves an accessed checkout to ABANDONED_CART
+func (c *Checkout) MarkAbandoned(at time.Time) error {
+	return c.transition(CheckoutStatusAbandonedCart, at, &c.AbandonedAt, CheckoutAbandoned)
+}
+
+// MarkRecovered moves an abandoned or accessed checkout to RECOVERED
+func (c *Checkout) MarkRecovered(at time.Time) error {
+	return c.transition(CheckoutStatusRecovered, at, &c.RecoveredAt, CheckoutRecovered)
+}
+
+// MarkSaleFinalized moves an open checkout to SALE_FINALIZED
+func (c *Checkout) MarkSaleFinalized(at time.Time) error {
+	return c.transition(CheckoutStatusSaleFinalized, at, &c.SaleFinalizedAt, CheckoutSaleFinalized)
+}
+
+// transition changes the status, stamps the status timestamp and records
+// the domain event, or returns an InvalidStatusTransitionError leaving the
+// checkout untouched
+func (c *Checkout) transition(to CheckoutStatus, at time.Time, timestamp **time.Time, eventType CheckoutEventType) error {
+	from := c.Status
+	if !c.CanTransitionTo(to) {
+		return errors.NewInvalidStatusTransitionError("checkout", string(from), string(to))
+	}
+
+	at = at.UTC()
+	c.Status = to
+	*timestamp = &at
+	c.events = append(c.events, CheckoutEvent{
+		Type:         eventType,
+		CheckoutUUID: c.UUID,
+		OfferID:      c.OfferID,
+		ProductID:    c.ProductID,
+		From:         from,
+		To:           to,
+		OccurredAt:   at,
+	})
+	return nil
+}
+
 // HasPixelData checks if checkout has pixel data
 func (c *Checkout) HasPixelData() bool {
 	return c.PixelData != nil && len(c.PixelData) > 0
@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// CheckoutEventType names a checkout domain event
+type CheckoutEventType string
+
+const (
+	CheckoutAbandoned     CheckoutEventType = "checkout.abandoned"
+	CheckoutRecovered     CheckoutEventType = "checkout.recovered"
+	CheckoutSaleFinalized CheckoutEventType = "checkout.sale_finalized"
+)
+
+// CheckoutEvent is recorded by the checkout on every status transition and
+// published once the checkout is written
+type CheckoutEvent struct {
+	Type         CheckoutEventType `json:"type"`
+	CheckoutUUID string            `json:"checkout_uuid"`
+	OfferID      *int              `json:"offer_id,omitempty"`
+	ProductID    int               `json:"product_id"`
+	From         CheckoutStatus    `json:"from"`
+	To           CheckoutStatus    `json:"to"`
+	OccurredAt   time.Time         `json:"occurred_at"`
+}
+
+// PendingEvents returns the events recorded since the last PullEvents
+func (c *Checkout) PendingEvents() []CheckoutEvent {
+	return append([]CheckoutEvent(nil), c.events...)
+}
+
+// PullEvents returns the events recorded since the last call and clears them
+func (c *Checkout) PullEvents() []CheckoutEvent {
+	events := c.events
+	c.events = nil
+	return events
+}
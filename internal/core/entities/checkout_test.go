@@ -0,0 +1,184 @@
+package entities
+
+import (
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"checkout-go/internal/core/errors"
+)
+
+var statuses = []CheckoutStatus{
+	CheckoutStatusAccessed,
+	CheckoutStatusAbandonedCart,
+	CheckoutStatusRecovered,
+	CheckoutStatusSaleFinalized,
+}
+
+// mark moves the checkout to status through its Mark method, returning the
+// timestamp the transition stamps and the event it records
+func mark(checkout *Checkout, status CheckoutStatus, at time.Time) (**time.Time, CheckoutEventType, error) {
+	switch status {
+	case CheckoutStatusAbandonedCart:
+		return &checkout.AbandonedAt, CheckoutAbandoned, checkout.MarkAbandoned(at)
+	case CheckoutStatusRecovered:
+		return &checkout.RecoveredAt, CheckoutRecovered, checkout.MarkRecovered(at)
+	case CheckoutStatusSaleFinalized:
+		return &checkout.SaleFinalizedAt, CheckoutSaleFinalized, checkout.MarkSaleFinalized(at)
+	}
+	panic("no transition to " + status)
+}
+
+func TestCheckoutTransitions(t *testing.T) {
+	allowed := map[CheckoutStatus][]CheckoutStatus{
+		CheckoutStatusAccessed:      {CheckoutStatusAbandonedCart, CheckoutStatusRecovered, CheckoutStatusSaleFinalized},
+		CheckoutStatusAbandonedCart: {CheckoutStatusRecovered, CheckoutStatusSaleFinalized},
+		CheckoutStatusRecovered:     {CheckoutStatusSaleFinalized},
+		CheckoutStatusSaleFinalized: nil,
+	}
+	// A transition is stamped in UTC whatever the zone of the time given
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+	offerID := 7
+
+	for _, from := range statuses {
+		for _, to := range statuses[1:] {
+			want := false
+			for _, status := range allowed[from] {
+				want = want || status == to
+			}
+
+			t.Run(string(from)+" to "+string(to), func(t *testing.T) {
+				checkout := &Checkout{UUID: "366b643f-3ad1-4204-9655-cdd079d2498c", OfferID: &offerID, ProductID: 3, Status: from, Version: 4}
+				if got := checkout.CanTransitionTo(to); got != want {
+					t.Errorf("CanTransitionTo = %v, want %v", got, want)
+				}
+
+				timestamp, eventType, err := mark(checkout, to, at)
+				if !want {
+					var invalid *errors.InvalidStatusTransitionError
+					if !stdErrors.As(err, &invalid) || invalid.From != string(from) || invalid.To != string(to) {
+						t.Fatalf("error = %v, want InvalidStatusTransitionError from %s to %s", err, from, to)
+					}
+					if checkout.Status != from || *timestamp != nil || len(checkout.PendingEvents()) != 0 {
+						t.Errorf("refused transition changed the checkout: %s, %v, %v", checkout.Status, *timestamp, checkout.PendingEvents())
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if checkout.Status != to {
+					t.Errorf("status = %s, want %s", checkout.Status, to)
+				}
+				if *timestamp == nil || !(*timestamp).Equal(at) || (*timestamp).Location() != time.UTC {
+					t.Errorf("timestamp = %v, want %v in UTC", *timestamp, at.UTC())
+				}
+				// The version is bumped by the repository when the checkout
+				// is written, not by the transition
+				if checkout.Version != 4 {
+					t.Errorf("version = %d, want 4", checkout.Version)
+				}
+
+				events := checkout.PullEvents()
+				wantEvent := CheckoutEvent{
+					Type:         eventType,
+					CheckoutUUID: checkout.UUID,
+					OfferID:      &offerID,
+					ProductID:    3,
+					From:         from,
+					To:           to,
+					OccurredAt:   at.UTC(),
+				}
+				if len(events) != 1 || events[0] != wantEvent {
+					t.Errorf("events = %+v, want %+v", events, wantEvent)
+				}
+				if pending := checkout.PendingEvents(); len(pending) != 0 {
+					t.Errorf("events left after PullEvents: %+v", pending)
+				}
+			})
+		}
+	}
+}
+
+func TestCheckoutRecordsEveryTransition(t *testing.T) {
+	checkout := &Checkout{UUID: "366b643f-3ad1-4204-9655-cdd079d2498c", Status: CheckoutStatusAccessed}
+	start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for i, status := range statuses[1:] {
+		if _, _, err := mark(checkout, status, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("mark %s: %v", status, err)
+		}
+	}
+
+	events := checkout.PendingEvents()
+	want := []CheckoutEventType{CheckoutAbandoned, CheckoutRecovered, CheckoutSaleFinalized}
+	if len(events) != len(want) {
+		t.Fatalf("recorded %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Type != want[i] || event.From != statuses[i] || event.To != statuses[i+1] {
+			t.Errorf("event %d = %s from %s to %s", i, event.Type, event.From, event.To)
+		}
+	}
+	if !checkout.AbandonedAt.Before(*checkout.RecoveredAt) || !checkout.RecoveredAt.Before(*checkout.SaleFinalizedAt) {
+		t.Errorf("timestamps out of order: %v, %v, %v", checkout.AbandonedAt, checkout.RecoveredAt, checkout.SaleFinalizedAt)
+	}
+
+	// PendingEvents hands out a copy
+	events[0].Type = "changed"
+	if checkout.PendingEvents()[0].Type != CheckoutAbandoned {
+		t.Error("PendingEvents exposed the recorded events")
+	}
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestFinalizedCheckoutRefusesChanges(t *testing.T) {
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, status := range statuses {
+		t.Run(string(status), func(t *testing.T) {
+			checkout := &Checkout{UUID: "366b643f-3ad1-4204-9655-cdd079d2498c", Status: status}
+			leadErr := checkout.CaptureLead(CheckoutLead{Email: stringPtr("maria@example.com")}, at)
+			couponErr := checkout.ApplyCoupon("BEMVINDO10")
+
+			if status != CheckoutStatusSaleFinalized {
+				if leadErr != nil || couponErr != nil {
+					t.Fatalf("CaptureLead = %v, ApplyCoupon = %v", leadErr, couponErr)
+				}
+				if checkout.CustomerEmail == nil || checkout.LeadCapturedAt == nil || !checkout.LeadCapturedAt.Equal(at) || checkout.CouponCode == nil {
+					t.Errorf("checkout not updated: %v, %v, %v", checkout.CustomerEmail, checkout.LeadCapturedAt, checkout.CouponCode)
+				}
+				return
+			}
+
+			for name, err := range map[string]error{"CaptureLead": leadErr, "ApplyCoupon": couponErr} {
+				var finalized *errors.CheckoutFinalizedError
+				if !stdErrors.As(err, &finalized) || finalized.CheckoutUUID != checkout.UUID {
+					t.Errorf("%s error = %v, want CheckoutFinalizedError", name, err)
+				}
+			}
+			if checkout.CustomerEmail != nil || checkout.LeadCapturedAt != nil || checkout.CouponCode != nil {
+				t.Errorf("finalized checkout was changed: %v, %v, %v", checkout.CustomerEmail, checkout.LeadCapturedAt, checkout.CouponCode)
+			}
+		})
+	}
+}
+
+func TestCaptureLeadKeepsFieldsNotSent(t *testing.T) {
+	checkout := &Checkout{Status: CheckoutStatusAccessed}
+	first := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	checkout.CaptureLead(CheckoutLead{Name: stringPtr("Maria"), Email: stringPtr("maria@example.com")}, first)
+	checkout.CaptureLead(CheckoutLead{Email: stringPtr("maria.santos@example.com"), Phone: stringPtr("11987654321")}, first.Add(time.Minute))
+
+	if *checkout.CustomerName != "Maria" || *checkout.CustomerEmail != "maria.santos@example.com" || *checkout.CustomerPhone != "11987654321" || checkout.CustomerDocument != nil {
+		t.Errorf("lead = %v %v %v %v", *checkout.CustomerName, *checkout.CustomerEmail, *checkout.CustomerPhone, checkout.CustomerDocument)
+	}
+	if !checkout.LeadCapturedAt.Equal(first.Add(time.Minute)) {
+		t.Errorf("lead captured at %v, want the last capture", checkout.LeadCapturedAt)
+	}
+}
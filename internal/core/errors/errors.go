@@ -136,6 +136,86 @@ func NewUnableToUpdateEntityError(entityName, message string) *UnableToUpdateEnt
 	}
 }
 
+type ConcurrentModificationError struct {
+	*BaseError
+	EntityName      string
+	ExpectedVersion int
+}
+
+func NewConcurrentModificationError(entityName string, expectedVersion int) *ConcurrentModificationError {
+	return &ConcurrentModificationError{
+		BaseError: &BaseError{
+			Code:          "CONCURRENT_MODIFICATION",
+			Message:       fmt.Sprintf("%s was modified concurrently (expected version %d)", entityName, expectedVersion),
+			IsDisplayable: false,
+			HTTPCode:      409,
+		},
+		EntityName:      entityName,
+		ExpectedVersion: expectedVersion,
+	}
+}
+
+// InvalidStatusTransitionError is returned when an entity cannot move from
+// its current status to the requested one
+type InvalidStatusTransitionError struct {
+	*BaseError
+	EntityName string
+	From       string
+	To         string
+}
+
+func NewInvalidStatusTransitionError(entityName, from, to string) *InvalidStatusTransitionError {
+	return &InvalidStatusTransitionError{
+		BaseError: &BaseError{
+			Code:          "INVALID_STATUS_TRANSITION",
+			Message:       fmt.Sprintf("Não é possível alterar o status de %s para %s", from, to),
+			IsDisplayable: true,
+			HTTPCode:      409,
+		},
+		EntityName: entityName,
+		From:       from,
+		To:         to,
+	}
+}
+
+// CheckoutFinalizedError is returned when a checkout whose sale was already
+// finalized is changed
+type CheckoutFinalizedError struct {
+	*BaseError
+	CheckoutUUID string
+}
+
+func NewCheckoutFinalizedError(checkoutUUID string) *CheckoutFinalizedError {
+	return &CheckoutFinalizedError{
+		BaseError: &BaseError{
+			Code:          "CHECKOUT_FINALIZED",
+			Message:       "Este checkout já foi finalizado",
+			IsDisplayable: true,
+			HTTPCode:      409,
+		},
+		CheckoutUUID: checkoutUUID,
+	}
+}
+
+// InvalidCouponError is returned when a coupon code is unknown or cannot be
+// used on the checkout; Message tells the buyer why
+type InvalidCouponError struct {
+	*BaseError
+	CouponCode string
+}
+
+func NewInvalidCouponError(couponCode, message string) *InvalidCouponError {
+	return &InvalidCouponError{
+		BaseError: &BaseError{
+			Code:          "INVALID_COUPON",
+			Message:       message,
+			IsDisplayable: true,
+			HTTPCode:      400,
+		},
+		CouponCode: couponCode,
+	}
+}
+
 type InvalidIpAddressError struct {
 	*BaseError
 }
@@ -0,0 +1,126 @@
+package recovery
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	stdErrors "errors"
+	"strings"
+	"time"
+)
+
+// Links sent to buyers carry signed tokens. A recovery token names the
+// checkout of an abandoned cart and is "v1.<payload>.<signature>", where the
+// payload is the base64url (unpadded) JSON {"checkout": uuid, "exp": unix
+// seconds} and the signature the base64url HMAC-SHA256 of "v1.<payload>"
+// under the secret. A prefill token names a customer whose details prefill
+// the form; it has the same shape with the "p1" prefix and the payload
+// {"customer": id, "exp": unix seconds}. The prefix is signed, so a token of
+// one kind is never accepted as the other.
+
+const (
+	recoveryPrefix = "v1"
+	prefillPrefix  = "p1"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed tokens and bad signatures
+	ErrInvalidToken = stdErrors.New("invalid link token")
+	// ErrExpiredToken is returned for well-signed tokens past their expiry
+	ErrExpiredToken = stdErrors.New("expired link token")
+)
+
+type claims struct {
+	Checkout  string `json:"checkout,omitempty"`
+	Customer  int    `json:"customer,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer issues and verifies recovery and prefill tokens
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewSigner creates a signer whose tokens expire ttl after they are issued
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl}
+}
+
+// Issue returns a recovery token for the checkout, valid until now plus the
+// TTL
+func (s *Signer) Issue(checkoutUUID string, now time.Time) string {
+	return s.issue(recoveryPrefix, claims{Checkout: checkoutUUID, ExpiresAt: now.Add(s.ttl).Unix()})
+}
+
+// Verify checks the signature and expiry of a recovery token and returns the
+// UUID of its checkout
+func (s *Signer) Verify(token string, now time.Time) (string, error) {
+	c, err := s.verify(recoveryPrefix, token, now)
+	if err != nil {
+		return "", err
+	}
+	if c.Checkout == "" {
+		return "", ErrInvalidToken
+	}
+	return c.Checkout, nil
+}
+
+// IssuePrefill returns a prefill token for the customer, valid until now
+// plus the TTL
+func (s *Signer) IssuePrefill(customerID int, now time.Time) string {
+	return s.issue(prefillPrefix, claims{Customer: customerID, ExpiresAt: now.Add(s.ttl).Unix()})
+}
+
+// VerifyPrefill checks the signature and expiry of a prefill token and
+// returns the ID of its customer
+func (s *Signer) VerifyPrefill(token string, now time.Time) (int, error) {
+	c, err := s.verify(prefillPrefix, token, now)
+	if err != nil {
+		return 0, err
+	}
+	if c.Customer <= 0 {
+		return 0, ErrInvalidToken
+	}
+	return c.Customer, nil
+}
+
+func (s *Signer) issue(prefix string, c claims) string {
+	payload, _ := json.Marshal(c)
+	signed := prefix + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(s.sign(signed))
+}
+
+func (s *Signer) verify(prefix, token string, now time.Time) (*claims, error) {
+	version, rest, _ := strings.Cut(token, ".")
+	encodedPayload, encodedSignature, found := strings.Cut(rest, ".")
+	if version != prefix || !found {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.sign(version+"."+encodedPayload)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix() >= c.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &c, nil
+}
+
+func (s *Signer) sign(data string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,132 @@
+package recovery
+
+import (
+	"encoding/base64"
+	stdErrors "errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const checkoutUUID = "366b643f-3ad1-4204-9655-cdd079d2498c"
+
+var issuedAt = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestSigner() *Signer {
+	return NewSigner([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+}
+
+func TestVerifyAcceptsIssuedTokens(t *testing.T) {
+	signer := newTestSigner()
+
+	uuid, err := signer.Verify(signer.Issue(checkoutUUID, issuedAt), issuedAt.Add(59*time.Minute))
+	if err != nil || uuid != checkoutUUID {
+		t.Errorf("Verify = %q, %v, want %q", uuid, err, checkoutUUID)
+	}
+
+	customerID, err := signer.VerifyPrefill(signer.IssuePrefill(42, issuedAt), issuedAt.Add(59*time.Minute))
+	if err != nil || customerID != 42 {
+		t.Errorf("VerifyPrefill = %d, %v, want 42", customerID, err)
+	}
+}
+
+// tamper returns token with one character of the given part replaced
+func tamper(token string, part int) string {
+	parts := strings.Split(token, ".")
+	b := []byte(parts[part])
+	if b[0] == 'A' {
+		b[0] = 'B'
+	} else {
+		b[0] = 'A'
+	}
+	parts[part] = string(b)
+	return strings.Join(parts, ".")
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	signer := newTestSigner()
+	token := signer.Issue(checkoutUUID, issuedAt)
+	prefill := signer.IssuePrefill(42, issuedAt)
+	now := issuedAt.Add(time.Minute)
+
+	otherPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"checkout":"8a1f0c4e-2b7d-4d3a-9e5f-6c7b8a9d0e1f","exp":9999999999}`))
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"prefix only", "v1"},
+		{"no signature", parts[0] + "." + parts[1]},
+		{"empty signature", parts[0] + "." + parts[1] + "."},
+		{"truncated signature", token[:len(token)-4]},
+		{"tampered signature", tamper(token, 2)},
+		{"tampered payload", tamper(token, 1)},
+		{"swapped payload", parts[0] + "." + otherPayload + "." + parts[2]},
+		{"garbled signature", parts[0] + "." + parts[1] + ".!!!"},
+		{"garbled", "not a token"},
+		{"extra part", token + ".extra"},
+		{"other secret", NewSigner([]byte("fedcba9876543210fedcba9876543210"), time.Hour).Issue(checkoutUUID, issuedAt)},
+		{"prefill token", prefill},
+		{"prefill token relabelled", "v1" + strings.TrimPrefix(prefill, "p1")},
+		{"signed without a checkout", signer.issue(recoveryPrefix, claims{Customer: 42, ExpiresAt: now.Add(time.Hour).Unix()})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid, err := signer.Verify(tt.token, now)
+			if !stdErrors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify = %q, %v, want ErrInvalidToken", uuid, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPrefillRejectsBadTokens(t *testing.T) {
+	signer := newTestSigner()
+	token := signer.Issue(checkoutUUID, issuedAt)
+	prefill := signer.IssuePrefill(42, issuedAt)
+	now := issuedAt.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"recovery token", token},
+		{"recovery token relabelled", "p1" + strings.TrimPrefix(token, "v1")},
+		{"tampered signature", tamper(prefill, 2)},
+		{"truncated", prefill[:len(prefill)/2]},
+		{"signed without a customer", signer.issue(prefillPrefix, claims{Checkout: checkoutUUID, ExpiresAt: now.Add(time.Hour).Unix()})},
+		{"negative customer", signer.issue(prefillPrefix, claims{Customer: -1, ExpiresAt: now.Add(time.Hour).Unix()})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customerID, err := signer.VerifyPrefill(tt.token, now)
+			if !stdErrors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyPrefill = %d, %v, want ErrInvalidToken", customerID, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsExpiredTokens(t *testing.T) {
+	signer := newTestSigner()
+	token := signer.Issue(checkoutUUID, issuedAt)
+	prefill := signer.IssuePrefill(42, issuedAt)
+
+	for _, now := range []time.Time{issuedAt.Add(time.Hour), issuedAt.Add(48 * time.Hour)} {
+		if _, err := signer.Verify(token, now); !stdErrors.Is(err, ErrExpiredToken) {
+			t.Errorf("Verify at %v = %v, want ErrExpiredToken", now, err)
+		}
+		if _, err := signer.VerifyPrefill(prefill, now); !stdErrors.Is(err, ErrExpiredToken) {
+			t.Errorf("VerifyPrefill at %v = %v, want ErrExpiredToken", now, err)
+		}
+	}
+
+	// Expiry is only reported for tokens with a valid signature
+	if _, err := signer.Verify(tamper(token, 2), issuedAt.Add(48*time.Hour)); !stdErrors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify of a tampered expired token = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,103 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+)
+
+// Brazilian taxpayer document types
+const (
+	DocumentTypeCPF  = "CPF"
+	DocumentTypeCNPJ = "CNPJ"
+)
+
+var ErrInvalidDocument = errors.New("invalid CPF or CNPJ")
+
+// Document is a CPF or CNPJ with valid check digits, stored as digits only
+type Document struct {
+	value   string
+	docType string
+}
+
+// NewDocument parses a CPF (11 digits) or CNPJ (14 digits), with or without
+// punctuation
+func NewDocument(value string) (*Document, error) {
+	digits := onlyDigits(value)
+	switch {
+	case len(digits) == 11 && validCPF(digits):
+		return &Document{value: digits, docType: DocumentTypeCPF}, nil
+	case len(digits) == 14 && validCNPJ(digits):
+		return &Document{value: digits, docType: DocumentTypeCNPJ}, nil
+	default:
+		return nil, ErrInvalidDocument
+	}
+}
+
+func (d *Document) String() string {
+	return d.value
+}
+
+func (d *Document) Value() string {
+	return d.value
+}
+
+// Type returns DocumentTypeCPF or DocumentTypeCNPJ
+func (d *Document) Type() string {
+	return d.docType
+}
+
+func onlyDigits(value string) string {
+	var digits strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+// validCPF checks the two mod 11 check digits of a CPF
+func validCPF(digits string) bool {
+	if repeated(digits) {
+		return false
+	}
+	for check := 9; check < 11; check++ {
+		sum := 0
+		for i := 0; i < check; i++ {
+			sum += int(digits[i]-'0') * (check + 1 - i)
+		}
+		digit := sum * 10 % 11 % 10
+		if digit != int(digits[check]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// validCNPJ checks the two mod 11 check digits of a CNPJ
+func validCNPJ(digits string) bool {
+	if repeated(digits) {
+		return false
+	}
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	for check := 12; check < 14; check++ {
+		sum := 0
+		for i := 0; i < check; i++ {
+			sum += int(digits[i]-'0') * weights[i+13-check]
+		}
+		digit := 11 - sum%11
+		if digit >= 10 {
+			digit = 0
+		}
+		if digit != int(digits[check]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// repeated reports whether every digit is the same, which passes the check
+// digit rules but is never a real document
+func repeated(digits string) bool {
+	return strings.Count(digits, digits[:1]) == len(digits)
+}
@@ -0,0 +1,212 @@
+package valueobjects
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts stored without one
+const DefaultCurrency = "BRL"
+
+var (
+	ErrCurrencyMismatch = errors.New("money amounts have different currencies")
+	ErrMoneyOverflow    = errors.New("money amount overflows")
+)
+
+// percentageScale is the denominator of percentages: they are applied as
+// whole hundredths of a percent, so 2.5% is 250/10000
+const percentageScale = 10000
+
+// Money is an amount in the minor unit of an ISO 4217 currency, e.g. cents
+// of BRL. The zero value is zero BRL.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// NewMoney creates an amount of currency in its minor unit; an empty
+// currency means DefaultCurrency
+func NewMoney(amount int64, currency string) Money {
+	return Money{amount: amount, currency: normalizeCurrency(currency)}
+}
+
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// Amount returns the amount in the currency's minor unit
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the ISO 4217 code of the currency
+func (m Money) Currency() string {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Add returns m + other, failing when the currencies differ or the sum
+// overflows
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency() != other.Currency() {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.amount > 0 && m.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && m.amount < math.MinInt64-other.amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{amount: m.amount + other.amount, currency: m.Currency()}, nil
+}
+
+// Sub returns m - other, failing when the currencies differ or the
+// difference overflows
+func (m Money) Sub(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(Money{amount: -other.amount, currency: other.Currency()})
+}
+
+// Multiply returns m times factor, failing when the product overflows
+func (m Money) Multiply(factor int64) (Money, error) {
+	product := m.amount * factor
+	if m.amount != 0 && (product/m.amount != factor || (m.amount == -1 && factor == math.MinInt64)) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{amount: product, currency: m.Currency()}, nil
+}
+
+// Percentage returns percentage percent of m, e.g. 5 for 5%. The
+// percentage is rounded to hundredths of a percent and the result is rounded
+// half away from zero to the minor unit.
+func (m Money) Percentage(percentage float64) Money {
+	if math.IsNaN(percentage) || math.IsInf(percentage, 0) {
+		return Money{currency: m.Currency()}
+	}
+	rate := int64(math.Round(percentage * 100))
+
+	// amount * rate / percentageScale, without overflowing the product
+	quotient := m.amount / percentageScale * rate
+	remainder := m.amount % percentageScale * rate
+	part := remainder / percentageScale
+	if rest := remainder % percentageScale; rest*2 >= percentageScale {
+		part++
+	} else if rest*2 <= -percentageScale {
+		part--
+	}
+	return Money{amount: quotient + part, currency: m.Currency()}
+}
+
+// currencyFormat describes how the amounts of a currency are written
+type currencyFormat struct {
+	symbol   string
+	decimals int
+}
+
+// currencyFormats lists the currencies with a symbol or a minor unit other
+// than cents; other currencies are written with their code and 2 decimals
+var currencyFormats = map[string]currencyFormat{
+	"BRL": {symbol: "R$", decimals: 2},
+	"USD": {symbol: "US$", decimals: 2},
+	"EUR": {symbol: "€", decimals: 2},
+	"GBP": {symbol: "£", decimals: 2},
+	"ARS": {symbol: "ARS", decimals: 2},
+	"MXN": {symbol: "MX$", decimals: 2},
+	"COP": {symbol: "COP", decimals: 2},
+	"CLP": {symbol: "CLP", decimals: 0},
+	"PYG": {symbol: "PYG", decimals: 0},
+	"JPY": {symbol: "JP¥", decimals: 0},
+}
+
+func formatOf(currency string) currencyFormat {
+	if format, ok := currencyFormats[currency]; ok {
+		return format
+	}
+	return currencyFormat{symbol: currency, decimals: 2}
+}
+
+// String formats the amount in the pt-BR locale of the checkout, e.g.
+// "R$ 1.234,56" or "US$ 10,00"
+func (m Money) String() string {
+	format := formatOf(m.Currency())
+
+	amount := m.amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absUint(amount), 10)
+	if len(digits) <= format.decimals {
+		digits = strings.Repeat("0", format.decimals-len(digits)+1) + digits
+	}
+	integer := digits[:len(digits)-format.decimals]
+	fraction := digits[len(digits)-format.decimals:]
+
+	var formatted strings.Builder
+	formatted.WriteString(sign)
+	formatted.WriteString(format.symbol)
+	formatted.WriteString(" ")
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			formatted.WriteString(".")
+		}
+		formatted.WriteRune(digit)
+	}
+	if format.decimals > 0 {
+		formatted.WriteString(",")
+		formatted.WriteString(fraction)
+	}
+	return formatted.String()
+}
+
+func absUint(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+	return uint64(amount)
+}
+
+// moneyJSON is the JSON form of Money
+type moneyJSON struct {
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+	Formatted   string `json:"formatted,omitempty"`
+}
+
+// MarshalJSON encodes the amount in minor units with its currency and the
+// formatted string
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		AmountCents: m.amount,
+		Currency:    m.Currency(),
+		Formatted:   m.String(),
+	})
+}
+
+// UnmarshalJSON decodes the amount and currency, ignoring the formatted
+// string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var decoded moneyJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = NewMoney(decoded.AmountCents, decoded.Currency)
+	return nil
+}
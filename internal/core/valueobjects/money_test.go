@@ -0,0 +1,74 @@
+package valueobjects
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoneyPercentage(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64
+		percentage float64
+		want       int64
+	}{
+		{"whole", 19700, 5, 985},
+		{"half rounds up", 30, 5, 2},
+		{"half of a cent rounds up", 10, 5, 1},
+		{"below half rounds down", 29, 5, 1},
+		{"fractional percentage at half", 19700, 2.5, 493},
+		{"percentage rounded to hundredths", 10000, 2.504, 250},
+		{"negative half rounds away from zero", -30, 5, -2},
+		{"negative half of a cent", -10, 5, -1},
+		{"negative below half", -29, 5, -1},
+		{"negative percentage", 1000, -5, -50},
+		{"negative percentage at half", 10, -5, -1},
+		{"both negative", -10, -5, 1},
+		{"zero", 0, 5, 0},
+		{"NaN", 1000, math.NaN(), 0},
+		{"infinite", 1000, math.Inf(1), 0},
+		{"largest amount", math.MaxInt64, 100, math.MaxInt64},
+		{"smallest amount", math.MinInt64, 100, math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMoney(tt.amount, "BRL").Percentage(tt.percentage)
+			if got.Amount() != tt.want {
+				t.Errorf("%d.Percentage(%v) = %d, want %d", tt.amount, tt.percentage, got.Amount(), tt.want)
+			}
+			if got.Currency() != "BRL" {
+				t.Errorf("currency = %s, want BRL", got.Currency())
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{123456, "BRL", "R$ 1.234,56"},
+		{5, "BRL", "R$ 0,05"},
+		{0, "BRL", "R$ 0,00"},
+		{-1050, "", "-R$ 10,50"},
+		{1000, "usd", "US$ 10,00"},
+		{12345, "CHF", "CHF 123,45"},
+		{1234567, "CLP", "CLP 1.234.567"},
+		{0, "CLP", "CLP 0"},
+		{5, "PYG", "PYG 5"},
+		{-500, "JPY", "-JP¥ 500"},
+		{100, "JPY", "JP¥ 100"},
+		{math.MinInt64, "BRL", "-R$ 92.233.720.368.547.758,08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := NewMoney(tt.amount, tt.currency).String(); got != tt.want {
+				t.Errorf("NewMoney(%d, %q).String() = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"net/http"
 	"net/url"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"checkout-go/internal/core/errors"
 	"checkout-go/internal/infrastructure/di"
+	"checkout-go/internal/usecases/capturelead"
 	"checkout-go/internal/usecases/showcheckout"
 )
 
@@ -80,10 +83,111 @@ func (h *CheckoutHandlers) ShowCheckout(c *gin.Context) {
 		return
 	}
 
+	// Let the next visit resume this checkout
+	if result.CheckoutCookie != nil {
+		http.SetCookie(c.Writer, result.CheckoutCookie.HTTPCookie())
+	}
+
 	// Send successful response
 	c.JSON(http.StatusOK, result)
 }
 
+// CaptureLead handles PATCH /checkouts/:uuid
+func (h *CheckoutHandlers) CaptureLead(c *gin.Context) {
+	var req capturelead.CaptureLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid request body: " + err.Error(),
+			"status":  http.StatusBadRequest,
+		})
+		return
+	}
+	req.CheckoutUUID = c.Param("uuid")
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Validation failed: " + err.Error(),
+			"status":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Execute use case
+	result, err := h.container.GetCaptureLeadUseCase().Execute(c.Request.Context(), &req)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		body := gin.H{
+			"error":   true,
+			"message": err.Error(),
+			"status":  status,
+		}
+		var validationErr *errors.ValidationError
+		if stdErrors.As(err, &validationErr) {
+			body["details"] = validationErr.Details
+		}
+		c.JSON(status, body)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApplyCoupon handles POST /checkouts/:uuid/coupon
+func (h *CheckoutHandlers) ApplyCoupon(c *gin.Context) {
+	var req showcheckout.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid request body: " + err.Error(),
+			"status":  http.StatusBadRequest,
+		})
+		return
+	}
+	req.CheckoutUUID = c.Param("uuid")
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Validation failed: " + err.Error(),
+			"status":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Execute use case
+	result, err := h.container.GetShowCheckoutUseCase().ApplyCoupon(c.Request.Context(), &req)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		body := gin.H{
+			"error":   true,
+			"message": err.Error(),
+			"status":  status,
+		}
+		var validationErr *errors.ValidationError
+		if stdErrors.As(err, &validationErr) {
+			body["details"] = validationErr.Details
+		}
+		c.JSON(status, body)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// errorStatus returns the HTTP status of a core error, or fallback for any
+// other error
+func errorStatus(err error, fallback int) int {
+	var coreErr interface{ GetHTTPCode() int }
+	if stdErrors.As(err, &coreErr) {
+		return coreErr.GetHTTPCode()
+	}
+	return fallback
+}
+
 // buildRequestFromGin constructs the ShowCheckoutRequest from Gin context
 func (h *CheckoutHandlers) buildRequestFromGin(offerUUID string, c *gin.Context) (*showcheckout.ShowCheckoutRequest, error) {
 	// Extract client info from query parameters and headers
@@ -170,6 +274,21 @@ func (h *CheckoutHandlers) buildRequestFromGin(offerUUID string, c *gin.Context)
 	if clickId := c.Query("clickId"); clickId != "" {
 		req.ClickID = &clickId
 	}
+	if checkoutUUID := c.Query("checkout"); checkoutUUID != "" {
+		req.CheckoutUUID = &checkoutUUID
+	}
+	if recoveryToken := c.Query("recovery"); recoveryToken != "" {
+		req.RecoveryToken = &recoveryToken
+	}
+	if prefillToken := c.Query("prefill"); prefillToken != "" {
+		req.PrefillToken = &prefillToken
+	}
+	if coupon := c.Query("coupon"); coupon != "" {
+		req.Coupon = &coupon
+	}
+	if planUUID := c.Query("plan"); planUUID != "" {
+		req.PlanUUID = &planUUID
+	}
 	if originalUrl := c.Query("originalUrl"); originalUrl != "" {
 		// Validate URL
 		if _, err := url.Parse(originalUrl); err == nil {
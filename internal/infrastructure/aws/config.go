@@ -2,10 +2,14 @@ package aws
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/smithy-go/middleware"
 
 	appConfig "checkout-go/internal/config"
 )
@@ -27,15 +31,81 @@ func NewConfig(cfg *appConfig.Config) (aws.Config, error) {
 		configOptions = append(configOptions, config.WithCredentialsProvider(creds))
 	}
 
+	// Retry policy and operation timeout, shared by every client of the config
+	configOptions = append(configOptions, config.WithRetryer(newRetryer(cfg)))
+	if cfg.AWSOperationTimeout > 0 {
+		configOptions = append(configOptions, config.WithAPIOptions([]func(*middleware.Stack) error{
+			withOperationTimeout(cfg.AWSOperationTimeout),
+		}))
+	}
+
 	// Load AWS configuration
 	awsConfig, err := config.LoadDefaultConfig(context.TODO(), configOptions...)
 	if err != nil {
 		return aws.Config{}, err
 	}
 
+	// Service specific endpoints are set on the clients
+	if cfg.AWSEndpoint != "" {
+		awsConfig.BaseEndpoint = aws.String(cfg.AWSEndpoint)
+	}
+
 	return awsConfig, nil
 }
 
+// newRetryer builds the configured retry policy. Adaptive mode adds client
+// side rate limiting on top of the standard attempts and backoff.
+func newRetryer(cfg *appConfig.Config) func() aws.Retryer {
+	standard := func(o *retry.StandardOptions) {
+		o.MaxAttempts = cfg.AWSMaxAttempts
+		o.MaxBackoff = cfg.AWSMaxBackoff
+		o.Backoff = retry.NewExponentialJitterBackoff(cfg.AWSMaxBackoff)
+	}
+
+	return func() aws.Retryer {
+		if cfg.AWSRetryMode == appConfig.AWSRetryModeAdaptive {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, standard)
+			})
+		}
+		return retry.NewStandard(standard)
+	}
+}
+
+// withOperationTimeout bounds each operation, all attempts and backoff
+// included. It runs before the retry middleware, so a timeout stops the
+// retries too; a shorter deadline on the caller's context still wins.
+func withOperationTimeout(timeout time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationTimeout",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
+
+// DescribeClientSettings summarizes the effective AWS client settings for
+// the startup log
+func DescribeClientSettings(cfg *appConfig.Config) string {
+	timeout := "none"
+	if cfg.AWSOperationTimeout > 0 {
+		timeout = cfg.AWSOperationTimeout.String()
+	}
+
+	return fmt.Sprintf("region=%s dynamodb_endpoint=%s s3_endpoint=%s retry_mode=%s max_attempts=%d max_backoff=%s operation_timeout=%s",
+		cfg.AWSRegion, endpointOrDefault(cfg.DynamoDBEndpoint()), endpointOrDefault(cfg.S3Endpoint()),
+		cfg.AWSRetryMode, cfg.AWSMaxAttempts, cfg.AWSMaxBackoff, timeout)
+}
+
+func endpointOrDefault(endpoint string) string {
+	if endpoint == "" {
+		return "default"
+	}
+	return endpoint
+}
+
 // GetTableName returns the DynamoDB table name with environment prefix
 // This function is kept for backward compatibility but now uses the config
 func GetTableName(cfg *appConfig.Config, baseName string) string {
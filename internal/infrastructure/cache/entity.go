@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// fetchTimeout bounds a coalesced fetch, which no longer follows the deadline
+// of the caller that started it
+const fetchTimeout = 10 * time.Second
+
+// Settings configures the cache of one entity type
+type Settings struct {
+	// Size is the maximum number of keys kept in memory
+	Size int
+	// TTL is how long a found, active entity is served from memory
+	TTL time.Duration
+	// NegativeTTL is how long a missing or inactive entity is served from
+	// memory; zero disables negative caching
+	NegativeTTL time.Duration
+}
+
+// entityCache is a read-through cache for one entity type. An entity can be
+// reachable under several keys (id, uuid); all of them are filled on a fetch.
+type entityCache[T any] struct {
+	settings Settings
+	entries  *LRU[*T]
+	flights  flightGroup[*T]
+	keys     func(*T) []string
+	negative func(*T) bool
+	// generation is bumped on every invalidation so that a fetch that started
+	// before it does not store the value it read
+	generation atomic.Uint64
+}
+
+func newEntityCache[T any](settings Settings, keys func(*T) []string, negative func(*T) bool) *entityCache[T] {
+	return &entityCache[T]{
+		settings: settings,
+		entries:  NewLRU[*T](settings.Size),
+		keys:     keys,
+		negative: negative,
+	}
+}
+
+// get returns a copy of the entity stored under key, fetching it on a miss.
+// Errors are never cached.
+func (c *entityCache[T]) get(ctx context.Context, key string, fetch func(ctx context.Context) (*T, error)) (*T, error) {
+	if value, ok := c.entries.Get(key); ok {
+		return clone(value), nil
+	}
+
+	value, err := c.flights.do(ctx, key, func(ctx context.Context) (*T, error) {
+		generation := c.generation.Load()
+		value, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if c.generation.Load() == generation {
+			c.store(key, value)
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(value), nil
+}
+
+// getMany returns copies of the entities with the given IDs, fetching all the
+// misses in one call. IDs without an entity are absent from the result.
+func (c *entityCache[T]) getMany(ctx context.Context, ids []int, fetch func(ctx context.Context, ids []int) (map[int]*T, error)) (map[int]*T, error) {
+	found := make(map[int]*T, len(ids))
+	var misses []int
+	for _, id := range ids {
+		if value, ok := c.entries.Get(idKey(id)); ok {
+			if value != nil {
+				found[id] = clone(value)
+			}
+			continue
+		}
+		misses = append(misses, id)
+	}
+
+	if len(misses) == 0 {
+		return found, nil
+	}
+
+	generation := c.generation.Load()
+	fetched, err := fetch(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := c.generation.Load() != generation
+	for _, id := range misses {
+		value := fetched[id]
+		if !stale {
+			c.store(idKey(id), value)
+		}
+		if value != nil {
+			found[id] = clone(value)
+		}
+	}
+	return found, nil
+}
+
+// store caches value under the requested key and every key of the entity
+func (c *entityCache[T]) store(key string, value *T) {
+	if value == nil || (c.negative != nil && c.negative(value)) {
+		c.entries.Set(key, value, c.settings.NegativeTTL)
+		return
+	}
+
+	c.entries.Set(key, value, c.settings.TTL)
+	for _, entityKey := range c.keys(value) {
+		if entityKey != key {
+			c.entries.Set(entityKey, value, c.settings.TTL)
+		}
+	}
+}
+
+// invalidate drops key and every other key of the entity cached under it
+func (c *entityCache[T]) invalidate(key string) {
+	c.generation.Add(1)
+
+	if value, ok := c.entries.Get(key); ok && value != nil {
+		for _, entityKey := range c.keys(value) {
+			c.entries.Delete(entityKey)
+		}
+	}
+	c.entries.Delete(key)
+}
+
+// purge drops every cached entity
+func (c *entityCache[T]) purge() {
+	c.generation.Add(1)
+	c.entries.Purge()
+}
+
+// idKey builds the cache key of a numeric primary key
+func idKey(id int) string {
+	return "id:" + strconv.Itoa(id)
+}
+
+// uuidKey builds the cache key of a UUID lookup
+func uuidKey(uuid string) string {
+	return "uuid:" + uuid
+}
+
+// clone returns a shallow copy so callers cannot mutate cached entities
+func clone[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
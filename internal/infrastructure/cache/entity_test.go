@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	stdErrors "errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// item is a cached entity reachable by id and uuid
+type item struct {
+	ID     int
+	UUID   string
+	Active bool
+}
+
+var testSettings = Settings{Size: 100, TTL: time.Minute, NegativeTTL: 10 * time.Second}
+
+func newTestCache(settings Settings) (*entityCache[item], *fakeClock) {
+	clock := &fakeClock{now: start}
+	cache := newEntityCache(settings,
+		func(i *item) []string { return []string{idKey(i.ID), uuidKey(i.UUID)} },
+		func(i *item) bool { return !i.Active },
+	)
+	cache.entries.now = clock.Now
+	return cache, clock
+}
+
+// countingFetch returns the item stored under its pointer, counting calls
+type countingFetch struct {
+	value *item
+	err   error
+	calls int
+}
+
+func (f *countingFetch) fetch(ctx context.Context) (*item, error) {
+	f.calls++
+	if f.value == nil {
+		return nil, f.err
+	}
+	copied := *f.value
+	return &copied, f.err
+}
+
+func TestEntityCacheServesHitsUntilTTL(t *testing.T) {
+	cache, clock := newTestCache(testSettings)
+	source := &countingFetch{value: &item{ID: 1, UUID: "a", Active: true}}
+
+	for i := 0; i < 3; i++ {
+		value, err := cache.get(context.Background(), idKey(1), source.fetch)
+		if err != nil || value == nil || value.UUID != "a" {
+			t.Fatalf("get = %+v, %v", value, err)
+		}
+	}
+	if source.calls != 1 {
+		t.Errorf("fetched %d times, want 1", source.calls)
+	}
+
+	// The entity was also cached under its uuid
+	if _, err := cache.get(context.Background(), uuidKey("a"), source.fetch); err != nil || source.calls != 1 {
+		t.Errorf("get by uuid: %v, fetched %d times, want 1", err, source.calls)
+	}
+
+	clock.now = start.Add(time.Minute)
+	if _, err := cache.get(context.Background(), idKey(1), source.fetch); err != nil || source.calls != 2 {
+		t.Errorf("get after TTL: %v, fetched %d times, want 2", err, source.calls)
+	}
+}
+
+func TestEntityCacheNegativeTTL(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *item
+	}{
+		{"missing", nil},
+		{"inactive", &item{ID: 1, UUID: "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, clock := newTestCache(testSettings)
+			source := &countingFetch{value: tt.value}
+
+			cache.get(context.Background(), idKey(1), source.fetch)
+			clock.now = start.Add(10*time.Second - time.Nanosecond)
+			cache.get(context.Background(), idKey(1), source.fetch)
+			if source.calls != 1 {
+				t.Errorf("fetched %d times within the negative TTL, want 1", source.calls)
+			}
+
+			clock.now = start.Add(10 * time.Second)
+			cache.get(context.Background(), idKey(1), source.fetch)
+			if source.calls != 2 {
+				t.Errorf("fetched %d times after the negative TTL, want 2", source.calls)
+			}
+
+			// A negative entry is only cached under the requested key
+			if _, ok := cache.entries.Get(uuidKey("a")); ok {
+				t.Error("negative entry cached under the uuid")
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		cache, _ := newTestCache(Settings{Size: 100, TTL: time.Minute})
+		source := &countingFetch{}
+
+		cache.get(context.Background(), idKey(1), source.fetch)
+		cache.get(context.Background(), idKey(1), source.fetch)
+		if source.calls != 2 {
+			t.Errorf("fetched %d times without negative caching, want 2", source.calls)
+		}
+	})
+}
+
+func TestEntityCacheDoesNotCacheErrors(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+	source := &countingFetch{err: stdErrors.New("throttled")}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.get(context.Background(), idKey(1), source.fetch); err == nil {
+			t.Fatal("get returned no error")
+		}
+	}
+	if source.calls != 2 {
+		t.Errorf("fetched %d times, want 2", source.calls)
+	}
+}
+
+func TestEntityCacheReturnsCopies(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+	source := &countingFetch{value: &item{ID: 1, UUID: "a", Active: true}}
+
+	value, _ := cache.get(context.Background(), idKey(1), source.fetch)
+	value.UUID = "changed"
+
+	value, _ = cache.get(context.Background(), idKey(1), source.fetch)
+	if value.UUID != "a" {
+		t.Errorf("cached entity was changed to %q", value.UUID)
+	}
+}
+
+func TestEntityCacheInvalidateDropsEveryKey(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+	source := &countingFetch{value: &item{ID: 1, UUID: "a", Active: true}}
+	cache.get(context.Background(), idKey(1), source.fetch)
+
+	cache.invalidate(idKey(1))
+	for _, key := range []string{idKey(1), uuidKey("a")} {
+		if _, ok := cache.entries.Get(key); ok {
+			t.Errorf("%s still cached after invalidate", key)
+		}
+	}
+}
+
+func TestEntityCacheDropsFetchesRacingAnInvalidation(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+	stale := &item{ID: 1, UUID: "a", Active: true}
+
+	// The entity changes and is invalidated while it is being fetched
+	value, err := cache.get(context.Background(), idKey(1), func(ctx context.Context) (*item, error) {
+		cache.invalidate(idKey(1))
+		return stale, nil
+	})
+	if err != nil || value.UUID != "a" {
+		t.Fatalf("get = %+v, %v", value, err)
+	}
+	if _, ok := cache.entries.Get(idKey(1)); ok {
+		t.Error("value read before the invalidation was cached")
+	}
+
+	_, err = cache.getMany(context.Background(), []int{1}, func(ctx context.Context, ids []int) (map[int]*item, error) {
+		cache.purge()
+		return map[int]*item{1: stale}, nil
+	})
+	if err != nil {
+		t.Fatalf("getMany: %v", err)
+	}
+	if _, ok := cache.entries.Get(idKey(1)); ok {
+		t.Error("values read before the purge were cached")
+	}
+}
+
+func TestEntityCacheGetMany(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+	cache.store(idKey(1), &item{ID: 1, UUID: "a", Active: true})
+	cache.store(idKey(2), nil)
+
+	var fetched [][]int
+	fetch := func(ctx context.Context, ids []int) (map[int]*item, error) {
+		fetched = append(fetched, append([]int(nil), ids...))
+		return map[int]*item{
+			3: {ID: 3, UUID: "c", Active: true},
+			5: {ID: 5, UUID: "e"},
+		}, nil
+	}
+
+	// 1 is a hit, 2 a cached miss, 3 and 5 are fetched and 4 is missing
+	found, err := cache.getMany(context.Background(), []int{1, 2, 3, 4, 5}, fetch)
+	if err != nil {
+		t.Fatalf("getMany: %v", err)
+	}
+	if !reflect.DeepEqual(fetched, [][]int{{3, 4, 5}}) {
+		t.Errorf("fetched %v, want [[3 4 5]]", fetched)
+	}
+	ids := make([]int, 0, len(found))
+	for id, value := range found {
+		if value == nil || value.ID != id {
+			t.Errorf("found[%d] = %+v", id, value)
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	if !reflect.DeepEqual(ids, []int{1, 3, 5}) {
+		t.Errorf("found ids %v, want [1 3 5]", ids)
+	}
+
+	// Everything is cached now, including the missing 4
+	if _, err := cache.getMany(context.Background(), []int{1, 2, 3, 4, 5}, fetch); err != nil || len(fetched) != 1 {
+		t.Errorf("second getMany: %v, fetched %v", err, fetched)
+	}
+	if _, ok := cache.entries.Get(uuidKey("c")); !ok {
+		t.Error("fetched entity not cached under its uuid")
+	}
+}
+
+func TestEntityCacheGetManyFailsOnFetchErrors(t *testing.T) {
+	cache, _ := newTestCache(testSettings)
+
+	_, err := cache.getMany(context.Background(), []int{1}, func(ctx context.Context, ids []int) (map[int]*item, error) {
+		return nil, stdErrors.New("throttled")
+	})
+	if err == nil {
+		t.Fatal("getMany returned no error")
+	}
+	if _, ok := cache.entries.Get(idKey(1)); ok {
+		t.Error("failed fetch was cached")
+	}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// fakeClock is a clock the test moves by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLRU(capacity int) (*LRU[string], *fakeClock) {
+	clock := &fakeClock{now: start}
+	lru := NewLRU[string](capacity)
+	lru.now = clock.Now
+	return lru, clock
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	lru, clock := newTestLRU(10)
+	lru.Set("short", "a", time.Minute)
+	lru.Set("long", "b", time.Hour)
+
+	clock.now = start.Add(time.Minute - time.Nanosecond)
+	if value, ok := lru.Get("short"); !ok || value != "a" {
+		t.Errorf("Get before expiry = %q, %v", value, ok)
+	}
+
+	clock.now = start.Add(time.Minute)
+	if _, ok := lru.Get("short"); ok {
+		t.Error("Get at expiry found the entry")
+	}
+	if value, ok := lru.Get("long"); !ok || value != "b" {
+		t.Errorf("Get of the live entry = %q, %v", value, ok)
+	}
+	if lru.Len() != 1 {
+		t.Errorf("Len = %d, want the expired entry dropped", lru.Len())
+	}
+
+	// Setting again renews the expiry
+	lru.Set("long", "c", time.Minute)
+	clock.now = clock.now.Add(59 * time.Second)
+	if value, ok := lru.Get("long"); !ok || value != "c" {
+		t.Errorf("Get after renewal = %q, %v", value, ok)
+	}
+}
+
+func TestLRUSetWithoutTTLRemovesTheKey(t *testing.T) {
+	lru, _ := newTestLRU(10)
+	lru.Set("key", "a", time.Minute)
+
+	lru.Set("key", "b", 0)
+	if _, ok := lru.Get("key"); ok {
+		t.Error("Get found a key set with a zero TTL")
+	}
+	lru.Set("other", "b", -time.Minute)
+	if lru.Len() != 0 {
+		t.Errorf("Len = %d, want 0", lru.Len())
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	lru, _ := newTestLRU(3)
+	lru.Set("a", "a", time.Hour)
+	lru.Set("b", "b", time.Hour)
+	lru.Set("c", "c", time.Hour)
+
+	// Reading a and rewriting b leaves c as the least recently used
+	lru.Get("a")
+	lru.Set("b", "b2", time.Hour)
+	lru.Set("d", "d", time.Hour)
+
+	if _, ok := lru.Get("c"); ok {
+		t.Error("c was not evicted")
+	}
+	for key, want := range map[string]string{"a": "a", "b": "b2", "d": "d"} {
+		if value, ok := lru.Get(key); !ok || value != want {
+			t.Errorf("Get(%s) = %q, %v, want %q", key, value, ok, want)
+		}
+	}
+	if lru.Len() != 3 {
+		t.Errorf("Len = %d, want 3", lru.Len())
+	}
+
+	lru.Delete("a")
+	lru.Purge()
+	if lru.Len() != 0 {
+		t.Errorf("Len after Purge = %d, want 0", lru.Len())
+	}
+}
+
+func TestNewLRUHoldsAtLeastOneEntry(t *testing.T) {
+	lru, _ := newTestLRU(0)
+	lru.Set("a", "a", time.Hour)
+	lru.Set("b", "b", time.Hour)
+
+	if _, ok := lru.Get("a"); ok || lru.Len() != 1 {
+		t.Errorf("Len = %d, want only the latest entry", lru.Len())
+	}
+}
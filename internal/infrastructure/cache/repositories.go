@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+
+	"checkout-go/internal/repositories"
+)
+
+// The decorators below wrap the catalog repositories with a read-through
+// cache. Missing and inactive entities are cached with the negative TTL so
+// that lookups for disabled offers do not reach the database on every view.
+
+// OffersRepository caches offer lookups by ID and UUID
+type OffersRepository struct {
+	next  repositories.OffersRepository
+	cache *entityCache[repositories.Offer]
+}
+
+func NewOffersRepository(next repositories.OffersRepository, settings Settings) *OffersRepository {
+	return &OffersRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(o *repositories.Offer) []string { return []string{idKey(o.ID), uuidKey(o.UUID)} },
+			func(o *repositories.Offer) bool { return o.Status != repositories.OfferStatusActive || o.IsTemporary },
+		),
+	}
+}
+
+func (r *OffersRepository) FindByUUID(ctx context.Context, uuid string) (*repositories.Offer, error) {
+	return r.cache.get(ctx, uuidKey(uuid), func(ctx context.Context) (*repositories.Offer, error) {
+		return r.next.FindByUUID(ctx, uuid)
+	})
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *OffersRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *OffersRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *OffersRepository) Find(ctx context.Context, id int) (*repositories.Offer, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.Offer, error) {
+		return r.next.Find(ctx, id)
+	})
+}
+
+func (r *OffersRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Offer, error) {
+	return r.cache.getMany(ctx, ids, r.next.FindMany)
+}
+
+// InvalidateUUID drops the cached offer with the given UUID
+func (r *OffersRepository) InvalidateUUID(uuid string) {
+	r.cache.invalidate(uuidKey(uuid))
+}
+
+func (r *OffersRepository) IncrementCheckoutCount(ctx context.Context, uuid string) error {
+	return r.next.IncrementCheckoutCount(ctx, uuid)
+}
+
+// ProductsRepository caches product lookups by ID
+type ProductsRepository struct {
+	next  repositories.ProductsRepository
+	cache *entityCache[repositories.Product]
+}
+
+func NewProductsRepository(next repositories.ProductsRepository, settings Settings) *ProductsRepository {
+	return &ProductsRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(p *repositories.Product) []string { return []string{idKey(p.ID)} },
+			func(p *repositories.Product) bool {
+				return p.Status != repositories.ProductStatusActive || p.EvaluationStatus == repositories.ProductEvaluationStatusRefused
+			},
+		),
+	}
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *ProductsRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *ProductsRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *ProductsRepository) Find(ctx context.Context, id int) (*repositories.Product, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.Product, error) {
+		return r.next.Find(ctx, id)
+	})
+}
+
+func (r *ProductsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Product, error) {
+	return r.cache.getMany(ctx, ids, r.next.FindMany)
+}
+
+// UsersRepository caches user lookups by ID
+type UsersRepository struct {
+	next  repositories.UsersRepository
+	cache *entityCache[repositories.User]
+}
+
+func NewUsersRepository(next repositories.UsersRepository, settings Settings) *UsersRepository {
+	return &UsersRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(u *repositories.User) []string { return []string{idKey(u.ID)} },
+			func(u *repositories.User) bool { return u.Status != repositories.UserStatusActive },
+		),
+	}
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *UsersRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *UsersRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *UsersRepository) Find(ctx context.Context, id int) (*repositories.User, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.User, error) {
+		return r.next.Find(ctx, id)
+	})
+}
+
+// CompaniesRepository caches company lookups by ID
+type CompaniesRepository struct {
+	next  repositories.CompaniesRepository
+	cache *entityCache[repositories.Company]
+}
+
+func NewCompaniesRepository(next repositories.CompaniesRepository, settings Settings) *CompaniesRepository {
+	return &CompaniesRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(c *repositories.Company) []string { return []string{idKey(c.ID)} },
+			nil,
+		),
+	}
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *CompaniesRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *CompaniesRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *CompaniesRepository) Find(ctx context.Context, id int) (*repositories.Company, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.Company, error) {
+		return r.next.Find(ctx, id)
+	})
+}
+
+// FormatsRepository caches format lookups by ID
+type FormatsRepository struct {
+	next  repositories.FormatsRepository
+	cache *entityCache[repositories.Format]
+}
+
+func NewFormatsRepository(next repositories.FormatsRepository, settings Settings) *FormatsRepository {
+	return &FormatsRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(f *repositories.Format) []string { return []string{idKey(f.ID)} },
+			nil,
+		),
+	}
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *FormatsRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *FormatsRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *FormatsRepository) Find(ctx context.Context, id int) (*repositories.Format, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.Format, error) {
+		return r.next.Find(ctx, id)
+	})
+}
+
+func (r *FormatsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Format, error) {
+	return r.cache.getMany(ctx, ids, r.next.FindMany)
+}
+
+// CheckoutConfigsRepository caches checkout config lookups by ID
+type CheckoutConfigsRepository struct {
+	next  repositories.CheckoutConfigsRepository
+	cache *entityCache[repositories.CheckoutConfig]
+}
+
+func NewCheckoutConfigsRepository(next repositories.CheckoutConfigsRepository, settings Settings) *CheckoutConfigsRepository {
+	return &CheckoutConfigsRepository{
+		next: next,
+		cache: newEntityCache(settings,
+			func(c *repositories.CheckoutConfig) []string { return []string{idKey(c.ID)} },
+			nil,
+		),
+	}
+}
+
+// Invalidate drops the cached entity with the given ID
+func (r *CheckoutConfigsRepository) Invalidate(id int) {
+	r.cache.invalidate(idKey(id))
+}
+
+// Purge drops every cached entity
+func (r *CheckoutConfigsRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r *CheckoutConfigsRepository) Find(ctx context.Context, id int) (*repositories.CheckoutConfig, error) {
+	return r.cache.get(ctx, idKey(id), func(ctx context.Context) (*repositories.CheckoutConfig, error) {
+		return r.next.Find(ctx, id)
+	})
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls for the same key into a single call
+type flightGroup[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[V]
+}
+
+type flightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// do runs fn once per key among concurrent callers. The shared call runs on a
+// context detached from any single caller, so one caller giving up does not
+// fail the others; each caller still stops waiting when its own ctx is done.
+func (g *flightGroup[V]) do(ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[V])
+	}
+	call, inFlight := g.calls[key]
+	if !inFlight {
+		call = &flightCall[V]{done: make(chan struct{})}
+		g.calls[key] = call
+	}
+	g.mu.Unlock()
+
+	if !inFlight {
+		go g.run(ctx, key, call, fn)
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (g *flightGroup[V]) run(ctx context.Context, key string, call *flightCall[V], fn func(ctx context.Context) (V, error)) {
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fetchTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("cache fetch for %s panicked: %v", key, r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn(fetchCtx)
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	stdErrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupCoalescesConcurrentCalls(t *testing.T) {
+	var group flightGroup[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	var started, done sync.WaitGroup
+	results := make([]int, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i], _ = group.do(context.Background(), "key", fn)
+		}(i)
+	}
+	started.Wait()
+	// Give every caller time to join the flight before it lands
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fn ran %d times, want 1", calls.Load())
+	}
+	for i, result := range results {
+		if result != 42 {
+			t.Errorf("caller %d got %d, want 42", i, result)
+		}
+	}
+
+	// A later call runs fn again
+	if _, err := group.do(context.Background(), "key", fn); err != nil || calls.Load() != 2 {
+		t.Errorf("later call: %v, fn ran %d times, want 2", err, calls.Load())
+	}
+}
+
+func TestFlightGroupSurvivesACallerGivingUp(t *testing.T) {
+	var group flightGroup[int]
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		entered <- struct{}{}
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := group.do(ctx, "key", fn)
+		first <- err
+	}()
+	<-entered
+
+	// The second caller joins the flight started by the first one
+	second := make(chan int, 1)
+	go func() {
+		value, _ := group.do(context.Background(), "key", fn)
+		second <- value
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-first; !stdErrors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got %v, want context.Canceled", err)
+	}
+
+	close(release)
+	if value := <-second; value != 42 {
+		t.Errorf("remaining caller got %d, want 42", value)
+	}
+}
+
+func TestFlightGroupReportsPanics(t *testing.T) {
+	var group flightGroup[int]
+
+	_, err := group.do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("do returned no error for a panicking fn")
+	}
+
+	// The key is released for the next call
+	value, err := group.do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Errorf("do after a panic = %d, %v", value, err)
+	}
+}
@@ -0,0 +1,127 @@
+package counters
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// Settings configures an Aggregator
+type Settings struct {
+	// FlushInterval is the period of Run
+	FlushInterval time.Duration
+	// Shards is the number of counter shards of a sharded offer, shard 0 (the
+	// offer item) included
+	Shards int
+	// ShardedOffers are the hot offers whose flushes go to a random shard
+	ShardedOffers map[int]bool
+	// ShardAll shards every offer
+	ShardAll bool
+}
+
+// Aggregator buffers checkout count increments per offer and writes each
+// offer's total with a single ADD per flush, instead of one update of the
+// offer item per checkout. Increments buffered when the process dies are
+// lost, so the counts are approximate.
+type Aggregator struct {
+	repo     repositories.OfferCountersRepository
+	settings Settings
+
+	mu      sync.Mutex
+	pending map[int]int64
+
+	// flushMu keeps flushes from interleaving, so a failed delta is requeued
+	// before the next flush takes the buffer
+	flushMu sync.Mutex
+}
+
+func NewAggregator(repo repositories.OfferCountersRepository, settings Settings) *Aggregator {
+	return &Aggregator{
+		repo:     repo,
+		settings: settings,
+		pending:  make(map[int]int64),
+	}
+}
+
+// Increment buffers one checkout of the offer
+func (a *Aggregator) Increment(offerID int) {
+	a.add(offerID, 1)
+}
+
+func (a *Aggregator) add(offerID int, delta int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending[offerID] += delta
+}
+
+// Flush writes the buffered increments. Deltas that fail are kept for the
+// next flush, except those of offers that no longer exist.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[int]int64)
+	a.mu.Unlock()
+
+	var failed int
+	var firstErr error
+	for offerID, delta := range pending {
+		err := a.repo.AddCheckoutCount(ctx, offerID, a.shardFor(offerID), delta)
+		if err == nil {
+			continue
+		}
+
+		var notFound *errors.EntityNotFoundError
+		if stdErrors.As(err, &notFound) {
+			log.Printf("Dropping %d checkout count increments of missing offer %d", delta, offerID)
+			continue
+		}
+
+		a.add(offerID, delta)
+		failed++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to flush %d of %d checkout counters: %w", failed, len(pending), firstErr)
+	}
+	return nil
+}
+
+// shardFor picks the shard of a flush. Every instance flushes a hot offer
+// once per interval, so a random shard spreads the instances over the shards.
+func (a *Aggregator) shardFor(offerID int) int {
+	if a.settings.Shards <= 1 || !(a.settings.ShardAll || a.settings.ShardedOffers[offerID]) {
+		return 0
+	}
+	return rand.Intn(a.settings.Shards)
+}
+
+// Run flushes every interval until ctx is done. Failures are logged and
+// retried on the next tick; the final flush is left to the caller.
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.settings.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				log.Printf("Checkout counter flush failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package counters
+
+import (
+	"context"
+	stdErrors "errors"
+	"sync"
+	"testing"
+
+	"checkout-go/internal/core/errors"
+)
+
+type addCall struct {
+	offerID int
+	shard   int
+	delta   int64
+}
+
+// fakeCounters records the writes of the aggregator, failing those of the
+// offers in errs
+type fakeCounters struct {
+	mu    sync.Mutex
+	calls []addCall
+	errs  map[int]error
+}
+
+func (r *fakeCounters) AddCheckoutCount(ctx context.Context, offerID, shard int, delta int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.errs[offerID]; err != nil {
+		return err
+	}
+	r.calls = append(r.calls, addCall{offerID, shard, delta})
+	return nil
+}
+
+// written returns the delta written per offer and clears the calls
+func (r *fakeCounters) written() map[int]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[int]int64)
+	for _, call := range r.calls {
+		totals[call.offerID] += call.delta
+	}
+	r.calls = nil
+	return totals
+}
+
+func increment(aggregator *Aggregator, offerID, times int) {
+	for i := 0; i < times; i++ {
+		aggregator.Increment(offerID)
+	}
+}
+
+func TestFlushWritesOneDeltaPerOffer(t *testing.T) {
+	repo := &fakeCounters{}
+	aggregator := NewAggregator(repo, Settings{Shards: 1})
+	increment(aggregator, 1, 3)
+	increment(aggregator, 2, 1)
+
+	if err := aggregator.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(repo.calls) != 2 {
+		t.Errorf("wrote %d times, want once per offer: %+v", len(repo.calls), repo.calls)
+	}
+	for _, call := range repo.calls {
+		if call.shard != 0 {
+			t.Errorf("offer %d written to shard %d, want 0", call.offerID, call.shard)
+		}
+	}
+	if totals := repo.written(); totals[1] != 3 || totals[2] != 1 {
+		t.Errorf("written = %v, want 3 for offer 1 and 1 for offer 2", totals)
+	}
+
+	// Nothing is left to write
+	if err := aggregator.Flush(context.Background()); err != nil || len(repo.calls) != 0 {
+		t.Errorf("second Flush: %v, wrote %+v", err, repo.calls)
+	}
+}
+
+func TestFlushRequeuesFailedDeltas(t *testing.T) {
+	throttled := stdErrors.New("throttled")
+	repo := &fakeCounters{errs: map[int]error{1: throttled}}
+	aggregator := NewAggregator(repo, Settings{Shards: 1})
+	increment(aggregator, 1, 3)
+	increment(aggregator, 2, 2)
+
+	err := aggregator.Flush(context.Background())
+	if !stdErrors.Is(err, throttled) {
+		t.Fatalf("Flush error = %v, want the write error", err)
+	}
+	if totals := repo.written(); totals[1] != 0 || totals[2] != 2 {
+		t.Errorf("written = %v, want only offer 2", totals)
+	}
+
+	// The failed delta is added to the increments buffered since
+	repo.errs = nil
+	increment(aggregator, 1, 1)
+	if err := aggregator.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if totals := repo.written(); len(totals) != 1 || totals[1] != 4 {
+		t.Errorf("written = %v, want 4 for offer 1", totals)
+	}
+}
+
+func TestFlushDropsDeltasOfMissingOffers(t *testing.T) {
+	repo := &fakeCounters{errs: map[int]error{1: errors.NewEntityNotFoundError("offer", "offer 1 not found")}}
+	aggregator := NewAggregator(repo, Settings{Shards: 1})
+	increment(aggregator, 1, 3)
+	increment(aggregator, 2, 1)
+
+	if err := aggregator.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush error = %v, want missing offers dropped", err)
+	}
+
+	repo.errs = nil
+	if err := aggregator.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if totals := repo.written(); len(totals) != 1 || totals[2] != 1 {
+		t.Errorf("written = %v, want only offer 2 once", totals)
+	}
+}
+
+func TestFlushSpreadsShardedOffers(t *testing.T) {
+	repo := &fakeCounters{}
+	aggregator := NewAggregator(repo, Settings{Shards: 4, ShardedOffers: map[int]bool{1: true}})
+
+	shards := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		increment(aggregator, 1, 1)
+		increment(aggregator, 2, 1)
+		if err := aggregator.Flush(context.Background()); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	}
+	for _, call := range repo.calls {
+		switch {
+		case call.offerID == 2 && call.shard != 0:
+			t.Fatalf("offer 2 written to shard %d, want 0", call.shard)
+		case call.shard < 0 || call.shard >= 4:
+			t.Fatalf("offer %d written to shard %d, want 0 to 3", call.offerID, call.shard)
+		case call.offerID == 1:
+			shards[call.shard] = true
+		}
+	}
+	if len(shards) != 4 {
+		t.Errorf("offer 1 written to shards %v, want all 4", shards)
+	}
+}
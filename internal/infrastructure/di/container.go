@@ -1,15 +1,30 @@
 package di
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"checkout-go/internal/config"
+	"checkout-go/internal/core/recovery"
 	"checkout-go/internal/infrastructure/aws"
+	"checkout-go/internal/infrastructure/cache"
+	"checkout-go/internal/infrastructure/counters"
+	"checkout-go/internal/infrastructure/domainevents"
 	"checkout-go/internal/infrastructure/dynamodb"
+	"checkout-go/internal/infrastructure/invalidation"
+	"checkout-go/internal/infrastructure/memory"
 	"checkout-go/internal/repositories"
+	"checkout-go/internal/usecases/abandonedcarts"
+	"checkout-go/internal/usecases/capturelead"
 	"checkout-go/internal/usecases/showcheckout"
 )
 
+// closeFlushTimeout bounds the final checkout counter flush of Close
+const closeFlushTimeout = 5 * time.Second
+
 // Container holds all dependencies
 type Container struct {
 	// Configuration
@@ -30,10 +45,35 @@ type Container struct {
 	pixelsRepo                   repositories.PixelsRepository
 	plansRepo                    repositories.PlansRepository
 	discountsRepo                repositories.DiscountsRepository
+	customersRepo                repositories.CustomersRepository
+	unitOfWork                   repositories.UnitOfWorkFactory
+	offerCountersRepo            repositories.OfferCountersRepository
+	jobCursorsRepo               repositories.JobCursorsRepository
 	fileDriver                   repositories.FileDriver
 
+	// Cache invalidation
+	dynamoClient          *dynamodb.Client
+	invalidationApplier   *invalidation.Applier
+	invalidationPublisher invalidation.Publisher
+	invalidationPoller    *invalidation.Poller
+
+	// Checkout domain events
+	checkoutEvents *domainevents.LocalBus
+
+	// Cart recovery link tokens, nil when no secret is configured
+	recoveryTokens *recovery.Signer
+
+	// Checkout counter aggregation, nil in transactional mode
+	checkoutCounter *counters.Aggregator
+
+	// Background work of long-running processes
+	stopBackground context.CancelFunc
+	background     sync.WaitGroup
+
 	// Use Cases
 	showCheckoutUseCase *showcheckout.UseCase
+	captureLeadUseCase  *capturelead.UseCase
+	abandonedCartsJob   *abandonedcarts.Job
 }
 
 // NewContainer creates and configures a new dependency injection container
@@ -44,75 +84,368 @@ func NewContainer() (*Container, error) {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize AWS configuration
-	awsConfig, err := aws.NewConfig(cfg)
+	container := &Container{config: cfg}
+
+	// Initialize repositories for the configured storage backend
+	if cfg.UsesMemoryStorage() {
+		err = container.initMemoryRepositories()
+	} else {
+		err = container.initDynamoDBRepositories()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize AWS config: %w", err)
+		return nil, err
 	}
 
-	// Initialize DynamoDB client
-	dynamoClient, err := dynamodb.NewClient(awsConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize DynamoDB client: %w", err)
-	}
-
-	// Initialize repositories
-	offersRepo := dynamodb.NewOffersRepository(dynamoClient, cfg)
-	productsRepo := dynamodb.NewProductsRepository(dynamoClient, cfg)
-	usersRepo := dynamodb.NewUsersRepository(dynamoClient, cfg)
-	companiesRepo := dynamodb.NewCompaniesRepository(dynamoClient, cfg)
-	formatsRepo := dynamodb.NewFormatsRepository(dynamoClient, cfg)
-	checkoutConfigsRepo := dynamodb.NewCheckoutConfigsRepository(dynamoClient, cfg)
-	affiliatesRepo := dynamodb.NewAffiliatesRepository(dynamoClient, cfg)
-	productAffiliateSettingsRepo := dynamodb.NewProductAffiliateSettingsRepository(dynamoClient, cfg)
-	checkoutsRepo := dynamodb.NewCheckoutsRepository(dynamoClient, cfg)
-	orderBumpsRepo := dynamodb.NewOrderBumpsRepository(dynamoClient, cfg)
-	reviewsRepo := dynamodb.NewReviewsRepository(dynamoClient, cfg)
-	pixelsRepo := dynamodb.NewPixelsRepository(dynamoClient, cfg)
-	plansRepo := dynamodb.NewPlansRepository(dynamoClient, cfg)
-	discountsRepo := dynamodb.NewDiscountsRepository(dynamoClient, cfg)
+	// Wrap the catalog repositories with read-through caches
+	container.initCaches()
+
+	// Publish the status transitions of written checkouts
+	container.initCheckoutEvents()
+
+	// Connect the caches to the configured invalidation transport
+	if err := container.initInvalidation(); err != nil {
+		return nil, err
+	}
+
+	// Aggregate the offer checkout counts when configured
+	if err := container.initCheckoutCounter(); err != nil {
+		return nil, err
+	}
+	var checkoutCounter repositories.CheckoutCounter
+	if container.checkoutCounter != nil {
+		checkoutCounter = container.checkoutCounter
+	}
+
+	// Sign cart recovery links when a secret is configured
+	if cfg.RecoveryTokenSecret != "" {
+		container.recoveryTokens = recovery.NewSigner([]byte(cfg.RecoveryTokenSecret), cfg.RecoveryTokenTTL)
+	}
 
 	// Initialize file driver (S3-based) with configuration
-	fileDriver := aws.NewS3FileDriver(cfg)
+	container.fileDriver = aws.NewS3FileDriver(cfg)
 
 	// Initialize use cases
-	showCheckoutUseCase := showcheckout.NewUseCase(
-		offersRepo,
-		productsRepo,
-		usersRepo,
-		companiesRepo,
-		formatsRepo,
-		checkoutConfigsRepo,
-		affiliatesRepo,
-		productAffiliateSettingsRepo,
-		checkoutsRepo,
-		orderBumpsRepo,
-		reviewsRepo,
-		pixelsRepo,
-		plansRepo,
-		discountsRepo,
-		fileDriver,
+	container.showCheckoutUseCase = showcheckout.NewUseCase(
+		container.offersRepo,
+		container.productsRepo,
+		container.usersRepo,
+		container.companiesRepo,
+		container.formatsRepo,
+		container.checkoutConfigsRepo,
+		container.affiliatesRepo,
+		container.productAffiliateSettingsRepo,
+		container.checkoutsRepo,
+		container.orderBumpsRepo,
+		container.reviewsRepo,
+		container.pixelsRepo,
+		container.plansRepo,
+		container.discountsRepo,
+		container.customersRepo,
+		container.unitOfWork,
+		checkoutCounter,
+		container.fileDriver,
+		showcheckout.Options{
+			LoadBudgets: showcheckout.LoadBudgets{
+				Required:        cfg.LoadBudgetRequired,
+				OrderBumps:      cfg.LoadBudgetOrderBumps,
+				Reviews:         cfg.LoadBudgetReviews,
+				Pixels:          cfg.LoadBudgetPixels,
+				Plans:           cfg.LoadBudgetPlans,
+				Discounts:       cfg.LoadBudgetDiscounts,
+				ResponseReserve: cfg.LoadBudgetResponseReserve,
+			},
+			ResumeWindow:            cfg.CheckoutResumeWindow,
+			RecoveryTokens:          container.recoveryTokens,
+			InstallmentInterestRate: cfg.InstallmentInterestRate,
+		},
+	)
+
+	container.captureLeadUseCase = capturelead.NewUseCase(container.checkoutsRepo)
+
+	container.abandonedCartsJob = abandonedcarts.NewJob(
+		container.checkoutsRepo,
+		container.jobCursorsRepo,
+		nil,
+		abandonedcarts.Options{
+			IdleThreshold: cfg.AbandonedCartIdleThreshold,
+			BatchSize:     cfg.AbandonedCartBatchSize,
+		},
 	)
 
-	return &Container{
-		config:                       cfg,
-		offersRepo:                   offersRepo,
-		productsRepo:                 productsRepo,
-		usersRepo:                    usersRepo,
-		companiesRepo:                companiesRepo,
-		formatsRepo:                  formatsRepo,
-		checkoutConfigsRepo:          checkoutConfigsRepo,
-		affiliatesRepo:               affiliatesRepo,
-		productAffiliateSettingsRepo: productAffiliateSettingsRepo,
-		checkoutsRepo:                checkoutsRepo,
-		orderBumpsRepo:               orderBumpsRepo,
-		reviewsRepo:                  reviewsRepo,
-		pixelsRepo:                   pixelsRepo,
-		plansRepo:                    plansRepo,
-		discountsRepo:                discountsRepo,
-		fileDriver:                   fileDriver,
-		showCheckoutUseCase:          showCheckoutUseCase,
-	}, nil
+	return container, nil
+}
+
+// initDynamoDBRepositories wires the repositories to DynamoDB
+func (c *Container) initDynamoDBRepositories() error {
+	// Every repository must resolve to a usable table before any is built
+	if err := c.config.ValidateTableNames(dynamodb.BaseTableNames(c.config)); err != nil {
+		return err
+	}
+
+	// Initialize AWS configuration
+	awsConfig, err := aws.NewConfig(c.config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AWS config: %w", err)
+	}
+
+	// Initialize DynamoDB client
+	dynamoClient, err := dynamodb.NewClient(awsConfig, dynamodb.WithEndpoint(c.config.DynamoDBEndpoint()))
+	if err != nil {
+		return fmt.Errorf("failed to initialize DynamoDB client: %w", err)
+	}
+	log.Printf("AWS client settings: %s", aws.DescribeClientSettings(c.config))
+
+	c.dynamoClient = dynamoClient
+
+	cfg := c.config
+	c.offersRepo = dynamodb.NewOffersRepository(dynamoClient, cfg)
+	c.productsRepo = dynamodb.NewProductsRepository(dynamoClient, cfg)
+	c.usersRepo = dynamodb.NewUsersRepository(dynamoClient, cfg)
+	c.companiesRepo = dynamodb.NewCompaniesRepository(dynamoClient, cfg)
+	c.formatsRepo = dynamodb.NewFormatsRepository(dynamoClient, cfg)
+	c.checkoutConfigsRepo = dynamodb.NewCheckoutConfigsRepository(dynamoClient, cfg)
+	c.affiliatesRepo = dynamodb.NewAffiliatesRepository(dynamoClient, cfg)
+	c.productAffiliateSettingsRepo = dynamodb.NewProductAffiliateSettingsRepository(dynamoClient, cfg)
+	c.checkoutsRepo = dynamodb.NewCheckoutsRepository(dynamoClient, cfg)
+	c.orderBumpsRepo = dynamodb.NewOrderBumpsRepository(dynamoClient, cfg)
+	c.reviewsRepo = dynamodb.NewReviewsRepository(dynamoClient, cfg)
+	c.pixelsRepo = dynamodb.NewPixelsRepository(dynamoClient, cfg)
+	c.plansRepo = dynamodb.NewPlansRepository(dynamoClient, cfg)
+	c.discountsRepo = dynamodb.NewDiscountsRepository(dynamoClient, cfg)
+	c.customersRepo = dynamodb.NewCustomersRepository(dynamoClient, cfg)
+	c.unitOfWork = dynamodb.NewUnitOfWorkFactory(dynamoClient, cfg)
+	c.offerCountersRepo = dynamodb.NewOfferCountersRepository(dynamoClient, cfg)
+	c.jobCursorsRepo = dynamodb.NewJobCursorsRepository(dynamoClient, cfg)
+
+	return nil
+}
+
+// initCaches wraps the catalog repositories enabled in the configuration with
+// read-through caches and registers them for invalidation events
+func (c *Container) initCaches() {
+	cfg := c.config
+	c.invalidationApplier = invalidation.NewApplier()
+
+	if cfg.CacheOffers.Enabled {
+		offers := cache.NewOffersRepository(c.offersRepo, cacheSettings(cfg.CacheOffers))
+		c.invalidationApplier.Register("offers", func(event invalidation.Event) {
+			if event.ID == nil && event.UUID == "" {
+				offers.Purge()
+				return
+			}
+			if event.ID != nil {
+				offers.Invalidate(*event.ID)
+			}
+			if event.UUID != "" {
+				offers.InvalidateUUID(event.UUID)
+			}
+		})
+		c.offersRepo = offers
+	}
+	if cfg.CacheProducts.Enabled {
+		products := cache.NewProductsRepository(c.productsRepo, cacheSettings(cfg.CacheProducts))
+		c.invalidationApplier.Register("products", invalidateByID(products))
+		c.productsRepo = products
+	}
+	if cfg.CacheUsers.Enabled {
+		users := cache.NewUsersRepository(c.usersRepo, cacheSettings(cfg.CacheUsers))
+		c.invalidationApplier.Register("users", invalidateByID(users))
+		c.usersRepo = users
+	}
+	if cfg.CacheCompanies.Enabled {
+		companies := cache.NewCompaniesRepository(c.companiesRepo, cacheSettings(cfg.CacheCompanies))
+		c.invalidationApplier.Register("companies", invalidateByID(companies))
+		c.companiesRepo = companies
+	}
+	if cfg.CacheFormats.Enabled {
+		formats := cache.NewFormatsRepository(c.formatsRepo, cacheSettings(cfg.CacheFormats))
+		c.invalidationApplier.Register("formats", invalidateByID(formats))
+		c.formatsRepo = formats
+	}
+	if cfg.CacheCheckoutConfigs.Enabled {
+		checkoutConfigs := cache.NewCheckoutConfigsRepository(c.checkoutConfigsRepo, cacheSettings(cfg.CacheCheckoutConfigs))
+		c.invalidationApplier.Register("checkout_configs", invalidateByID(checkoutConfigs))
+		c.checkoutConfigsRepo = checkoutConfigs
+	}
+}
+
+// idInvalidator is a cache that can drop entities by ID
+type idInvalidator interface {
+	Invalidate(id int)
+	Purge()
+}
+
+// invalidateByID drops the entity of the event, or the whole cache when the
+// event does not identify one
+func invalidateByID(target idInvalidator) invalidation.Handler {
+	return func(event invalidation.Event) {
+		if event.ID == nil {
+			target.Purge()
+			return
+		}
+		target.Invalidate(*event.ID)
+	}
+}
+
+// initInvalidation connects the caches to the configured invalidation transport
+func (c *Container) initInvalidation() error {
+	cfg := c.config
+	switch cfg.InvalidationTransport {
+	case config.InvalidationTransportLocal:
+		bus := invalidation.NewLocalBus()
+		bus.Subscribe(c.invalidationApplier)
+		c.invalidationPublisher = bus
+	case config.InvalidationTransportDynamoDB:
+		if c.dynamoClient == nil {
+			return fmt.Errorf("invalidation transport %q requires the DynamoDB storage backend", cfg.InvalidationTransport)
+		}
+		invalidations := dynamodb.NewInvalidationsRepository(c.dynamoClient, cfg)
+		c.invalidationPublisher = invalidations
+		c.invalidationPoller = invalidation.NewPoller(invalidations, c.invalidationApplier, cfg.InvalidationPollInterval, cfg.InvalidationPollOverlap)
+	}
+	return nil
+}
+
+// initCheckoutEvents wraps the checkouts repository so that the domain events
+// of every written checkout reach the local event bus
+func (c *Container) initCheckoutEvents() {
+	c.checkoutEvents = domainevents.NewLocalBus()
+	c.checkoutEvents.Subscribe(domainevents.LogEvent)
+	c.checkoutsRepo = domainevents.NewCheckoutsRepository(c.checkoutsRepo, c.checkoutEvents)
+}
+
+// initCheckoutCounter creates the checkout count aggregator in aggregated mode
+func (c *Container) initCheckoutCounter() error {
+	cfg := c.config
+	if cfg.CheckoutCounterMode != config.CheckoutCounterAggregated {
+		return nil
+	}
+
+	offerIDs, all, err := cfg.ShardedCheckoutCounterOffers()
+	if err != nil {
+		return err
+	}
+	sharded := make(map[int]bool, len(offerIDs))
+	for _, offerID := range offerIDs {
+		sharded[offerID] = true
+	}
+
+	c.checkoutCounter = counters.NewAggregator(c.offerCountersRepo, counters.Settings{
+		FlushInterval: cfg.CheckoutCounterFlushInterval,
+		Shards:        cfg.CheckoutCounterShards,
+		ShardedOffers: sharded,
+		ShardAll:      all,
+	})
+	return nil
+}
+
+// cacheSettings converts an entity cache configuration to cache settings
+func cacheSettings(cfg config.CacheConfig) cache.Settings {
+	return cache.Settings{
+		Size:        cfg.Size,
+		TTL:         cfg.TTL,
+		NegativeTTL: cfg.NegativeTTL,
+	}
+}
+
+// initMemoryRepositories wires the repositories to an in-memory store seeded from fixtures
+func (c *Container) initMemoryRepositories() error {
+	store, err := memory.LoadStore(c.config.FixturesPath)
+	if err != nil {
+		return fmt.Errorf("failed to load memory fixtures: %w", err)
+	}
+
+	c.offersRepo = memory.NewOffersRepository(store)
+	c.productsRepo = memory.NewProductsRepository(store)
+	c.usersRepo = memory.NewUsersRepository(store)
+	c.companiesRepo = memory.NewCompaniesRepository(store)
+	c.formatsRepo = memory.NewFormatsRepository(store)
+	c.checkoutConfigsRepo = memory.NewCheckoutConfigsRepository(store)
+	c.affiliatesRepo = memory.NewAffiliatesRepository(store)
+	c.productAffiliateSettingsRepo = memory.NewProductAffiliateSettingsRepository(store)
+	c.checkoutsRepo = memory.NewCheckoutsRepository(store)
+	c.orderBumpsRepo = memory.NewOrderBumpsRepository(store)
+	c.reviewsRepo = memory.NewReviewsRepository(store)
+	c.pixelsRepo = memory.NewPixelsRepository(store)
+	c.plansRepo = memory.NewPlansRepository(store)
+	c.discountsRepo = memory.NewDiscountsRepository(store)
+	c.customersRepo = memory.NewCustomersRepository(store)
+	c.unitOfWork = memory.NewUnitOfWorkFactory(store)
+	c.offerCountersRepo = memory.NewOfferCountersRepository(store)
+	c.jobCursorsRepo = memory.NewJobCursorsRepository(store)
+
+	return nil
+}
+
+// StartBackgroundWork starts the invalidation poller and the checkout counter
+// flushes for long-running processes; it must be paired with Close
+func (c *Container) StartBackgroundWork() {
+	if c.stopBackground != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopBackground = cancel
+
+	if c.invalidationPoller != nil {
+		c.background.Add(1)
+		go func() {
+			defer c.background.Done()
+			c.invalidationPoller.Run(ctx)
+		}()
+		log.Printf("Cache invalidation poller started (interval %s)", c.config.InvalidationPollInterval)
+	}
+
+	if c.checkoutCounter != nil {
+		c.background.Add(1)
+		go func() {
+			defer c.background.Done()
+			c.checkoutCounter.Run(ctx)
+		}()
+		log.Printf("Checkout counter flushes started (interval %s)", c.config.CheckoutCounterFlushInterval)
+	}
+}
+
+// PollInvalidations applies pending invalidation events when the poll
+// interval elapsed. Runtimes without background work call it per request.
+func (c *Container) PollInvalidations(ctx context.Context) {
+	if c.invalidationPoller == nil {
+		return
+	}
+	if err := c.invalidationPoller.PollIfDue(ctx); err != nil {
+		log.Printf("Cache invalidation poll failed: %v", err)
+	}
+}
+
+// FlushCheckoutCounters writes the buffered checkout counts. Runtimes
+// without background work call it at the end of each request.
+func (c *Container) FlushCheckoutCounters(ctx context.Context) {
+	if c.checkoutCounter == nil {
+		return
+	}
+	if err := c.checkoutCounter.Flush(ctx); err != nil {
+		log.Printf("Checkout counter flush failed: %v", err)
+	}
+}
+
+// Close stops the background work started by StartBackgroundWork and
+// flushes the buffered checkout counts
+func (c *Container) Close() {
+	if c.stopBackground != nil {
+		c.stopBackground()
+		c.background.Wait()
+		c.stopBackground = nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeFlushTimeout)
+	defer cancel()
+	c.FlushCheckoutCounters(ctx)
+}
+
+// StreamTableResolver maps the physical tables of this environment back to
+// the base tables whose streams are consumed
+func (c *Container) StreamTableResolver() invalidation.TableResolver {
+	return invalidation.NewTableResolver(invalidation.StreamTables, c.config.GetTableName)
 }
 
 // GetConfig returns the application configuration
@@ -177,11 +510,49 @@ func (c *Container) GetDiscountsRepository() repositories.DiscountsRepository {
 	return c.discountsRepo
 }
 
+func (c *Container) GetCustomersRepository() repositories.CustomersRepository {
+	return c.customersRepo
+}
+
+func (c *Container) GetUnitOfWorkFactory() repositories.UnitOfWorkFactory {
+	return c.unitOfWork
+}
+
+func (c *Container) GetJobCursorsRepository() repositories.JobCursorsRepository {
+	return c.jobCursorsRepo
+}
+
 func (c *Container) GetFileDriver() repositories.FileDriver {
 	return c.fileDriver
 }
 
+// GetInvalidationPublisher returns the publisher of the configured invalidation
+// transport, or nil when invalidations are disabled
+func (c *Container) GetInvalidationPublisher() invalidation.Publisher {
+	return c.invalidationPublisher
+}
+
+// GetCheckoutEventBus returns the bus receiving the checkout domain events, for
+// subscribing handlers
+func (c *Container) GetCheckoutEventBus() *domainevents.LocalBus {
+	return c.checkoutEvents
+}
+
+// GetRecoveryTokens returns the signer of cart recovery links, or nil when
+// RECOVERY_TOKEN_SECRET is not set
+func (c *Container) GetRecoveryTokens() *recovery.Signer {
+	return c.recoveryTokens
+}
+
 // Use case getters
 func (c *Container) GetShowCheckoutUseCase() *showcheckout.UseCase {
 	return c.showCheckoutUseCase
 }
+
+func (c *Container) GetCaptureLeadUseCase() *capturelead.UseCase {
+	return c.captureLeadUseCase
+}
+
+func (c *Container) GetAbandonedCartsJob() *abandonedcarts.Job {
+	return c.abandonedCartsJob
+}
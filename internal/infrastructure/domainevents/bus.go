@@ -0,0 +1,69 @@
+package domainevents
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"checkout-go/internal/core/entities"
+)
+
+// Handler reacts to a checkout domain event
+type Handler func(ctx context.Context, event entities.CheckoutEvent)
+
+// LocalBus delivers checkout events synchronously to the handlers of this
+// process. Handlers run after the checkout is written, so they must not fail
+// the request; a panicking handler is logged and skipped.
+type LocalBus struct {
+	mu       sync.RWMutex
+	handlers map[entities.CheckoutEventType][]Handler
+	all      []Handler
+}
+
+// NewLocalBus creates a bus without handlers
+func NewLocalBus() *LocalBus {
+	return &LocalBus{handlers: make(map[entities.CheckoutEventType][]Handler)}
+}
+
+// Subscribe registers handler for the given event types, or for every event
+// when no type is given
+func (b *LocalBus) Subscribe(handler Handler, types ...entities.CheckoutEventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(types) == 0 {
+		b.all = append(b.all, handler)
+		return
+	}
+	for _, eventType := range types {
+		b.handlers[eventType] = append(b.handlers[eventType], handler)
+	}
+}
+
+// Publish delivers events in order to their handlers
+func (b *LocalBus) Publish(ctx context.Context, events []entities.CheckoutEvent) error {
+	for _, event := range events {
+		b.mu.RLock()
+		handlers := append(append([]Handler(nil), b.all...), b.handlers[event.Type]...)
+		b.mu.RUnlock()
+
+		for _, handler := range handlers {
+			deliver(ctx, handler, event)
+		}
+	}
+	return nil
+}
+
+func deliver(ctx context.Context, handler Handler, event entities.CheckoutEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Checkout event handler panicked on %s for %s: %v", event.Type, event.CheckoutUUID, r)
+		}
+	}()
+	handler(ctx, event)
+}
+
+// LogEvent is a handler that logs every event
+func LogEvent(ctx context.Context, event entities.CheckoutEvent) {
+	log.Printf("Checkout %s: %s -> %s (%s)", event.CheckoutUUID, event.From, event.To, event.Type)
+}
@@ -0,0 +1,50 @@
+package domainevents
+
+import (
+	"context"
+	"log"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/repositories"
+)
+
+// CheckoutsRepository publishes the pending events of a checkout once the
+// wrapped repository has written it. Events of a failed write stay on the
+// checkout; UpdateCheckoutWithRetry starts each attempt from a fresh read, so
+// a lost race does not publish the transition twice.
+type CheckoutsRepository struct {
+	repositories.CheckoutsRepository
+	publisher repositories.CheckoutEventPublisher
+}
+
+func NewCheckoutsRepository(next repositories.CheckoutsRepository, publisher repositories.CheckoutEventPublisher) *CheckoutsRepository {
+	return &CheckoutsRepository{CheckoutsRepository: next, publisher: publisher}
+}
+
+func (r *CheckoutsRepository) Create(ctx context.Context, checkout *entities.Checkout) error {
+	if err := r.CheckoutsRepository.Create(ctx, checkout); err != nil {
+		return err
+	}
+	r.publish(ctx, checkout)
+	return nil
+}
+
+func (r *CheckoutsRepository) Update(ctx context.Context, checkout *entities.Checkout) error {
+	if err := r.CheckoutsRepository.Update(ctx, checkout); err != nil {
+		return err
+	}
+	r.publish(ctx, checkout)
+	return nil
+}
+
+// publish delivers the pending events of a written checkout. The write has
+// already succeeded, so a publishing failure is logged instead of returned.
+func (r *CheckoutsRepository) publish(ctx context.Context, checkout *entities.Checkout) {
+	events := checkout.PullEvents()
+	if len(events) == 0 {
+		return
+	}
+	if err := r.publisher.Publish(ctx, events); err != nil {
+		log.Printf("Failed to publish %d events of checkout %s: %v", len(events), checkout.UUID, err)
+	}
+}
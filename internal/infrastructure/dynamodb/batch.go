@@ -0,0 +1,69 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchGetKeys is the DynamoDB limit of keys per BatchGetItem call
+const maxBatchGetKeys = 100
+
+// maxBatchGetAttempts bounds the retries of unprocessed keys, which DynamoDB
+// returns when a batch exceeds the provisioned throughput
+const maxBatchGetAttempts = 8
+
+// batchGetByID loads the items of table whose numeric "id" key is in ids.
+// Duplicate ids are fetched once; ids without an item are absent from the map.
+func batchGetByID[T any](ctx context.Context, client *Client, table string, ids []int, idOf func(*T) int) (map[int]*T, error) {
+	found := make(map[int]*T, len(ids))
+
+	keys := make([]map[string]types.AttributeValue, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		keys = append(keys, map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberN{Value: strconv.Itoa(id)},
+		})
+	}
+
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := min(start+maxBatchGetKeys, len(keys))
+		pending := map[string]types.KeysAndAttributes{
+			table: {Keys: keys[start:end]},
+		}
+
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == maxBatchGetAttempts {
+				return nil, fmt.Errorf("failed to batch get %s: keys still unprocessed after %d attempts", table, attempt)
+			}
+			if attempt > 0 {
+				if err := sleepWithContext(ctx, backoff(attempt)); err != nil {
+					return nil, err
+				}
+			}
+
+			result, err := client.GetDynamoDB().BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: pending})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get %s: %w", table, err)
+			}
+
+			for _, item := range result.Responses[table] {
+				value := new(T)
+				if err := unmarshalItem(item, value); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal %s item: %w", table, err)
+				}
+				found[idOf(value)] = value
+			}
+			pending = result.UnprocessedKeys
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,102 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tableActiveTimeout bounds the wait for a new table to become ACTIVE
+const tableActiveTimeout = 2 * time.Minute
+
+// CreateTable creates a table with on-demand billing and its indexes, stream
+// and time to live. It reports created=false when the table already exists,
+// in which case the existing table is left untouched.
+func CreateTable(ctx context.Context, client *Client, table TableSchema) (created bool, err error) {
+	api := client.GetDynamoDB()
+
+	_, err = api.CreateTable(ctx, createTableInput(table))
+	if err != nil {
+		var inUse *types.ResourceInUseException
+		if errors.As(err, &inUse) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create table %s: %w", table.Name, err)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(api)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: &table.Name}, tableActiveTimeout); err != nil {
+		return true, fmt.Errorf("failed waiting for table %s: %w", table.Name, err)
+	}
+
+	if table.TTLAttribute != "" {
+		_, err := api.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+			TableName: &table.Name,
+			TimeToLiveSpecification: &types.TimeToLiveSpecification{
+				AttributeName: &table.TTLAttribute,
+				Enabled:       aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return true, fmt.Errorf("failed to enable time to live on table %s: %w", table.Name, err)
+		}
+	}
+
+	return true, nil
+}
+
+// createTableInput translates a table schema into a CreateTable request
+func createTableInput(table TableSchema) *dynamodb.CreateTableInput {
+	attributes := map[string]types.ScalarAttributeType{}
+	addKey := func(key *KeyAttribute, keyType types.KeyType, schema *[]types.KeySchemaElement) {
+		if key == nil {
+			return
+		}
+		attributes[key.Name] = key.Type
+		*schema = append(*schema, types.KeySchemaElement{AttributeName: aws.String(key.Name), KeyType: keyType})
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(table.Name),
+		BillingMode: types.BillingModePayPerRequest,
+	}
+	addKey(&table.HashKey, types.KeyTypeHash, &input.KeySchema)
+	addKey(table.RangeKey, types.KeyTypeRange, &input.KeySchema)
+
+	for _, index := range table.Indexes {
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}
+		addKey(&index.HashKey, types.KeyTypeHash, &gsi.KeySchema)
+		addKey(index.RangeKey, types.KeyTypeRange, &gsi.KeySchema)
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: attributes[name],
+		})
+	}
+
+	if table.Stream {
+		input.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: types.StreamViewTypeNewAndOldImages,
+		}
+	}
+
+	return input
+}
@@ -0,0 +1,110 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/core/entities"
+	coreErrors "checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// The listings read the created_at indexes of the checkouts table (see
+// Tables). Each one projects ALL attributes and sorts by created_at, a
+// fixed-width RFC 3339 UTC string (timeLayout); the affiliate index is sparse.
+// The status index is sharded, see checkouts_status.go.
+
+func (r *CheckoutsRepository) FindByProduct(ctx context.Context, productID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	return r.findPage(ctx, IndexCheckoutsByProduct, "product_id", &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", productID)}, query)
+}
+
+func (r *CheckoutsRepository) FindByOffer(ctx context.Context, offerID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	return r.findPage(ctx, IndexCheckoutsByOffer, "offer_id", &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)}, query)
+}
+
+func (r *CheckoutsRepository) FindByAffiliate(ctx context.Context, affiliateID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	return r.findPage(ctx, IndexCheckoutsByAffiliate, "affiliate_id", &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", affiliateID)}, query)
+}
+
+// findPage reads one page of an index partition within the created_at range
+func (r *CheckoutsRepository) findPage(ctx context.Context, index, partitionKey string, partitionValue types.AttributeValue, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	startKey, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, coreErrors.NewInvalidParameterValueError("cursor", query.Cursor)
+	}
+
+	result, err := r.queryPartition(ctx, index, partitionKey, partitionValue, query, startKey)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &repositories.CheckoutPage{Items: make([]*entities.Checkout, 0, len(result.Items))}
+	for _, item := range result.Items {
+		var checkout entities.Checkout
+		if err := unmarshalItem(item, &checkout); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal checkout: %w", err)
+		}
+		page.Items = append(page.Items, &checkout)
+	}
+
+	page.NextCursor, err = encodeCursor(result.LastEvaluatedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checkouts cursor: %w", err)
+	}
+
+	return page, nil
+}
+
+// queryPartition reads up to a page of an index partition within the
+// created_at range, starting after startKey
+func (r *CheckoutsRepository) queryPartition(ctx context.Context, index, partitionKey string, partitionValue types.AttributeValue, query repositories.CheckoutQuery, startKey map[string]types.AttributeValue) (*dynamodb.QueryOutput, error) {
+	keyCondition := "#pk = :pk"
+	names := map[string]string{"#pk": partitionKey}
+	values := map[string]types.AttributeValue{":pk": partitionValue}
+
+	// The range is half-open; BETWEEN is inclusive, so the upper bound moves
+	// back by the smallest step a timestamp can take
+	from, to := !query.From.IsZero(), !query.To.IsZero()
+	switch {
+	case from && to:
+		keyCondition += " AND #created_at BETWEEN :from AND :to"
+		values[":from"] = timeAttribute(query.From)
+		values[":to"] = timeAttribute(query.To.Add(-time.Nanosecond))
+	case from:
+		keyCondition += " AND #created_at >= :from"
+		values[":from"] = timeAttribute(query.From)
+	case to:
+		keyCondition += " AND #created_at < :to"
+		values[":to"] = timeAttribute(query.To)
+	}
+	if from || to {
+		names["#created_at"] = "created_at"
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 &r.tableName,
+		IndexName:                 stringPtr(index),
+		KeyConditionExpression:    stringPtr(keyCondition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ExclusiveStartKey:         startKey,
+		ScanIndexForward:          aws.Bool(!query.Descending),
+		Limit:                     int32Ptr(int32(query.PageSize())),
+	}
+
+	result, err := r.client.GetDynamoDB().Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkouts by %s: %w", partitionKey, err)
+	}
+	return result, nil
+}
+
+// timeAttribute formats a created_at bound the way checkouts store it
+func timeAttribute(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: formatTime(t)}
+}
@@ -0,0 +1,311 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/core/entities"
+	coreErrors "checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// checkoutStatusShards is how many partitions of the status index hold the
+// checkouts of one status. A status has only a few values and most checkouts
+// share one of them, so an index keyed by status alone would put them in a
+// few hot partitions. Changing it moves every checkout to another shard on
+// its next write; items not yet rewritten are missed by the listing.
+const checkoutStatusShards = 8
+
+// checkoutItem is how a checkout is stored: the entity plus the key of the
+// status index
+type checkoutItem struct {
+	entities.Checkout
+	StatusShard string `dynamodb:"status_shard"`
+}
+
+// marshalCheckout encodes a checkout with its status shard
+func marshalCheckout(checkout *entities.Checkout) (map[string]types.AttributeValue, error) {
+	return marshalItem(checkoutItem{
+		Checkout:    *checkout,
+		StatusShard: statusShard(checkout.Status, checkout.UUID),
+	})
+}
+
+// statusShard returns the status index partition of a checkout, the status
+// followed by a shard number derived from the UUID, e.g. "ACCESSED#3"
+func statusShard(status entities.CheckoutStatus, uuid string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(uuid))
+	return statusShardName(status, int(hash.Sum32()%checkoutStatusShards))
+}
+
+func statusShardName(status entities.CheckoutStatus, shard int) string {
+	return fmt.Sprintf("%s#%d", status, shard)
+}
+
+// FindByStatus reads a page from every shard of the status and merges them
+// by created_at. The cursor holds the position reached in each shard that
+// still has checkouts.
+func (r *CheckoutsRepository) FindByStatus(ctx context.Context, status entities.CheckoutStatus, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	positions, err := decodeShardCursor(query.Cursor)
+	if err != nil {
+		return nil, coreErrors.NewInvalidParameterValueError("cursor", query.Cursor)
+	}
+	for shard := range positions {
+		if !strings.HasPrefix(shard, string(status)+"#") {
+			return nil, coreErrors.NewInvalidParameterValueError("cursor", query.Cursor)
+		}
+	}
+	if positions == nil {
+		positions = make(map[string]map[string]types.AttributeValue, checkoutStatusShards)
+		for shard := 0; shard < checkoutStatusShards; shard++ {
+			positions[statusShardName(status, shard)] = nil
+		}
+	}
+
+	results := make([]shardResult, 0, len(positions))
+	for shard, startKey := range positions {
+		result, err := r.queryPartition(ctx, IndexCheckoutsByStatus, "status_shard", &types.AttributeValueMemberS{Value: shard}, query, startKey)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, shardResult{
+			shard:            shard,
+			startKey:         startKey,
+			items:            result.Items,
+			lastEvaluatedKey: result.LastEvaluatedKey,
+		})
+	}
+
+	taken, next := mergeShards(results, query.PageSize(), query.Descending)
+
+	page := &repositories.CheckoutPage{Items: make([]*entities.Checkout, 0, len(taken))}
+	for _, item := range taken {
+		var checkout entities.Checkout
+		if err := unmarshalItem(item, &checkout); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal checkout: %w", err)
+		}
+		page.Items = append(page.Items, &checkout)
+	}
+
+	page.NextCursor, err = encodeShardCursor(next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checkouts cursor: %w", err)
+	}
+
+	return page, nil
+}
+
+// shardResult is what one status shard returned for a page
+type shardResult struct {
+	shard            string
+	startKey         map[string]types.AttributeValue
+	items            []map[string]types.AttributeValue
+	lastEvaluatedKey map[string]types.AttributeValue
+}
+
+// mergeShards takes up to pageSize items of the shards in created_at order
+// and returns them with the position to resume each unfinished shard from.
+//
+// Each shard is already sorted, so the first pageSize items of the merge
+// are among the items read. A shard that stopped early (a Query stops at
+// 1 MB) may hold more items before its last one sorts after other shards'
+// items, so nothing past the earliest last item of an unfinished shard is
+// taken. Items with the same created_at keep their shard order, which keeps
+// what is taken from each shard a prefix of what it returned.
+func mergeShards(results []shardResult, pageSize int, descending bool) ([]map[string]types.AttributeValue, map[string]map[string]types.AttributeValue) {
+	sortsBefore := func(a, b string) bool {
+		if descending {
+			return a > b
+		}
+		return a < b
+	}
+
+	type candidate struct {
+		result    int
+		createdAt string
+	}
+	var candidates []candidate
+	cutoff, bounded := "", false
+	for i, result := range results {
+		for _, item := range result.items {
+			candidates = append(candidates, candidate{result: i, createdAt: createdAtOf(item)})
+		}
+		if len(result.lastEvaluatedKey) > 0 && len(result.items) > 0 {
+			last := createdAtOf(result.items[len(result.items)-1])
+			if !bounded || sortsBefore(last, cutoff) {
+				cutoff, bounded = last, true
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return sortsBefore(candidates[i].createdAt, candidates[j].createdAt)
+	})
+
+	taken := make([]map[string]types.AttributeValue, 0, pageSize)
+	counts := make([]int, len(results))
+	for _, c := range candidates {
+		if len(taken) == pageSize || (bounded && sortsBefore(cutoff, c.createdAt)) {
+			break
+		}
+		taken = append(taken, results[c.result].items[counts[c.result]])
+		counts[c.result]++
+	}
+
+	next := make(map[string]map[string]types.AttributeValue, len(results))
+	for i, result := range results {
+		switch {
+		case counts[i] < len(result.items) && counts[i] > 0:
+			next[result.shard] = indexKey(result.items[counts[i]-1])
+		case counts[i] < len(result.items):
+			next[result.shard] = result.startKey
+		case len(result.lastEvaluatedKey) > 0:
+			next[result.shard] = result.lastEvaluatedKey
+		}
+	}
+	return taken, next
+}
+
+// createdAtOf returns the stored created_at of an item
+func createdAtOf(item map[string]types.AttributeValue) string {
+	if createdAt, ok := item["created_at"].(*types.AttributeValueMemberS); ok {
+		return createdAt.Value
+	}
+	return ""
+}
+
+// indexKey returns the key of an item in the status index, which is where
+// a Query resumes from
+func indexKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"uuid":         item["uuid"],
+		"status_shard": item["status_shard"],
+		"created_at":   item["created_at"],
+	}
+}
+
+// encodeShardCursor turns the positions of the unfinished shards into an
+// opaque cursor; a shard still at its start has an empty position. No
+// unfinished shard yields an empty cursor.
+func encodeShardCursor(positions map[string]map[string]types.AttributeValue) (string, error) {
+	if len(positions) == 0 {
+		return "", nil
+	}
+
+	cursors := make(map[string]string, len(positions))
+	for shard, key := range positions {
+		cursor, err := encodeCursor(key)
+		if err != nil {
+			return "", err
+		}
+		cursors[shard] = cursor
+	}
+
+	data, err := json.Marshal(cursors)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeShardCursor turns a cursor produced by encodeShardCursor back into
+// the shard positions. An empty cursor yields nil, meaning every shard from
+// its start.
+func decodeShardCursor(cursor string) (map[string]map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursors map[string]string
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, err
+	}
+	if len(cursors) == 0 {
+		return nil, fmt.Errorf("cursor has no shards")
+	}
+
+	positions := make(map[string]map[string]types.AttributeValue, len(cursors))
+	for shard, shardCursor := range cursors {
+		key, err := decodeCursor(shardCursor)
+		if err != nil {
+			return nil, err
+		}
+		positions[shard] = key
+	}
+	return positions, nil
+}
+
+// BackfillStatusShards sets status_shard on the checkouts of the table that
+// lack it or carry the shard of another status, so that checkouts written
+// before the status index was sharded are listed again. A checkout whose
+// status changes meanwhile is left to its writer. It returns how many
+// checkouts were updated.
+func BackfillStatusShards(ctx context.Context, client *Client, tableName string) (int, error) {
+	input := &dynamodb.ScanInput{
+		TableName:                stringPtr(tableName),
+		ProjectionExpression:     stringPtr("#uuid, #status, #status_shard"),
+		ExpressionAttributeNames: map[string]string{"#uuid": "uuid", "#status": "status", "#status_shard": "status_shard"},
+	}
+
+	updated := 0
+	paginator := dynamodb.NewScanPaginator(client.GetDynamoDB(), input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return updated, fmt.Errorf("failed to scan %s: %w", tableName, err)
+		}
+
+		for _, item := range page.Items {
+			var stored struct {
+				UUID        string                  `dynamodb:"uuid"`
+				Status      entities.CheckoutStatus `dynamodb:"status"`
+				StatusShard string                  `dynamodb:"status_shard"`
+			}
+			if err := unmarshalItem(item, &stored); err != nil {
+				return updated, fmt.Errorf("failed to unmarshal checkout: %w", err)
+			}
+			shard := statusShard(stored.Status, stored.UUID)
+			if stored.Status == "" || stored.StatusShard == shard {
+				continue
+			}
+
+			_, err := client.GetDynamoDB().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:           stringPtr(tableName),
+				Key:                 map[string]types.AttributeValue{"uuid": &types.AttributeValueMemberS{Value: stored.UUID}},
+				UpdateExpression:    stringPtr("SET #status_shard = :shard"),
+				ConditionExpression: stringPtr("#status = :status"),
+				ExpressionAttributeNames: map[string]string{
+					"#status":       "status",
+					"#status_shard": "status_shard",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":shard":  &types.AttributeValueMemberS{Value: shard},
+					":status": &types.AttributeValueMemberS{Value: string(stored.Status)},
+				},
+			})
+			if err != nil {
+				var conditionFailed *types.ConditionalCheckFailedException
+				if errors.As(err, &conditionFailed) {
+					continue
+				}
+				return updated, fmt.Errorf("failed to update checkout %s: %w", stored.UUID, err)
+			}
+			updated++
+		}
+	}
+	return updated, nil
+}
@@ -0,0 +1,197 @@
+package dynamodb
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/core/entities"
+)
+
+func TestMarshalCheckoutAddsStatusShard(t *testing.T) {
+	checkout := &entities.Checkout{
+		UUID:      "366b643f-3ad1-4204-9655-cdd079d2498c",
+		ProductID: 2,
+		Status:    entities.CheckoutStatusAccessed,
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	item, err := marshalCheckout(checkout)
+	if err != nil {
+		t.Fatalf("marshalCheckout: %v", err)
+	}
+
+	shard, ok := item["status_shard"].(*types.AttributeValueMemberS)
+	if !ok || shard.Value != statusShard(checkout.Status, checkout.UUID) {
+		t.Errorf("status_shard = %v, want %s", item["status_shard"], statusShard(checkout.Status, checkout.UUID))
+	}
+	for _, name := range []string{"uuid", "status", "product_id", "created_at"} {
+		if _, ok := item[name]; !ok {
+			t.Errorf("item has no %s", name)
+		}
+	}
+
+	var decoded entities.Checkout
+	if err := unmarshalItem(item, &decoded); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+	if decoded.UUID != checkout.UUID || decoded.Status != checkout.Status || !decoded.CreatedAt.Equal(checkout.CreatedAt) {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
+
+func TestCheckoutModelMatchesIndexes(t *testing.T) {
+	for _, table := range Tables {
+		if table.Name != TableCheckouts {
+			continue
+		}
+		if drifts := KeyDrifts(table); len(drifts) > 0 {
+			t.Errorf("checkouts key drifts: %+v", drifts)
+		}
+	}
+}
+
+// statusIndex holds the items of the status index the way a Query reads
+// them: by shard, sorted by created_at
+type statusIndex map[string][]map[string]types.AttributeValue
+
+func (index statusIndex) query(shard string, startKey map[string]types.AttributeValue, limit int, descending bool) shardResult {
+	items := append([]map[string]types.AttributeValue(nil), index[shard]...)
+	if descending {
+		sort.SliceStable(items, func(i, j int) bool { return createdAtOf(items[i]) > createdAtOf(items[j]) })
+	}
+
+	start := 0
+	if startKey != nil {
+		for i, item := range items {
+			if item["uuid"].(*types.AttributeValueMemberS).Value == startKey["uuid"].(*types.AttributeValueMemberS).Value {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+limit, len(items))
+
+	result := shardResult{shard: shard, startKey: startKey, items: items[start:end]}
+	if end < len(items) {
+		result.lastEvaluatedKey = indexKey(items[end-1])
+	}
+	return result
+}
+
+func TestMergeShardsPagesInCreatedAtOrder(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	index := make(statusIndex)
+	for i := 0; i < 50; i++ {
+		checkout := &entities.Checkout{
+			UUID:   fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			Status: entities.CheckoutStatusAccessed,
+			// Some checkouts share a created_at
+			CreatedAt: base.Add(time.Duration(i/2) * time.Millisecond),
+		}
+		item, err := marshalCheckout(checkout)
+		if err != nil {
+			t.Fatalf("marshalCheckout: %v", err)
+		}
+		shard := statusShard(checkout.Status, checkout.UUID)
+		index[shard] = append(index[shard], item)
+	}
+
+	for _, descending := range []bool{false, true} {
+		t.Run(fmt.Sprintf("descending=%v", descending), func(t *testing.T) {
+			seen := make(map[string]bool)
+			var order []string
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > 50 {
+					t.Fatal("paging does not end")
+				}
+
+				positions, err := decodeShardCursor(cursor)
+				if err != nil {
+					t.Fatalf("decodeShardCursor: %v", err)
+				}
+				if positions == nil {
+					positions = make(map[string]map[string]types.AttributeValue)
+					for shard := 0; shard < checkoutStatusShards; shard++ {
+						positions[statusShardName(entities.CheckoutStatusAccessed, shard)] = nil
+					}
+				}
+
+				var results []shardResult
+				for shard, startKey := range positions {
+					results = append(results, index.query(shard, startKey, 7, descending))
+				}
+				taken, next := mergeShards(results, 7, descending)
+				if len(taken) == 0 {
+					t.Fatal("page is empty")
+				}
+				for _, item := range taken {
+					uuid := item["uuid"].(*types.AttributeValueMemberS).Value
+					if seen[uuid] {
+						t.Errorf("%s listed twice", uuid)
+					}
+					seen[uuid] = true
+					order = append(order, createdAtOf(item))
+				}
+
+				if cursor, err = encodeShardCursor(next); err != nil {
+					t.Fatalf("encodeShardCursor: %v", err)
+				}
+				if cursor == "" {
+					break
+				}
+			}
+
+			if len(seen) != 50 {
+				t.Errorf("listed %d checkouts, want 50", len(seen))
+			}
+			sorted := sort.SliceIsSorted(order, func(i, j int) bool {
+				if descending {
+					return order[i] > order[j]
+				}
+				return order[i] < order[j]
+			})
+			if !sorted {
+				t.Errorf("checkouts out of order: %v", order)
+			}
+		})
+	}
+}
+
+func TestMergeShardsStopsAtTruncatedShard(t *testing.T) {
+	item := func(uuid, createdAt string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"uuid":         &types.AttributeValueMemberS{Value: uuid},
+			"status_shard": &types.AttributeValueMemberS{Value: "ACCESSED#0"},
+			"created_at":   &types.AttributeValueMemberS{Value: createdAt},
+		}
+	}
+
+	// Shard 0 stopped after one item although the page holds three
+	results := []shardResult{
+		{shard: "ACCESSED#0", items: []map[string]types.AttributeValue{item("a", "2024-01-01")}, lastEvaluatedKey: indexKey(item("a", "2024-01-01"))},
+		{shard: "ACCESSED#1", items: []map[string]types.AttributeValue{item("b", "2024-01-02"), item("c", "2024-01-03")}},
+	}
+
+	taken, next := mergeShards(results, 3, false)
+	if len(taken) != 1 || taken[0]["uuid"].(*types.AttributeValueMemberS).Value != "a" {
+		t.Errorf("took %d items, want only a", len(taken))
+	}
+	if _, ok := next["ACCESSED#0"]; !ok {
+		t.Error("shard 0 is not resumed")
+	}
+	if key, ok := next["ACCESSED#1"]; !ok || key != nil {
+		t.Errorf("shard 1 resumes from %v, want its start", key)
+	}
+}
+
+func TestDecodeShardCursorRejectsGarbage(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bnVsbA", "e30"} {
+		if _, err := decodeShardCursor(cursor); err == nil {
+			t.Errorf("decodeShardCursor(%q) succeeded", cursor)
+		}
+	}
+}
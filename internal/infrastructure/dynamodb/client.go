@@ -12,8 +12,8 @@ type Client struct {
 }
 
 // NewClient creates a new DynamoDB client wrapper
-func NewClient(cfg aws.Config) (*Client, error) {
-	client := dynamodb.NewFromConfig(cfg)
+func NewClient(cfg aws.Config, optFns ...func(*dynamodb.Options)) (*Client, error) {
+	client := dynamodb.NewFromConfig(cfg, optFns...)
 
 	return &Client{
 		dynamoDB: client,
@@ -21,6 +21,16 @@ func NewClient(cfg aws.Config) (*Client, error) {
 	}, nil
 }
 
+// WithEndpoint points the client at a custom endpoint such as DynamoDB Local;
+// an empty endpoint keeps the default
+func WithEndpoint(endpoint string) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}
+}
+
 // GetDynamoDB returns the underlying DynamoDB client
 func (c *Client) GetDynamoDB() *dynamodb.Client {
 	return c.dynamoDB
@@ -0,0 +1,120 @@
+package dynamodb
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// attributeTag is the struct tag holding the attribute names of the models.
+// The SDK reads `dynamodbav` by default, which the models do not use.
+//
+// Before the tag was read, items were encoded under the Go field names
+// (ProductID, CreatedAt, ...) and decoded by matching those names case
+// insensitively, so snake_case attributes written by the TypeScript service
+// decoded to zero values. Items still holding the Go field names are read
+// through legacyAttributes and take the tagged names on their next write.
+const attributeTag = "dynamodb"
+
+// timeLayout is how times are stored: RFC 3339 in UTC with all nine fraction
+// digits. RFC3339Nano drops the trailing zeros of the fraction, so its
+// strings do not sort in time order within a second, which the created_at
+// range keys rely on. Both forms decode the same way.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// marshalItem encodes a model using its `dynamodb` tags
+func marshalItem(in interface{}) (map[string]types.AttributeValue, error) {
+	return attributevalue.MarshalMapWithOptions(in, func(o *attributevalue.EncoderOptions) {
+		o.TagKey = attributeTag
+		o.EncodeTime = encodeTime
+	})
+}
+
+func encodeTime(t time.Time) (types.AttributeValue, error) {
+	return &types.AttributeValueMemberS{Value: formatTime(t)}, nil
+}
+
+// formatTime formats t the way items store it
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// unmarshalItem decodes an item into a model using its `dynamodb` tags,
+// falling back to the Go field names of items written before them
+func unmarshalItem(item map[string]types.AttributeValue, out interface{}) error {
+	return attributevalue.UnmarshalMapWithOptions(withTaggedNames(item, out), out, func(o *attributevalue.DecoderOptions) {
+		o.TagKey = attributeTag
+	})
+}
+
+// legacyAttribute pairs the tagged name of a model field with its Go name
+type legacyAttribute struct {
+	tagged string
+	legacy string
+}
+
+var legacyAttributesByType sync.Map // reflect.Type -> []legacyAttribute
+
+// withTaggedNames returns item with the attributes stored under a legacy Go
+// field name renamed to the tagged name, unless the tagged one is present.
+// item is returned as is when nothing needs renaming.
+func withTaggedNames(item map[string]types.AttributeValue, out interface{}) map[string]types.AttributeValue {
+	var renamed map[string]types.AttributeValue
+	for _, attribute := range legacyAttributes(reflect.TypeOf(out)) {
+		if _, ok := item[attribute.tagged]; ok {
+			continue
+		}
+		value, ok := item[attribute.legacy]
+		if !ok {
+			continue
+		}
+		if renamed == nil {
+			renamed = make(map[string]types.AttributeValue, len(item))
+			for name, value := range item {
+				renamed[name] = value
+			}
+		}
+		renamed[attribute.tagged] = value
+		delete(renamed, attribute.legacy)
+	}
+
+	if renamed == nil {
+		return item
+	}
+	return renamed
+}
+
+// legacyAttributes lists the fields of a model whose tagged name differs
+// from the Go name, including the fields of embedded structs
+func legacyAttributes(t reflect.Type) []legacyAttribute {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := legacyAttributesByType.Load(t); ok {
+		return cached.([]legacyAttribute)
+	}
+
+	var attributes []legacyAttribute
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(attributeTag), ",")
+		if field.Anonymous && name == "" {
+			attributes = append(attributes, legacyAttributes(field.Type)...)
+			continue
+		}
+		if !field.IsExported() || name == "" || name == "-" || name == field.Name {
+			continue
+		}
+		attributes = append(attributes, legacyAttribute{tagged: name, legacy: field.Name})
+	}
+
+	legacyAttributesByType.Store(t, attributes)
+	return attributes
+}
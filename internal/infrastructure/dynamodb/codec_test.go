@@ -0,0 +1,314 @@
+package dynamodb
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/repositories"
+)
+
+func TestUnmarshalItemDecodesPlan(t *testing.T) {
+	// A plan as the TypeScript service writes it, offer under offerId
+	item := map[string]types.AttributeValue{
+		"id":                         &types.AttributeValueMemberN{Value: "7"},
+		"uuid":                       &types.AttributeValueMemberS{Value: "aaaaaaaa-0000-4000-8000-000000000002"},
+		"offerId":                    &types.AttributeValueMemberN{Value: "42"},
+		"title":                      &types.AttributeValueMemberS{Value: "Anual"},
+		"tag":                        &types.AttributeValueMemberS{Value: "Nenhum"},
+		"price":                      &types.AttributeValueMemberN{Value: "99900"},
+		"promotional_price":          &types.AttributeValueMemberN{Value: "89900"},
+		"first_charge_price_enabled": &types.AttributeValueMemberBOOL{Value: false},
+		"first_charge_price":         &types.AttributeValueMemberN{Value: "0"},
+		"charge_frequency":           &types.AttributeValueMemberS{Value: "YEARLY"},
+		"is_default":                 &types.AttributeValueMemberBOOL{Value: true},
+	}
+
+	var plan repositories.Plan
+	if err := unmarshalItem(item, &plan); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+
+	want := repositories.Plan{
+		ID:               7,
+		UUID:             "aaaaaaaa-0000-4000-8000-000000000002",
+		OfferID:          42,
+		Title:            "Anual",
+		Tag:              "Nenhum",
+		Price:            99900,
+		PromotionalPrice: 89900,
+		ChargeFrequency:  "YEARLY",
+		IsDefault:        true,
+	}
+	if plan != want {
+		t.Errorf("plan = %+v, want %+v", plan, want)
+	}
+}
+
+func TestPlanModelMatchesOfferIndex(t *testing.T) {
+	item, err := marshalItem(repositories.Plan{ID: 1, OfferID: 42})
+	if err != nil {
+		t.Fatalf("marshalItem: %v", err)
+	}
+
+	for _, table := range Tables {
+		if table.Name != TablePlans {
+			continue
+		}
+		for _, index := range table.Indexes {
+			if _, ok := item[index.HashKey.Name]; !ok {
+				t.Errorf("plan item has no %s attribute for index %s", index.HashKey.Name, index.Name)
+			}
+		}
+	}
+}
+
+func TestUnmarshalItemReadsLegacyFieldNames(t *testing.T) {
+	// A checkout as written before the `dynamodb` tags were read: attributes
+	// named after the Go fields, with the UUID key renamed by hand
+	item := map[string]types.AttributeValue{
+		"uuid":            &types.AttributeValueMemberS{Value: "366b643f-3ad1-4204-9655-cdd079d2498c"},
+		"OfferID":         &types.AttributeValueMemberN{Value: "1"},
+		"ProductID":       &types.AttributeValueMemberN{Value: "2"},
+		"Status":          &types.AttributeValueMemberS{Value: "ACCESSED"},
+		"IsMobile":        &types.AttributeValueMemberBOOL{Value: true},
+		"EmailSentAmount": &types.AttributeValueMemberN{Value: "3"},
+		"CreatedAt":       &types.AttributeValueMemberS{Value: "2024-01-15T10:30:00Z"},
+	}
+
+	var checkout entities.Checkout
+	if err := unmarshalItem(item, &checkout); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+
+	if checkout.UUID != "366b643f-3ad1-4204-9655-cdd079d2498c" {
+		t.Errorf("UUID = %q", checkout.UUID)
+	}
+	if checkout.OfferID == nil || *checkout.OfferID != 1 {
+		t.Errorf("OfferID = %v, want 1", checkout.OfferID)
+	}
+	if checkout.ProductID != 2 || checkout.Status != entities.CheckoutStatusAccessed || !checkout.IsMobile || checkout.EmailSentAmount != 3 {
+		t.Errorf("checkout = %+v", checkout)
+	}
+	if want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC); !checkout.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", checkout.CreatedAt, want)
+	}
+
+	// The next write stores the tagged names only
+	written, err := marshalItem(&checkout)
+	if err != nil {
+		t.Fatalf("marshalItem: %v", err)
+	}
+	for _, name := range []string{"offer_id", "product_id", "email_sent_amount", "created_at"} {
+		if _, ok := written[name]; !ok {
+			t.Errorf("written item has no %s", name)
+		}
+	}
+	for _, name := range []string{"OfferID", "ProductID", "CreatedAt"} {
+		if _, ok := written[name]; ok {
+			t.Errorf("written item still has %s", name)
+		}
+	}
+}
+
+func TestUnmarshalItemReadsLegacyModels(t *testing.T) {
+	// Items of the catalogue tables as the Go service wrote them before the
+	// `dynamodb` tags were read, every attribute under its Go field name
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		out  interface{}
+		want interface{}
+	}{
+		{
+			name: "offer",
+			item: map[string]types.AttributeValue{
+				"ID":                &types.AttributeValueMemberN{Value: "1"},
+				"UUID":              &types.AttributeValueMemberS{Value: "366b643f-3ad1-4204-9655-cdd079d2498c"},
+				"ProductID":         &types.AttributeValueMemberN{Value: "2"},
+				"CheckoutConfigID":  &types.AttributeValueMemberN{Value: "3"},
+				"Status":            &types.AttributeValueMemberS{Value: repositories.OfferStatusActive},
+				"Price":             &types.AttributeValueMemberN{Value: "4990"},
+				"OrderBumpsEnabled": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			out: &repositories.Offer{},
+			want: &repositories.Offer{
+				ID:                1,
+				UUID:              "366b643f-3ad1-4204-9655-cdd079d2498c",
+				ProductID:         2,
+				CheckoutConfigID:  3,
+				Status:            repositories.OfferStatusActive,
+				Price:             4990,
+				OrderBumpsEnabled: true,
+			},
+		},
+		{
+			name: "product",
+			item: map[string]types.AttributeValue{
+				"ID":               &types.AttributeValueMemberN{Value: "2"},
+				"Name":             &types.AttributeValueMemberS{Value: "Curso de Go"},
+				"UserID":           &types.AttributeValueMemberN{Value: "5"},
+				"CompanyID":        &types.AttributeValueMemberN{Value: "6"},
+				"FormatID":         &types.AttributeValueMemberN{Value: "7"},
+				"EvaluationStatus": &types.AttributeValueMemberS{Value: "APPROVED"},
+				"PhotoURL":         &types.AttributeValueMemberS{Value: "https://example.com/photo.png"},
+			},
+			out: &repositories.Product{},
+			want: &repositories.Product{
+				ID:               2,
+				Name:             "Curso de Go",
+				UserID:           5,
+				CompanyID:        6,
+				FormatID:         7,
+				EvaluationStatus: "APPROVED",
+				PhotoURL:         "https://example.com/photo.png",
+			},
+		},
+		{
+			// The tag of OfferID is not the snake_case of the field name
+			name: "plan",
+			item: map[string]types.AttributeValue{
+				"ID":               &types.AttributeValueMemberN{Value: "7"},
+				"OfferID":          &types.AttributeValueMemberN{Value: "42"},
+				"PromotionalPrice": &types.AttributeValueMemberN{Value: "89900"},
+				"IsDefault":        &types.AttributeValueMemberBOOL{Value: true},
+			},
+			out:  &repositories.Plan{},
+			want: &repositories.Plan{ID: 7, OfferID: 42, PromotionalPrice: 89900, IsDefault: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := unmarshalItem(tt.item, tt.out); err != nil {
+				t.Fatalf("unmarshalItem: %v", err)
+			}
+			if !reflect.DeepEqual(tt.out, tt.want) {
+				t.Errorf("decoded %+v, want %+v", tt.out, tt.want)
+			}
+		})
+	}
+}
+
+// legacyAudit is embedded without a tag, so its fields are attributes of the
+// model embedding it
+type legacyAudit struct {
+	CreatedBy string    `dynamodb:"created_by"`
+	CreatedAt time.Time `dynamodb:"created_at"`
+}
+
+type legacyModel struct {
+	legacyAudit
+	UUID  string `dynamodb:"uuid"`
+	Total int64  `dynamodb:"total"`
+}
+
+func TestUnmarshalItemReadsLegacyFieldNamesOfEmbeddedStructs(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"UUID":      &types.AttributeValueMemberS{Value: "366b643f-3ad1-4204-9655-cdd079d2498c"},
+		"Total":     &types.AttributeValueMemberN{Value: "4990"},
+		"CreatedBy": &types.AttributeValueMemberS{Value: "backoffice"},
+		"CreatedAt": &types.AttributeValueMemberS{Value: "2024-01-15T10:30:00Z"},
+	}
+
+	var model legacyModel
+	if err := unmarshalItem(item, &model); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+	want := legacyModel{
+		legacyAudit: legacyAudit{CreatedBy: "backoffice", CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		UUID:        "366b643f-3ad1-4204-9655-cdd079d2498c",
+		Total:       4990,
+	}
+	if model != want {
+		t.Errorf("model = %+v, want %+v", model, want)
+	}
+}
+
+func TestWithTaggedNamesKeepsCurrentItems(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"uuid":       &types.AttributeValueMemberS{Value: "366b643f-3ad1-4204-9655-cdd079d2498c"},
+		"product_id": &types.AttributeValueMemberN{Value: "2"},
+	}
+	if renamed := withTaggedNames(item, &entities.Checkout{}); reflect.ValueOf(renamed).Pointer() != reflect.ValueOf(item).Pointer() {
+		t.Errorf("an item without legacy names was copied")
+	}
+
+	// Renaming works on a copy, leaving the item read from the table as is
+	legacy := map[string]types.AttributeValue{"ProductID": &types.AttributeValueMemberN{Value: "2"}}
+	renamed := withTaggedNames(legacy, &entities.Checkout{})
+	if _, ok := renamed["product_id"]; !ok {
+		t.Errorf("renamed item = %v, want product_id", renamed)
+	}
+	if _, ok := renamed["ProductID"]; ok {
+		t.Errorf("renamed item still has ProductID")
+	}
+	if _, ok := legacy["ProductID"]; !ok || len(legacy) != 1 {
+		t.Errorf("legacy item was modified: %v", legacy)
+	}
+}
+
+func TestUnmarshalItemPrefersTaggedNames(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"uuid":       &types.AttributeValueMemberS{Value: "366b643f-3ad1-4204-9655-cdd079d2498c"},
+		"product_id": &types.AttributeValueMemberN{Value: "2"},
+		"ProductID":  &types.AttributeValueMemberN{Value: "9"},
+	}
+
+	var checkout entities.Checkout
+	if err := unmarshalItem(item, &checkout); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+	if checkout.ProductID != 2 {
+		t.Errorf("ProductID = %d, want 2", checkout.ProductID)
+	}
+}
+
+func TestTimesSortInTimeOrder(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	times := []time.Time{
+		base,
+		base.Add(100 * time.Millisecond),
+		base.Add(120 * time.Millisecond),
+		base.Add(123456789),
+		base.Add(time.Second),
+	}
+
+	var stored []string
+	for _, at := range times {
+		item, err := marshalItem(struct {
+			CreatedAt time.Time `dynamodb:"created_at"`
+		}{at})
+		if err != nil {
+			t.Fatalf("marshalItem: %v", err)
+		}
+		stored = append(stored, item["created_at"].(*types.AttributeValueMemberS).Value)
+	}
+
+	if !sort.StringsAreSorted(stored) {
+		t.Errorf("stored times do not sort in time order: %v", stored)
+	}
+	for i, value := range stored {
+		if len(value) != len(timeLayout) {
+			t.Errorf("%q is not %d characters long", value, len(timeLayout))
+		}
+		if value != timeAttribute(times[i]).(*types.AttributeValueMemberS).Value {
+			t.Errorf("query bound for %v differs from stored %q", times[i], value)
+		}
+	}
+
+	var decoded struct {
+		CreatedAt time.Time `dynamodb:"created_at"`
+	}
+	item := map[string]types.AttributeValue{"created_at": &types.AttributeValueMemberS{Value: stored[3]}}
+	if err := unmarshalItem(item, &decoded); err != nil {
+		t.Fatalf("unmarshalItem: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(times[3]) {
+		t.Errorf("decoded %v, want %v", decoded.CreatedAt, times[3])
+	}
+}
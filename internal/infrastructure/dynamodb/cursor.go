@@ -0,0 +1,72 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// cursorValue is the JSON form of a key attribute; keys are strings or numbers
+type cursorValue struct {
+	S *string `json:"s,omitempty"`
+	N *string `json:"n,omitempty"`
+}
+
+// encodeCursor turns a LastEvaluatedKey into an opaque URL-safe cursor. An
+// empty key, which marks the last page, yields an empty cursor.
+func encodeCursor(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	values := make(map[string]cursorValue, len(key))
+	for name, attribute := range key {
+		switch v := attribute.(type) {
+		case *types.AttributeValueMemberS:
+			values[name] = cursorValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			values[name] = cursorValue{N: &v.Value}
+		default:
+			return "", fmt.Errorf("unsupported key attribute %s of type %T", name, attribute)
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor turns a cursor produced by encodeCursor back into an
+// ExclusiveStartKey. An empty cursor yields a nil key.
+func decodeCursor(cursor string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]cursorValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	key := make(map[string]types.AttributeValue, len(values))
+	for name, value := range values {
+		switch {
+		case value.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *value.S}
+		case value.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *value.N}
+		default:
+			return nil, fmt.Errorf("empty key attribute %s", name)
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,373 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The tables are shared with the TypeScript service, and the models mix
+// snake_case and camelCase attribute names to match what it writes. The drift
+// detector compares the `dynamodb` tags of a model with real items so a
+// renamed or retyped attribute shows up before it decodes to zero values.
+
+// DriftKind classifies a difference between a model and the stored items
+type DriftKind string
+
+const (
+	// DriftMissing is a model attribute absent from sampled items
+	DriftMissing DriftKind = "missing"
+	// DriftUnknown is an item attribute the model does not declare
+	DriftUnknown DriftKind = "unknown"
+	// DriftMistyped is an attribute stored with a type the model cannot decode
+	DriftMistyped DriftKind = "mistyped"
+	// DriftKey is a table or index key the model does not declare with the
+	// key type
+	DriftKey DriftKind = "key"
+)
+
+// Drift is one difference between a model and a table
+type Drift struct {
+	Kind      DriftKind
+	Attribute string
+	// Expected lists the attribute types the model accepts
+	Expected []string
+	// Actual lists the attribute types found in the items
+	Actual []string
+	// Items is the number of sampled items showing the drift
+	Items int
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftMissing:
+		return fmt.Sprintf("missing   %s (%s) in %d items", d.Attribute, strings.Join(d.Expected, "|"), d.Items)
+	case DriftUnknown:
+		return fmt.Sprintf("unknown   %s (%s) in %d items", d.Attribute, strings.Join(d.Actual, "|"), d.Items)
+	case DriftMistyped:
+		return fmt.Sprintf("mistyped  %s: expected %s, found %s in %d items", d.Attribute, strings.Join(d.Expected, "|"), strings.Join(d.Actual, "|"), d.Items)
+	default:
+		return fmt.Sprintf("key       %s: expected %s, model has %s", d.Attribute, strings.Join(d.Expected, "|"), describeTypes(d.Actual))
+	}
+}
+
+// DriftReport is the result of checking one table
+type DriftReport struct {
+	Table   string
+	Sampled int
+	Drifts  []Drift
+}
+
+// HasDrift reports whether any difference was found
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// DetectDrift scans up to sample items of the table and compares them, and
+// the table keys, with the table model
+func DetectDrift(ctx context.Context, client *Client, table TableSchema, sample int) (*DriftReport, error) {
+	items := make([]map[string]types.AttributeValue, 0, sample)
+	input := &dynamodb.ScanInput{TableName: stringPtr(table.Name)}
+
+	for len(items) < sample {
+		input.Limit = int32Ptr(int32(sample - len(items)))
+
+		result, err := client.GetDynamoDB().Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", table.Name, err)
+		}
+		items = append(items, result.Items...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	report := &DriftReport{Table: table.Name, Sampled: len(items)}
+	report.Drifts = append(KeyDrifts(table), CompareItems(table.Model, items)...)
+	return report, nil
+}
+
+// KeyDrifts checks that the model declares the key attributes of the table
+// and its indexes with the key types
+func KeyDrifts(table TableSchema) []Drift {
+	attributes := modelAttributes(reflect.TypeOf(table.Model))
+
+	keys := []KeyAttribute{table.HashKey}
+	if table.RangeKey != nil {
+		keys = append(keys, *table.RangeKey)
+	}
+	for _, index := range table.Indexes {
+		keys = append(keys, index.HashKey)
+		if index.RangeKey != nil {
+			keys = append(keys, *index.RangeKey)
+		}
+	}
+
+	var drifts []Drift
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.Name] {
+			continue
+		}
+		seen[key.Name] = true
+
+		expected, ok := attributes[key.Name]
+		if ok && expected.accepts(string(key.Type)) {
+			continue
+		}
+		drifts = append(drifts, Drift{
+			Kind:      DriftKey,
+			Attribute: key.Name,
+			Expected:  []string{string(key.Type)},
+			Actual:    expected.types,
+		})
+	}
+	return drifts
+}
+
+// CompareItems compares items with the `dynamodb` tags of model, a struct
+// value or pointer. Contract tests can feed it items captured from a table.
+func CompareItems(model interface{}, items []map[string]types.AttributeValue) []Drift {
+	attributes := modelAttributes(reflect.TypeOf(model))
+
+	missing := make(map[string]int)
+	mistyped := make(map[string]map[string]int)
+	unknown := make(map[string]map[string]int)
+
+	for _, item := range items {
+		for name, expected := range attributes {
+			if _, ok := item[name]; !ok && !expected.optional {
+				missing[name]++
+			}
+		}
+
+		for name, value := range item {
+			actual := attributeType(value)
+
+			expected, ok := attributes[name]
+			if !ok {
+				countType(unknown, name, actual)
+				continue
+			}
+			if !expected.decodes(value) {
+				if actual == "N" {
+					// An integer field holding a fractional number
+					actual = "N (fractional)"
+				}
+				countType(mistyped, name, actual)
+			}
+		}
+	}
+
+	var drifts []Drift
+	for name, count := range missing {
+		drifts = append(drifts, Drift{Kind: DriftMissing, Attribute: name, Expected: attributes[name].types, Items: count})
+	}
+	for name, found := range mistyped {
+		actual, count := summarizeTypes(found)
+		drifts = append(drifts, Drift{Kind: DriftMistyped, Attribute: name, Expected: attributes[name].types, Actual: actual, Items: count})
+	}
+	for name, found := range unknown {
+		actual, count := summarizeTypes(found)
+		drifts = append(drifts, Drift{Kind: DriftUnknown, Attribute: name, Actual: actual, Items: count})
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Attribute != drifts[j].Attribute {
+			return drifts[i].Attribute < drifts[j].Attribute
+		}
+		return drifts[i].Kind < drifts[j].Kind
+	})
+	return drifts
+}
+
+// expectedAttribute describes what a model field can decode
+type expectedAttribute struct {
+	// types lists the accepted attribute types; empty accepts any
+	types []string
+	// integer requires N values to be whole numbers
+	integer bool
+	// optional fields (omitempty, pointers, maps, slices) may be absent or NULL
+	optional bool
+}
+
+func (e expectedAttribute) accepts(attributeType string) bool {
+	if len(e.types) == 0 {
+		return true
+	}
+	for _, t := range e.types {
+		if t == attributeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (e expectedAttribute) decodes(value types.AttributeValue) bool {
+	actual := attributeType(value)
+	if actual == "NULL" {
+		return e.optional
+	}
+	if !e.accepts(actual) {
+		return false
+	}
+	if n, ok := value.(*types.AttributeValueMemberN); ok && e.integer {
+		_, err := strconv.ParseInt(n.Value, 10, 64)
+		return err == nil
+	}
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// modelAttributes maps the attribute names of a model to what they accept,
+// following the field rules of the attributevalue encoder
+func modelAttributes(t reflect.Type) map[string]expectedAttribute {
+	attributes := make(map[string]expectedAttribute)
+	if t == nil {
+		return attributes
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return attributes
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(attributeTag)
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs are flattened into the item
+		if field.Anonymous && name == "" {
+			for embeddedName, expected := range modelAttributes(field.Type) {
+				if _, exists := attributes[embeddedName]; !exists {
+					attributes[embeddedName] = expected
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		expected := expectedFor(field.Type)
+		if strings.Contains(options, "omitempty") {
+			expected.optional = true
+		}
+		attributes[name] = expected
+	}
+	return attributes
+}
+
+// expectedFor returns the attribute types a Go type decodes from
+func expectedFor(t reflect.Type) expectedAttribute {
+	optional := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		optional = true
+	}
+
+	if t == timeType {
+		return expectedAttribute{types: []string{"S", "N"}, optional: optional}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return expectedAttribute{types: []string{"S"}, optional: optional}
+	case reflect.Bool:
+		return expectedAttribute{types: []string{"BOOL"}, optional: optional}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return expectedAttribute{types: []string{"N"}, integer: true, optional: optional}
+	case reflect.Float32, reflect.Float64:
+		return expectedAttribute{types: []string{"N"}, optional: optional}
+	case reflect.Slice, reflect.Array:
+		switch elem := t.Elem().Kind(); {
+		case elem == reflect.Uint8:
+			return expectedAttribute{types: []string{"B"}, optional: true}
+		case elem == reflect.String:
+			return expectedAttribute{types: []string{"L", "SS"}, optional: true}
+		case elem >= reflect.Int && elem <= reflect.Float64:
+			return expectedAttribute{types: []string{"L", "NS"}, optional: true}
+		default:
+			return expectedAttribute{types: []string{"L"}, optional: true}
+		}
+	case reflect.Map:
+		return expectedAttribute{types: []string{"M"}, optional: true}
+	case reflect.Struct:
+		return expectedAttribute{types: []string{"M"}, optional: optional}
+	default:
+		return expectedAttribute{optional: true}
+	}
+}
+
+// attributeType returns the DynamoDB type descriptor of a value
+func attributeType(value types.AttributeValue) string {
+	switch value.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	default:
+		return "?"
+	}
+}
+
+func countType(counts map[string]map[string]int, name, attributeType string) {
+	if counts[name] == nil {
+		counts[name] = make(map[string]int)
+	}
+	counts[name][attributeType]++
+}
+
+// summarizeTypes returns the sorted types found and the number of items
+func summarizeTypes(found map[string]int) ([]string, int) {
+	names := make([]string, 0, len(found))
+	total := 0
+	for name, count := range found {
+		names = append(names, name)
+		total += count
+	}
+	sort.Strings(names)
+	return names, total
+}
+
+func describeTypes(attributeTypes []string) string {
+	if len(attributeTypes) == 0 {
+		return "no such attribute"
+	}
+	return strings.Join(attributeTypes, "|")
+}
@@ -0,0 +1,244 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// driftAudit is embedded untagged, so its attributes are flattened into the
+// item
+type driftAudit struct {
+	CreatedAt time.Time  `dynamodb:"created_at"`
+	DeletedAt *time.Time `dynamodb:"deleted_at,omitempty"`
+}
+
+// driftModel covers the field kinds the drift detector tells apart
+type driftModel struct {
+	ID       int               `dynamodb:"id"`
+	OfferID  int               `dynamodb:"offerId"`
+	Status   string            `dynamodb:"status"`
+	Rate     float64           `dynamodb:"rate"`
+	IsActive bool              `dynamodb:"is_active"`
+	Tag      *string           `dynamodb:"tag"`
+	Note     string            `dynamodb:"note,omitempty"`
+	Codes    []string          `dynamodb:"codes"`
+	Extra    map[string]string `dynamodb:"extra"`
+	Address  struct {
+		City string `dynamodb:"city"`
+	} `dynamodb:"address"`
+	Ignored string `dynamodb:"-"`
+	// Unexported fields are not attributes
+	internal string
+	driftAudit
+}
+
+func numberAttr(value string) types.AttributeValue { return &types.AttributeValueMemberN{Value: value} }
+func stringAttr(value string) types.AttributeValue { return &types.AttributeValueMemberS{Value: value} }
+
+var nullAttr types.AttributeValue = &types.AttributeValueMemberNULL{Value: true}
+
+// validDriftItem returns an item matching driftModel, changed by change
+func validDriftItem(change func(item map[string]types.AttributeValue)) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"id":         numberAttr("1"),
+		"offerId":    numberAttr("42"),
+		"status":     stringAttr("ACTIVE"),
+		"rate":       numberAttr("1.5"),
+		"is_active":  &types.AttributeValueMemberBOOL{Value: true},
+		"tag":        stringAttr("Nenhum"),
+		"note":       stringAttr("nota"),
+		"codes":      &types.AttributeValueMemberSS{Value: []string{"A"}},
+		"extra":      &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		"address":    &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"city": stringAttr("São Paulo")}},
+		"created_at": stringAttr("2024-01-15T12:00:00Z"),
+	}
+	if change != nil {
+		change(item)
+	}
+	return item
+}
+
+func TestCompareItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []map[string]types.AttributeValue
+		want  []Drift
+	}{
+		{
+			name:  "matching items",
+			items: []map[string]types.AttributeValue{validDriftItem(nil), validDriftItem(nil)},
+		},
+		{
+			name: "optional attributes absent or NULL",
+			items: []map[string]types.AttributeValue{
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					delete(item, "tag")
+					delete(item, "note")
+					delete(item, "codes")
+					delete(item, "extra")
+				}),
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					item["tag"] = nullAttr
+					item["note"] = nullAttr
+					item["codes"] = nullAttr
+					item["deleted_at"] = nullAttr
+				}),
+			},
+		},
+		{
+			name: "other accepted types",
+			items: []map[string]types.AttributeValue{validDriftItem(func(item map[string]types.AttributeValue) {
+				item["rate"] = numberAttr("2")
+				item["codes"] = &types.AttributeValueMemberL{Value: []types.AttributeValue{stringAttr("A")}}
+				item["created_at"] = numberAttr("1705320000")
+				item["deleted_at"] = stringAttr("2024-01-16T12:00:00Z")
+			})},
+		},
+		{
+			name: "missing attributes",
+			items: []map[string]types.AttributeValue{
+				validDriftItem(func(item map[string]types.AttributeValue) { delete(item, "status") }),
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					delete(item, "status")
+					delete(item, "created_at")
+				}),
+				validDriftItem(nil),
+			},
+			want: []Drift{
+				{Kind: DriftMissing, Attribute: "created_at", Expected: []string{"S", "N"}, Items: 1},
+				{Kind: DriftMissing, Attribute: "status", Expected: []string{"S"}, Items: 2},
+			},
+		},
+		{
+			name: "unknown attributes",
+			items: []map[string]types.AttributeValue{
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					item["offer_id"] = numberAttr("42")
+					item["Ignored"] = stringAttr("x")
+				}),
+				validDriftItem(func(item map[string]types.AttributeValue) { item["offer_id"] = stringAttr("42") }),
+			},
+			want: []Drift{
+				{Kind: DriftUnknown, Attribute: "Ignored", Actual: []string{"S"}, Items: 1},
+				{Kind: DriftUnknown, Attribute: "offer_id", Actual: []string{"N", "S"}, Items: 2},
+			},
+		},
+		{
+			name: "mistyped attributes",
+			items: []map[string]types.AttributeValue{
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					item["offerId"] = stringAttr("42")
+					item["is_active"] = stringAttr("true")
+					item["address"] = stringAttr("São Paulo")
+				}),
+				validDriftItem(func(item map[string]types.AttributeValue) {
+					item["offerId"] = &types.AttributeValueMemberNS{Value: []string{"42"}}
+				}),
+			},
+			want: []Drift{
+				{Kind: DriftMistyped, Attribute: "address", Expected: []string{"M"}, Actual: []string{"S"}, Items: 1},
+				{Kind: DriftMistyped, Attribute: "is_active", Expected: []string{"BOOL"}, Actual: []string{"S"}, Items: 1},
+				{Kind: DriftMistyped, Attribute: "offerId", Expected: []string{"N"}, Actual: []string{"NS", "S"}, Items: 2},
+			},
+		},
+		{
+			name: "fractional number in an integer field",
+			items: []map[string]types.AttributeValue{validDriftItem(func(item map[string]types.AttributeValue) {
+				item["id"] = numberAttr("1.5")
+				item["rate"] = numberAttr("1.25")
+			})},
+			want: []Drift{
+				{Kind: DriftMistyped, Attribute: "id", Expected: []string{"N"}, Actual: []string{"N (fractional)"}, Items: 1},
+			},
+		},
+		{
+			name: "NULL in required attributes",
+			items: []map[string]types.AttributeValue{validDriftItem(func(item map[string]types.AttributeValue) {
+				item["status"] = nullAttr
+				item["created_at"] = nullAttr
+			})},
+			want: []Drift{
+				{Kind: DriftMistyped, Attribute: "created_at", Expected: []string{"S", "N"}, Actual: []string{"NULL"}, Items: 1},
+				{Kind: DriftMistyped, Attribute: "status", Expected: []string{"S"}, Actual: []string{"NULL"}, Items: 1},
+			},
+		},
+		{
+			name: "no items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareItems(&driftModel{}, tt.items)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareItems =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyDrifts(t *testing.T) {
+	tests := []struct {
+		name  string
+		table TableSchema
+		want  []Drift
+	}{
+		{
+			name: "declared keys",
+			table: TableSchema{
+				HashKey:  KeyAttribute{Name: "id", Type: types.ScalarAttributeTypeN},
+				RangeKey: &KeyAttribute{Name: "created_at", Type: types.ScalarAttributeTypeS},
+				Indexes: []IndexSchema{
+					{HashKey: KeyAttribute{Name: "offerId", Type: types.ScalarAttributeTypeN}, RangeKey: &KeyAttribute{Name: "created_at", Type: types.ScalarAttributeTypeS}},
+					{HashKey: KeyAttribute{Name: "status", Type: types.ScalarAttributeTypeS}},
+				},
+			},
+		},
+		{
+			name: "undeclared and mistyped keys",
+			table: TableSchema{
+				HashKey: KeyAttribute{Name: "uuid", Type: types.ScalarAttributeTypeS},
+				Indexes: []IndexSchema{
+					{HashKey: KeyAttribute{Name: "status", Type: types.ScalarAttributeTypeN}},
+					{HashKey: KeyAttribute{Name: "Ignored", Type: types.ScalarAttributeTypeS}},
+					{HashKey: KeyAttribute{Name: "uuid", Type: types.ScalarAttributeTypeS}},
+				},
+			},
+			want: []Drift{
+				{Kind: DriftKey, Attribute: "uuid", Expected: []string{"S"}},
+				{Kind: DriftKey, Attribute: "status", Expected: []string{"N"}, Actual: []string{"S"}},
+				{Kind: DriftKey, Attribute: "Ignored", Expected: []string{"S"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Model = driftModel{}
+			got := KeyDrifts(tt.table)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyDrifts =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelsMatchTheirKeys(t *testing.T) {
+	for _, table := range Tables {
+		if table.Model == nil {
+			continue
+		}
+		if drifts := KeyDrifts(table); len(drifts) > 0 {
+			t.Errorf("%s: %v", table.Name, drifts)
+		}
+	}
+}
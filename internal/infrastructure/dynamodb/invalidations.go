@@ -0,0 +1,134 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/config"
+	"checkout-go/internal/infrastructure/aws"
+	"checkout-go/internal/infrastructure/invalidation"
+)
+
+// invalidationChannel is the partition shared by every catalog invalidation
+const invalidationChannel = "catalog"
+
+// maxBatchWriteItems is the DynamoDB limit of items per BatchWriteItem call
+const maxBatchWriteItems = 25
+
+// maxBatchWriteAttempts bounds the retries of unprocessed items, which
+// DynamoDB returns when the table is throttled
+const maxBatchWriteAttempts = 8
+
+// invalidationItem is the stored form of an invalidation event
+type invalidationItem struct {
+	Channel string `dynamodb:"channel"`
+	invalidation.Event
+	ExpiresAt int64 `dynamodb:"expires_at"`
+}
+
+// InvalidationsRepository stores cache invalidation events in a table keyed by
+// channel and a time-ordered sequence, so every service instance can poll it
+type InvalidationsRepository struct {
+	*BaseRepository
+	tableName string
+	retention time.Duration
+	counter   atomic.Uint64
+}
+
+func NewInvalidationsRepository(client *Client, cfg *config.Config) *InvalidationsRepository {
+	return &InvalidationsRepository{
+		BaseRepository: NewBaseRepository(client),
+		tableName:      aws.GetTableName(cfg, cfg.InvalidationTable),
+		retention:      cfg.InvalidationRetention,
+	}
+}
+
+// Publish writes events to the invalidations table
+func (r *InvalidationsRepository) Publish(ctx context.Context, events []invalidation.Event) error {
+	now := time.Now()
+	requests := make([]types.WriteRequest, 0, len(events))
+	for _, event := range events {
+		event.Sequence = r.nextSequence(now)
+
+		item, err := marshalItem(invalidationItem{
+			Channel:   invalidationChannel,
+			Event:     event,
+			ExpiresAt: now.Add(r.retention).Unix(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal invalidation event: %w", err)
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := min(start+maxBatchWriteItems, len(requests))
+		pending := map[string][]types.WriteRequest{r.tableName: requests[start:end]}
+
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == maxBatchWriteAttempts {
+				return fmt.Errorf("failed to write invalidation events: items still unprocessed after %d attempts", attempt)
+			}
+			if attempt > 0 {
+				if err := sleepWithContext(ctx, backoff(attempt)); err != nil {
+					return err
+				}
+			}
+
+			result, err := r.client.GetDynamoDB().BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return fmt.Errorf("failed to write invalidation events: %w", err)
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// FindSince returns up to limit events with a sequence after the given one,
+// oldest first
+func (r *InvalidationsRepository) FindSince(ctx context.Context, after string, limit int) ([]invalidation.Event, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &r.tableName,
+		KeyConditionExpression: stringPtr("#channel = :channel AND #sequence > :after"),
+		ExpressionAttributeNames: map[string]string{
+			"#channel":  "channel",
+			"#sequence": "sequence",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":channel": &types.AttributeValueMemberS{Value: invalidationChannel},
+			":after":   &types.AttributeValueMemberS{Value: after},
+		},
+		Limit: int32Ptr(int32(limit)),
+	}
+
+	result, err := r.client.GetDynamoDB().Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invalidation events: %w", err)
+	}
+
+	events := make([]invalidation.Event, 0, len(result.Items))
+	for _, item := range result.Items {
+		var stored invalidationItem
+		if err := unmarshalItem(item, &stored); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal invalidation event: %w", err)
+		}
+		events = append(events, stored.Event)
+	}
+
+	return events, nil
+}
+
+// nextSequence builds a sequence that sorts by publish time and stays unique
+// within this process. Publish times come from this host's clock; the poller
+// re-reads an overlap window to catch events stored behind newer ones.
+func (r *InvalidationsRepository) nextSequence(now time.Time) string {
+	return invalidation.SequenceAt(now) + "-" + strconv.FormatUint(r.counter.Add(1), 36)
+}
@@ -0,0 +1,76 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/config"
+	"checkout-go/internal/infrastructure/aws"
+)
+
+// jobCursor is an item of the job cursors table
+type jobCursor struct {
+	Job       string    `dynamodb:"job"`
+	Cursor    string    `dynamodb:"cursor"`
+	UpdatedAt time.Time `dynamodb:"updated_at"`
+}
+
+// JobCursorsRepository keeps one item per job in the job cursors table
+type JobCursorsRepository struct {
+	client    *Client
+	tableName string
+}
+
+func NewJobCursorsRepository(client *Client, cfg *config.Config) *JobCursorsRepository {
+	return &JobCursorsRepository{
+		client:    client,
+		tableName: aws.GetTableName(cfg, TableJobCursors),
+	}
+}
+
+func (r *JobCursorsRepository) Load(ctx context.Context, job string) (string, error) {
+	result, err := r.client.GetDynamoDB().GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &r.tableName,
+		Key:            map[string]types.AttributeValue{"job": &types.AttributeValueMemberS{Value: job}},
+		ConsistentRead: boolPtr(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get cursor of job %s: %w", job, err)
+	}
+	if result.Item == nil {
+		return "", nil
+	}
+
+	var cursor jobCursor
+	if err := unmarshalItem(result.Item, &cursor); err != nil {
+		return "", fmt.Errorf("failed to unmarshal cursor of job %s: %w", job, err)
+	}
+	return cursor.Cursor, nil
+}
+
+func (r *JobCursorsRepository) Save(ctx context.Context, job, cursor string) error {
+	if cursor == "" {
+		_, err := r.client.GetDynamoDB().DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: &r.tableName,
+			Key:       map[string]types.AttributeValue{"job": &types.AttributeValueMemberS{Value: job}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear cursor of job %s: %w", job, err)
+		}
+		return nil
+	}
+
+	item, err := marshalItem(jobCursor{Job: job, Cursor: cursor, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cursor of job %s: %w", job, err)
+	}
+	_, err = r.client.GetDynamoDB().PutItem(ctx, &dynamodb.PutItemInput{TableName: &r.tableName, Item: item})
+	if err != nil {
+		return fmt.Errorf("failed to save cursor of job %s: %w", job, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/config"
+	coreErrors "checkout-go/internal/core/errors"
+	"checkout-go/internal/infrastructure/aws"
+)
+
+// offerCountShard is an item of the counter shards table
+type offerCountShard struct {
+	OfferID       int   `dynamodb:"offer_id"`
+	Shard         int   `dynamodb:"shard"`
+	CheckoutCount int64 `dynamodb:"checkout_count"`
+}
+
+// OfferCountersRepository adds to the checkout_count of the offers table and,
+// for shards above 0, of the counter shards table
+type OfferCountersRepository struct {
+	client      *Client
+	offersTable string
+	shardsTable string
+}
+
+func NewOfferCountersRepository(client *Client, cfg *config.Config) *OfferCountersRepository {
+	return &OfferCountersRepository{
+		client:      client,
+		offersTable: aws.GetTableName(cfg, TableOffers),
+		shardsTable: aws.GetTableName(cfg, TableOfferCheckoutCountShards),
+	}
+}
+
+func (r *OfferCountersRepository) AddCheckoutCount(ctx context.Context, offerID, shard int, delta int64) error {
+	input := &dynamodb.UpdateItemInput{
+		UpdateExpression: stringPtr("ADD checkout_count :delta"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", delta)},
+		},
+	}
+
+	if shard == 0 {
+		// The condition keeps ADD from creating a stub offer item
+		input.TableName = &r.offersTable
+		input.Key = map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)},
+		}
+		input.ConditionExpression = stringPtr("attribute_exists(id)")
+	} else {
+		input.TableName = &r.shardsTable
+		input.Key = map[string]types.AttributeValue{
+			"offer_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)},
+			"shard":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", shard)},
+		}
+	}
+
+	_, err := r.client.GetDynamoDB().UpdateItem(ctx, input)
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return coreErrors.NewEntityNotFoundError("offer", fmt.Sprintf("offer %d not found", offerID))
+		}
+		return fmt.Errorf("failed to add to checkout count of offer %d: %w", offerID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Iterator streams the items of a query page by page, following
+// LastEvaluatedKey until the results or the limit are exhausted:
+//
+//	it := repo.IterAllByOffer(offerID, 50)
+//	for it.Next(ctx) {
+//		use(it.Value())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	paginator *dynamodb.QueryPaginator
+	filter    func(*T) bool
+	limit     int
+	emitted   int
+	page      []map[string]types.AttributeValue
+	current   *T
+	err       error
+}
+
+// newQueryIterator creates an iterator over input. Items rejected by filter
+// are skipped and do not count towards limit; a limit of 0 means no limit.
+func newQueryIterator[T any](client *Client, input *dynamodb.QueryInput, limit int, filter func(*T) bool) *Iterator[T] {
+	// Without a filter every item read is returned, so pages larger than the
+	// limit would only be discarded
+	if limit > 0 && filter == nil {
+		input.Limit = int32Ptr(int32(min(limit, 1000)))
+	}
+
+	return &Iterator[T]{
+		paginator: dynamodb.NewQueryPaginator(client.GetDynamoDB(), input),
+		filter:    filter,
+		limit:     limit,
+	}
+}
+
+// Next advances to the next item, fetching the next page when needed. It
+// returns false when the results are exhausted, the limit is reached or an
+// error occurred.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || (it.limit > 0 && it.emitted >= it.limit) {
+		return false
+	}
+
+	for {
+		for len(it.page) > 0 {
+			item := it.page[0]
+			it.page = it.page[1:]
+
+			value := new(T)
+			if err := unmarshalItem(item, value); err != nil {
+				it.err = fmt.Errorf("failed to unmarshal item: %w", err)
+				return false
+			}
+			if it.filter != nil && !it.filter(value) {
+				continue
+			}
+
+			it.current = value
+			it.emitted++
+			return true
+		}
+
+		if !it.paginator.HasMorePages() {
+			return false
+		}
+
+		output, err := it.paginator.NextPage(ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = output.Items
+	}
+}
+
+// Value returns the current item
+func (it *Iterator[T]) Value() *T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// queryAll returns every item matching input across all pages
+func queryAll[T any](ctx context.Context, client *Client, input *dynamodb.QueryInput, filter func(*T) bool) ([]*T, error) {
+	var items []*T
+	it := newQueryIterator(client, input, 0, filter)
+	for it.Next(ctx) {
+		items = append(items, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
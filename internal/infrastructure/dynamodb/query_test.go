@@ -0,0 +1,340 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"checkout-go/internal/config"
+)
+
+// attributes is an item in the DynamoDB JSON wire format
+type attributes map[string]map[string]string
+
+// queryRequest is the part of a Query request the fake looks at
+type queryRequest struct {
+	TableName                 string
+	IndexName                 string
+	Limit                     *int
+	ExclusiveStartKey         attributes
+	ExpressionAttributeValues attributes
+}
+
+// fakeQueries serves Query requests from items, pageSize items per page
+// unless the request has a lower Limit. The request numbered failAt, from
+// 1, fails.
+type fakeQueries struct {
+	items    []attributes
+	pageSize int
+	failAt   int
+
+	mu       sync.Mutex
+	requests []queryRequest
+}
+
+func (f *fakeQueries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	number := len(f.requests)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if number == f.failAt {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`)
+		return
+	}
+
+	start := 0
+	if key, ok := req.ExclusiveStartKey["position"]; ok {
+		start, _ = strconv.Atoi(key["N"])
+	}
+	size := f.pageSize
+	if req.Limit != nil && *req.Limit < size {
+		size = *req.Limit
+	}
+	end := min(start+size, len(f.items))
+
+	response := map[string]interface{}{"Items": f.items[start:end], "Count": end - start}
+	if end < len(f.items) {
+		response["LastEvaluatedKey"] = attributes{"position": {"N": strconv.Itoa(end)}}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// newFakeClient returns a client whose requests are served by queries
+func newFakeClient(t *testing.T, queries *fakeQueries) *Client {
+	t.Helper()
+	server := httptest.NewServer(queries)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}, WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func reviewItem(id int, status string) attributes {
+	return attributes{
+		"id":               {"N": strconv.Itoa(id)},
+		"checkoutConfigId": {"N": "7"},
+		"name":             {"S": fmt.Sprintf("Review %d", id)},
+		"status":           {"S": status},
+	}
+}
+
+func planItem(id int) attributes {
+	return attributes{
+		"id":      {"N": strconv.Itoa(id)},
+		"offerId": {"N": "1"},
+		"title":   {"S": fmt.Sprintf("Plan %d", id)},
+	}
+}
+
+func TestIteratorFollowsEveryPage(t *testing.T) {
+	queries := &fakeQueries{pageSize: 2}
+	for id := 1; id <= 5; id++ {
+		queries.items = append(queries.items, reviewItem(id, "ACTIVE"))
+	}
+	repo := NewReviewsRepository(newFakeClient(t, queries), &config.Config{})
+
+	var ids []int
+	it := repo.IterByCheckoutConfig(7, 0)
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("ids = %v, want [1 2 3 4 5]", ids)
+	}
+
+	if len(queries.requests) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(queries.requests))
+	}
+	first := queries.requests[0]
+	if first.TableName != TableReviews || first.IndexName != IndexReviewsByCheckoutConfig || first.ExpressionAttributeValues[":checkoutConfigId"]["N"] != "7" {
+		t.Errorf("first request = %+v", first)
+	}
+	if first.ExclusiveStartKey != nil || queries.requests[1].ExclusiveStartKey["position"]["N"] != "2" {
+		t.Errorf("start keys = %v, %v", first.ExclusiveStartKey, queries.requests[1].ExclusiveStartKey)
+	}
+}
+
+func TestIteratorLimitWithoutFilter(t *testing.T) {
+	queries := &fakeQueries{items: []attributes{planItem(1), planItem(2), planItem(3), planItem(4), planItem(5)}, pageSize: 10}
+	repo := NewPlansRepository(newFakeClient(t, queries), &config.Config{})
+
+	var ids []int
+	it := repo.IterByOffer(1, 3)
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("ids = %v, want [1 2 3]", ids)
+	}
+	if it.Next(context.Background()) {
+		t.Error("Next after the limit returned true")
+	}
+
+	// Every item read is returned, so the page is capped at the limit
+	if len(queries.requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(queries.requests))
+	}
+	if limit := queries.requests[0].Limit; limit == nil || *limit != 3 {
+		t.Errorf("page Limit = %v, want 3", limit)
+	}
+}
+
+func TestIteratorLimitWithFilter(t *testing.T) {
+	queries := &fakeQueries{pageSize: 2, items: []attributes{
+		reviewItem(1, "INACTIVE"), reviewItem(2, "ACTIVE"),
+		reviewItem(3, "INACTIVE"), reviewItem(4, "ACTIVE"),
+		reviewItem(5, "INACTIVE"), reviewItem(6, "ACTIVE"),
+		reviewItem(7, "ACTIVE"),
+	}}
+	repo := NewReviewsRepository(newFakeClient(t, queries), &config.Config{})
+
+	var ids []int
+	it := repo.IterByCheckoutConfig(7, 3)
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	// Skipped reviews do not count towards the limit
+	if fmt.Sprint(ids) != "[2 4 6]" {
+		t.Errorf("ids = %v, want [2 4 6]", ids)
+	}
+
+	// A page Limit would count the skipped items, so none is sent, and the
+	// last page is not read
+	if len(queries.requests) != 3 {
+		t.Errorf("sent %d requests, want 3", len(queries.requests))
+	}
+	for _, req := range queries.requests {
+		if req.Limit != nil {
+			t.Errorf("page Limit = %d, want none", *req.Limit)
+		}
+	}
+}
+
+func TestIteratorStopsOnErrors(t *testing.T) {
+	t.Run("query", func(t *testing.T) {
+		queries := &fakeQueries{items: []attributes{planItem(1), planItem(2), planItem(3)}, pageSize: 2, failAt: 2}
+		repo := NewPlansRepository(newFakeClient(t, queries), &config.Config{})
+
+		var ids []int
+		it := repo.IterByOffer(1, 0)
+		for it.Next(context.Background()) {
+			ids = append(ids, it.Value().ID)
+		}
+		if it.Err() == nil {
+			t.Fatal("Err = nil, want the query error")
+		}
+		if fmt.Sprint(ids) != "[1 2]" {
+			t.Errorf("ids = %v, want the first page", ids)
+		}
+		if it.Next(context.Background()) || len(queries.requests) != 2 {
+			t.Errorf("iteration went on after the error: %d requests", len(queries.requests))
+		}
+
+		// queryAll returns no partial result when a later page fails
+		queries.failAt = len(queries.requests) + 2
+		if plans, err := repo.FindByOffer(context.Background(), 1); err == nil || plans != nil {
+			t.Errorf("FindByOffer = %d plans, %v, want the query error", len(plans), err)
+		}
+	})
+
+	t.Run("decoding", func(t *testing.T) {
+		bad := planItem(2)
+		bad["id"] = map[string]string{"S": "two"}
+		queries := &fakeQueries{items: []attributes{planItem(1), bad, planItem(3)}, pageSize: 10}
+		repo := NewPlansRepository(newFakeClient(t, queries), &config.Config{})
+
+		var ids []int
+		it := repo.IterByOffer(1, 0)
+		for it.Next(context.Background()) {
+			ids = append(ids, it.Value().ID)
+		}
+		if it.Err() == nil || fmt.Sprint(ids) != "[1]" {
+			t.Errorf("ids = %v, Err = %v, want to stop at the bad item", ids, it.Err())
+		}
+	})
+}
+
+func TestQueryAllFiltersEveryPage(t *testing.T) {
+	queries := &fakeQueries{pageSize: 2}
+	for id := 1; id <= 7; id++ {
+		status := "ACTIVE"
+		if id%3 == 0 {
+			status = "INACTIVE"
+		}
+		queries.items = append(queries.items, reviewItem(id, status))
+	}
+	repo := NewReviewsRepository(newFakeClient(t, queries), &config.Config{})
+
+	reviews, err := repo.FindByCheckoutConfig(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("FindByCheckoutConfig: %v", err)
+	}
+	ids := make([]int, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 4 5 7]" {
+		t.Errorf("ids = %v, want [1 2 4 5 7]", ids)
+	}
+}
+
+func TestIterMethodsQueryTheirIndex(t *testing.T) {
+	cfg := &config.Config{}
+	tests := []struct {
+		name      string
+		iterate   func(client *Client) bool
+		wantTable string
+		wantIndex string
+		wantKeys  map[string]string
+	}{
+		{
+			name: "order bumps by offer",
+			iterate: func(client *Client) bool {
+				return NewOrderBumpsRepository(client, cfg).IterAllByOffer(1, 5).Next(context.Background())
+			},
+			wantTable: TableOrderBumps,
+			wantIndex: IndexOfferID,
+			wantKeys:  map[string]string{":offer_id": "1"},
+		},
+		{
+			name: "reviews by checkout config",
+			iterate: func(client *Client) bool {
+				return NewReviewsRepository(client, cfg).IterByCheckoutConfig(7, 5).Next(context.Background())
+			},
+			wantTable: TableReviews,
+			wantIndex: IndexReviewsByCheckoutConfig,
+			wantKeys:  map[string]string{":checkoutConfigId": "7"},
+		},
+		{
+			name: "pixels by user and product",
+			iterate: func(client *Client) bool {
+				return NewPixelsRepository(client, cfg).IterAllByUserAndProduct(2, 3, 5).Next(context.Background())
+			},
+			wantTable: TablePixels,
+			wantIndex: IndexPixelsByProductAndUser,
+			wantKeys:  map[string]string{":userId": "2", ":productId": "3"},
+		},
+		{
+			name: "plans by offer",
+			iterate: func(client *Client) bool {
+				return NewPlansRepository(client, cfg).IterByOffer(1, 5).Next(context.Background())
+			},
+			wantTable: TablePlans,
+			wantIndex: IndexPlansByOffer,
+			wantKeys:  map[string]string{":offerId": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := &fakeQueries{pageSize: 2}
+			if tt.iterate(newFakeClient(t, queries)) {
+				t.Error("Next over an empty index returned true")
+			}
+			if len(queries.requests) != 1 {
+				t.Fatalf("sent %d requests, want 1", len(queries.requests))
+			}
+			req := queries.requests[0]
+			if req.TableName != tt.wantTable || req.IndexName != tt.wantIndex {
+				t.Errorf("queried %s/%s, want %s/%s", req.TableName, req.IndexName, tt.wantTable, tt.wantIndex)
+			}
+			for name, value := range tt.wantKeys {
+				if got := req.ExpressionAttributeValues[name]["N"]; got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
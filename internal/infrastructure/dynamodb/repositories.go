@@ -2,14 +2,16 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"checkout-go/internal/config"
 	"checkout-go/internal/core/entities"
+	coreErrors "checkout-go/internal/core/errors"
 	"checkout-go/internal/infrastructure/aws"
 	"checkout-go/internal/repositories"
 )
@@ -34,7 +36,7 @@ type OffersRepository struct {
 func NewOffersRepository(client *Client, cfg *config.Config) *OffersRepository {
 	return &OffersRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "offers"),
+		tableName:      aws.GetTableName(cfg, TableOffers),
 	}
 }
 
@@ -42,7 +44,7 @@ func (r *OffersRepository) FindByUUID(ctx context.Context, uuid string) (*reposi
 	// Query the UuidIndex GSI instead of using GetItem on primary key
 	input := &dynamodb.QueryInput{
 		TableName: &r.tableName,
-		IndexName: stringPtr("UuidIndex"),
+		IndexName: stringPtr(IndexUUID),
 		KeyConditionExpression: stringPtr("#uuid = :uuid"),
 		ExpressionAttributeNames: map[string]string{
 			"#uuid": "uuid",
@@ -63,7 +65,7 @@ func (r *OffersRepository) FindByUUID(ctx context.Context, uuid string) (*reposi
 	}
 
 	var offer repositories.Offer
-	if err := attributevalue.UnmarshalMap(result.Items[0], &offer); err != nil {
+	if err := unmarshalItem(result.Items[0], &offer); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal offer: %w", err)
 	}
 
@@ -88,13 +90,17 @@ func (r *OffersRepository) Find(ctx context.Context, id int) (*repositories.Offe
 	}
 
 	var offer repositories.Offer
-	if err := attributevalue.UnmarshalMap(result.Item, &offer); err != nil {
+	if err := unmarshalItem(result.Item, &offer); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal offer: %w", err)
 	}
 
 	return &offer, nil
 }
 
+func (r *OffersRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Offer, error) {
+	return batchGetByID(ctx, r.client, r.tableName, ids, func(o *repositories.Offer) int { return o.ID })
+}
+
 func (r *OffersRepository) IncrementCheckoutCount(ctx context.Context, uuid string) error {
 	// First, find the offer by UUID to get its id (primary key)
 	offer, err := r.FindByUUID(ctx, uuid)
@@ -134,30 +140,16 @@ type CheckoutsRepository struct {
 func NewCheckoutsRepository(client *Client, cfg *config.Config) *CheckoutsRepository {
 	return &CheckoutsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "checkouts"),
+		tableName:      aws.GetTableName(cfg, TableCheckouts),
 	}
 }
 
 func (r *CheckoutsRepository) Create(ctx context.Context, checkout *entities.Checkout) error {
-	item, err := attributevalue.MarshalMap(checkout)
+	item, err := marshalCheckout(checkout)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkout: %w", err)
 	}
 
-	// Fix case sensitivity issue: rename UUID to uuid if it exists
-	if uuidAttr, exists := item["UUID"]; exists {
-		item["uuid"] = uuidAttr
-		delete(item, "UUID")
-		fmt.Printf("DEBUG: Fixed UUID case - moved from 'UUID' to 'uuid'\n")
-	}
-
-	// Debug: Check if uuid key exists in marshaled item
-	if uuidAttr, exists := item["uuid"]; exists {
-		fmt.Printf("DEBUG: Marshaled item has uuid key with value: %v\n", uuidAttr)
-	} else {
-		fmt.Printf("DEBUG: ERROR - Marshaled item is still missing uuid key! Available keys: %v\n", getMapKeys(item))
-	}
-
 	input := &dynamodb.PutItemInput{
 		TableName: &r.tableName,
 		Item:      item,
@@ -171,15 +163,6 @@ func (r *CheckoutsRepository) Create(ctx context.Context, checkout *entities.Che
 	return nil
 }
 
-// Helper function to get map keys for debugging
-func getMapKeys(m map[string]types.AttributeValue) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
 func (r *CheckoutsRepository) FindByUUID(ctx context.Context, uuid string) (*entities.Checkout, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: &r.tableName,
@@ -198,31 +181,56 @@ func (r *CheckoutsRepository) FindByUUID(ctx context.Context, uuid string) (*ent
 	}
 
 	var checkout entities.Checkout
-	if err := attributevalue.UnmarshalMap(result.Item, &checkout); err != nil {
+	if err := unmarshalItem(result.Item, &checkout); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal checkout: %w", err)
 	}
 
 	return &checkout, nil
 }
 
+// Update writes the checkout only if the stored version still matches the one
+// it was read with, returning a ConcurrentModificationError otherwise. On
+// success the checkout's version is incremented.
 func (r *CheckoutsRepository) Update(ctx context.Context, checkout *entities.Checkout) error {
-	checkout.UpdateTimestamp()
+	expectedVersion := checkout.Version
+	updated := *checkout
+	updated.UpdateTimestamp()
+	updated.Version++
 
-	item, err := attributevalue.MarshalMap(checkout)
+	item, err := marshalCheckout(&updated)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkout: %w", err)
 	}
 
+	// Checkouts written before versioning have no version attribute
+	condition := "attribute_exists(#uuid) AND #version = :expected"
+	if expectedVersion == 0 {
+		condition = "attribute_exists(#uuid) AND (attribute_not_exists(#version) OR #version = :expected)"
+	}
+
 	input := &dynamodb.PutItemInput{
-		TableName: &r.tableName,
-		Item:      item,
+		TableName:           &r.tableName,
+		Item:                item,
+		ConditionExpression: stringPtr(condition),
+		ExpressionAttributeNames: map[string]string{
+			"#uuid":    "uuid",
+			"#version": "version",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":expected": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expectedVersion)},
+		},
 	}
 
 	_, err = r.client.GetDynamoDB().PutItem(ctx, input)
 	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return coreErrors.NewConcurrentModificationError("checkout", expectedVersion)
+		}
 		return fmt.Errorf("failed to update checkout: %w", err)
 	}
 
+	*checkout = updated
 	return nil
 }
 
@@ -232,84 +240,84 @@ func (r *CheckoutsRepository) Update(ctx context.Context, checkout *entities.Che
 func NewProductsRepository(client *Client, cfg *config.Config) repositories.ProductsRepository {
 	return &ProductsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "products"),
+		tableName:      aws.GetTableName(cfg, TableProducts),
 	}
 }
 
 func NewUsersRepository(client *Client, cfg *config.Config) repositories.UsersRepository {
 	return &UsersRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "users"),
+		tableName:      aws.GetTableName(cfg, TableUsers),
 	}
 }
 
 func NewCompaniesRepository(client *Client, cfg *config.Config) repositories.CompaniesRepository {
 	return &CompaniesRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "companies"),
+		tableName:      aws.GetTableName(cfg, TableCompanies),
 	}
 }
 
 func NewFormatsRepository(client *Client, cfg *config.Config) repositories.FormatsRepository {
 	return &FormatsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "formats"),
+		tableName:      aws.GetTableName(cfg, TableFormats),
 	}
 }
 
 func NewCheckoutConfigsRepository(client *Client, cfg *config.Config) repositories.CheckoutConfigsRepository {
 	return &CheckoutConfigsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "checkout_configs"),
+		tableName:      aws.GetTableName(cfg, TableCheckoutConfigs),
 	}
 }
 
 func NewAffiliatesRepository(client *Client, cfg *config.Config) repositories.AffiliatesRepository {
 	return &AffiliatesRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "affiliates"),
+		tableName:      aws.GetTableName(cfg, TableAffiliates),
 	}
 }
 
 func NewProductAffiliateSettingsRepository(client *Client, cfg *config.Config) repositories.ProductAffiliateSettingsRepository {
 	return &ProductAffiliateSettingsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "product_affiliate_settings"),
+		tableName:      aws.GetTableName(cfg, TableProductAffiliateSettings),
 	}
 }
 
-func NewOrderBumpsRepository(client *Client, cfg *config.Config) repositories.OrderBumpsRepository {
+func NewOrderBumpsRepository(client *Client, cfg *config.Config) *OrderBumpsRepository {
 	return &OrderBumpsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "order_bumps"),
+		tableName:      aws.GetTableName(cfg, TableOrderBumps),
 	}
 }
 
-func NewReviewsRepository(client *Client, cfg *config.Config) repositories.ReviewsRepository {
+func NewReviewsRepository(client *Client, cfg *config.Config) *ReviewsRepository {
 	return &ReviewsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "reviews"),
+		tableName:      aws.GetTableName(cfg, TableReviews),
 	}
 }
 
-func NewPixelsRepository(client *Client, cfg *config.Config) repositories.PixelsRepository {
+func NewPixelsRepository(client *Client, cfg *config.Config) *PixelsRepository {
 	return &PixelsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "pixels"),
+		tableName:      aws.GetTableName(cfg, TablePixels),
 	}
 }
 
-func NewPlansRepository(client *Client, cfg *config.Config) repositories.PlansRepository {
+func NewPlansRepository(client *Client, cfg *config.Config) *PlansRepository {
 	return &PlansRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "plans"),
+		tableName:      aws.GetTableName(cfg, TablePlans),
 	}
 }
 
 func NewDiscountsRepository(client *Client, cfg *config.Config) repositories.DiscountsRepository {
 	return &DiscountsRepository{
 		BaseRepository: NewBaseRepository(client),
-		tableName:      aws.GetTableName(cfg, "discounts"),
+		tableName:      aws.GetTableName(cfg, TableDiscounts),
 	}
 }
 
@@ -337,13 +345,17 @@ func (r *ProductsRepository) Find(ctx context.Context, id int) (*repositories.Pr
 	}
 
 	var product repositories.Product
-	if err := attributevalue.UnmarshalMap(result.Item, &product); err != nil {
+	if err := unmarshalItem(result.Item, &product); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
 	}
 
 	return &product, nil
 }
 
+func (r *ProductsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Product, error) {
+	return batchGetByID(ctx, r.client, r.tableName, ids, func(p *repositories.Product) int { return p.ID })
+}
+
 type UsersRepository struct {
 	*BaseRepository
 	tableName string
@@ -367,7 +379,7 @@ func (r *UsersRepository) Find(ctx context.Context, id int) (*repositories.User,
 	}
 
 	var user repositories.User
-	if err := attributevalue.UnmarshalMap(result.Item, &user); err != nil {
+	if err := unmarshalItem(result.Item, &user); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
 	}
 
@@ -397,7 +409,7 @@ func (r *CompaniesRepository) Find(ctx context.Context, id int) (*repositories.C
 	}
 
 	var company repositories.Company
-	if err := attributevalue.UnmarshalMap(result.Item, &company); err != nil {
+	if err := unmarshalItem(result.Item, &company); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal company: %w", err)
 	}
 
@@ -427,13 +439,17 @@ func (r *FormatsRepository) Find(ctx context.Context, id int) (*repositories.For
 	}
 
 	var format repositories.Format
-	if err := attributevalue.UnmarshalMap(result.Item, &format); err != nil {
+	if err := unmarshalItem(result.Item, &format); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal format: %w", err)
 	}
 
 	return &format, nil
 }
 
+func (r *FormatsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Format, error) {
+	return batchGetByID(ctx, r.client, r.tableName, ids, func(f *repositories.Format) int { return f.ID })
+}
+
 type CheckoutConfigsRepository struct {
 	*BaseRepository
 	tableName string
@@ -457,7 +473,7 @@ func (r *CheckoutConfigsRepository) Find(ctx context.Context, id int) (*reposito
 	}
 
 	var checkoutConfig repositories.CheckoutConfig
-	if err := attributevalue.UnmarshalMap(result.Item, &checkoutConfig); err != nil {
+	if err := unmarshalItem(result.Item, &checkoutConfig); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal checkout config: %w", err)
 	}
 
@@ -472,7 +488,7 @@ type AffiliatesRepository struct {
 func (r *AffiliatesRepository) FindByUUID(ctx context.Context, uuid string) (*repositories.Affiliate, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("UuidIndex"),
+		IndexName:              stringPtr(IndexUUID),
 		KeyConditionExpression: stringPtr("#uuid = :uuid"),
 		ExpressionAttributeNames: map[string]string{
 			"#uuid": "uuid",
@@ -493,7 +509,7 @@ func (r *AffiliatesRepository) FindByUUID(ctx context.Context, uuid string) (*re
 	}
 
 	var affiliate repositories.Affiliate
-	if err := attributevalue.UnmarshalMap(result.Items[0], &affiliate); err != nil {
+	if err := unmarshalItem(result.Items[0], &affiliate); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal affiliate: %w", err)
 	}
 
@@ -508,7 +524,7 @@ type ProductAffiliateSettingsRepository struct {
 func (r *ProductAffiliateSettingsRepository) FindByProduct(ctx context.Context, productID int) (*repositories.ProductAffiliateSettings, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("ProductIdIndex"),
+		IndexName:              stringPtr(IndexProductID),
 		KeyConditionExpression: stringPtr("product_id = :product_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":product_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", productID)},
@@ -526,7 +542,7 @@ func (r *ProductAffiliateSettingsRepository) FindByProduct(ctx context.Context,
 	}
 
 	var settings repositories.ProductAffiliateSettings
-	if err := attributevalue.UnmarshalMap(result.Items[0], &settings); err != nil {
+	if err := unmarshalItem(result.Items[0], &settings); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal product affiliate settings: %w", err)
 	}
 
@@ -539,30 +555,29 @@ type OrderBumpsRepository struct {
 }
 
 func (r *OrderBumpsRepository) FindAllByOffer(ctx context.Context, offerID int) ([]*repositories.OrderBump, error) {
-	input := &dynamodb.QueryInput{
+	orderBumps, err := queryAll[repositories.OrderBump](ctx, r.client, r.byOfferQuery(offerID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order bumps by offer: %w", err)
+	}
+
+	return orderBumps, nil
+}
+
+// IterAllByOffer streams the order bumps of an offer, stopping after limit
+// items (0 means no limit)
+func (r *OrderBumpsRepository) IterAllByOffer(offerID, limit int) *Iterator[repositories.OrderBump] {
+	return newQueryIterator[repositories.OrderBump](r.client, r.byOfferQuery(offerID), limit, nil)
+}
+
+func (r *OrderBumpsRepository) byOfferQuery(offerID int) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("OfferIdIndex"),
+		IndexName:              stringPtr(IndexOfferID),
 		KeyConditionExpression: stringPtr("offer_id = :offer_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":offer_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)},
 		},
 	}
-
-	result, err := r.client.GetDynamoDB().Query(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query order bumps by offer: %w", err)
-	}
-
-	var orderBumps []*repositories.OrderBump
-	for _, item := range result.Items {
-		var orderBump repositories.OrderBump
-		if err := attributevalue.UnmarshalMap(item, &orderBump); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal order bump: %w", err)
-		}
-		orderBumps = append(orderBumps, &orderBump)
-	}
-
-	return orderBumps, nil
 }
 
 type ReviewsRepository struct {
@@ -571,9 +586,24 @@ type ReviewsRepository struct {
 }
 
 func (r *ReviewsRepository) FindByCheckoutConfig(ctx context.Context, checkoutConfigID int) ([]*repositories.Review, error) {
-	input := &dynamodb.QueryInput{
+	reviews, err := queryAll(ctx, r.client, r.byCheckoutConfigQuery(checkoutConfigID), isActiveReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews by checkout config: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// IterByCheckoutConfig streams the active reviews of a checkout config,
+// stopping after limit items (0 means no limit)
+func (r *ReviewsRepository) IterByCheckoutConfig(checkoutConfigID, limit int) *Iterator[repositories.Review] {
+	return newQueryIterator(r.client, r.byCheckoutConfigQuery(checkoutConfigID), limit, isActiveReview)
+}
+
+func (r *ReviewsRepository) byCheckoutConfigQuery(checkoutConfigID int) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("checkoutConfigId-index"), // Fixed to match TypeScript
+		IndexName:              stringPtr(IndexReviewsByCheckoutConfig), // Fixed to match TypeScript
 		KeyConditionExpression: stringPtr("#checkoutConfigId = :checkoutConfigId"),
 		ExpressionAttributeNames: map[string]string{
 			"#checkoutConfigId": "checkoutConfigId", // Fixed to match TypeScript
@@ -582,26 +612,11 @@ func (r *ReviewsRepository) FindByCheckoutConfig(ctx context.Context, checkoutCo
 			":checkoutConfigId": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", checkoutConfigID)},
 		},
 	}
+}
 
-	result, err := r.client.GetDynamoDB().Query(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query reviews by checkout config: %w", err)
-	}
-
-	var reviews []*repositories.Review
-	for _, item := range result.Items {
-		var review repositories.Review
-		if err := attributevalue.UnmarshalMap(item, &review); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal review: %w", err)
-		}
-		
-		// Filter by status = ACTIVE (same as TypeScript)
-		if review.Status == repositories.ReviewStatusActive {
-			reviews = append(reviews, &review)
-		}
-	}
-
-	return reviews, nil
+// isActiveReview filters by status = ACTIVE (same as TypeScript)
+func isActiveReview(review *repositories.Review) bool {
+	return review.Status == repositories.ReviewStatusActive
 }
 
 type PixelsRepository struct {
@@ -610,10 +625,25 @@ type PixelsRepository struct {
 }
 
 func (r *PixelsRepository) FindAllByUserAndProduct(ctx context.Context, userID, productID int) ([]*repositories.Pixel, error) {
+	pixels, err := queryAll[repositories.Pixel](ctx, r.client, r.byUserAndProductQuery(userID, productID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pixels by user and product: %w", err)
+	}
+
+	return pixels, nil
+}
+
+// IterAllByUserAndProduct streams the pixels of a user and product, stopping
+// after limit items (0 means no limit)
+func (r *PixelsRepository) IterAllByUserAndProduct(userID, productID, limit int) *Iterator[repositories.Pixel] {
+	return newQueryIterator[repositories.Pixel](r.client, r.byUserAndProductQuery(userID, productID), limit, nil)
+}
+
+func (r *PixelsRepository) byUserAndProductQuery(userID, productID int) *dynamodb.QueryInput {
 	// Use composite key query to match TypeScript implementation
-	input := &dynamodb.QueryInput{
+	return &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("productId-userId-index"), // Fixed to match TypeScript
+		IndexName:              stringPtr(IndexPixelsByProductAndUser), // Fixed to match TypeScript
 		KeyConditionExpression: stringPtr("#productId = :productId AND #userId = :userId"), // Composite key like TypeScript
 		ExpressionAttributeNames: map[string]string{
 			"#productId": "productId", // Fixed field names to match TypeScript
@@ -624,22 +654,6 @@ func (r *PixelsRepository) FindAllByUserAndProduct(ctx context.Context, userID,
 			":userId":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
 		},
 	}
-
-	result, err := r.client.GetDynamoDB().Query(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query pixels by user and product: %w", err)
-	}
-
-	var pixels []*repositories.Pixel
-	for _, item := range result.Items {
-		var pixel repositories.Pixel
-		if err := attributevalue.UnmarshalMap(item, &pixel); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal pixel: %w", err)
-		}
-		pixels = append(pixels, &pixel)
-	}
-
-	return pixels, nil
 }
 
 type PlansRepository struct {
@@ -650,7 +664,7 @@ type PlansRepository struct {
 func (r *PlansRepository) FindByUuid(ctx context.Context, uuid string) (*repositories.Plan, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("UuidIndex"),
+		IndexName:              stringPtr(IndexUUID),
 		KeyConditionExpression: stringPtr("#uuid = :uuid"),
 		ExpressionAttributeNames: map[string]string{
 			"#uuid": "uuid",
@@ -671,7 +685,7 @@ func (r *PlansRepository) FindByUuid(ctx context.Context, uuid string) (*reposit
 	}
 
 	var plan repositories.Plan
-	if err := attributevalue.UnmarshalMap(result.Items[0], &plan); err != nil {
+	if err := unmarshalItem(result.Items[0], &plan); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal plan: %w", err)
 	}
 
@@ -679,9 +693,24 @@ func (r *PlansRepository) FindByUuid(ctx context.Context, uuid string) (*reposit
 }
 
 func (r *PlansRepository) FindByOffer(ctx context.Context, offerID int) ([]*repositories.Plan, error) {
-	input := &dynamodb.QueryInput{
+	plans, err := queryAll[repositories.Plan](ctx, r.client, r.byOfferQuery(offerID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plans by offer: %w", err)
+	}
+
+	return plans, nil
+}
+
+// IterByOffer streams the plans of an offer, stopping after limit items
+// (0 means no limit)
+func (r *PlansRepository) IterByOffer(offerID, limit int) *Iterator[repositories.Plan] {
+	return newQueryIterator[repositories.Plan](r.client, r.byOfferQuery(offerID), limit, nil)
+}
+
+func (r *PlansRepository) byOfferQuery(offerID int) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("offerId-index"), // Fixed to match TypeScript
+		IndexName:              stringPtr(IndexPlansByOffer), // Fixed to match TypeScript
 		KeyConditionExpression: stringPtr("#offerId = :offerId"),
 		ExpressionAttributeNames: map[string]string{
 			"#offerId": "offerId", // Fixed to match TypeScript
@@ -690,22 +719,6 @@ func (r *PlansRepository) FindByOffer(ctx context.Context, offerID int) ([]*repo
 			":offerId": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)},
 		},
 	}
-
-	result, err := r.client.GetDynamoDB().Query(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query plans by offer: %w", err)
-	}
-
-	var plans []*repositories.Plan
-	for _, item := range result.Items {
-		var plan repositories.Plan
-		if err := attributevalue.UnmarshalMap(item, &plan); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal plan: %w", err)
-		}
-		plans = append(plans, &plan)
-	}
-
-	return plans, nil
 }
 
 type DiscountsRepository struct {
@@ -716,7 +729,7 @@ type DiscountsRepository struct {
 func (r *DiscountsRepository) CheckHasDiscounts(ctx context.Context, productID int) (bool, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              stringPtr("ProductIdIndex"),
+		IndexName:              stringPtr(IndexProductID),
 		KeyConditionExpression: stringPtr("product_id = :product_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":product_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", productID)},
@@ -732,6 +745,75 @@ func (r *DiscountsRepository) CheckHasDiscounts(ctx context.Context, productID i
 	return len(result.Items) > 0, nil
 }
 
+func (r *DiscountsRepository) FindByCode(ctx context.Context, productID int, code string) (*repositories.Discount, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &r.tableName,
+		IndexName:              stringPtr(IndexDiscountsByCode),
+		KeyConditionExpression: stringPtr("product_id = :product_id AND #code = :code"),
+		ExpressionAttributeNames: map[string]string{
+			"#code": "code",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":product_id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", productID)},
+			":code":       &types.AttributeValueMemberS{Value: strings.ToUpper(code)},
+		},
+		Limit: int32Ptr(1),
+	}
+
+	result, err := r.client.GetDynamoDB().Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discount by code: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var discount repositories.Discount
+	if err := unmarshalItem(result.Items[0], &discount); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal discount: %w", err)
+	}
+
+	return &discount, nil
+}
+
+type CustomersRepository struct {
+	*BaseRepository
+	tableName string
+}
+
+func NewCustomersRepository(client *Client, cfg *config.Config) repositories.CustomersRepository {
+	return &CustomersRepository{
+		BaseRepository: NewBaseRepository(client),
+		tableName:      aws.GetTableName(cfg, TableCustomers),
+	}
+}
+
+func (r *CustomersRepository) Find(ctx context.Context, id int) (*repositories.Customer, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: &r.tableName,
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)},
+		},
+	}
+
+	result, err := r.client.GetDynamoDB().GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer by ID: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var customer repositories.Customer
+	if err := unmarshalItem(result.Item, &customer); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
 // Helper functions
 func stringPtr(s string) *string {
 	return &s
@@ -740,3 +822,7 @@ func stringPtr(s string) *string {
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
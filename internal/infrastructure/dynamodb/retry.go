@@ -0,0 +1,24 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+)
+
+// backoff returns the delay before the given retry of unprocessed items
+func backoff(attempt int) time.Duration {
+	return 50 * time.Millisecond << min(attempt-1, 5)
+}
+
+// sleepWithContext waits for d or until ctx is done
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package dynamodb
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/config"
+	"checkout-go/internal/infrastructure/aws"
+	"checkout-go/internal/repositories"
+)
+
+// Base table names. The physical names depend on the environment, see
+// config.GetTableName.
+const (
+	TableOffers                   = "offers"
+	TableProducts                 = "products"
+	TableUsers                    = "users"
+	TableCompanies                = "companies"
+	TableFormats                  = "formats"
+	TableCheckoutConfigs          = "checkout_configs"
+	TableAffiliates               = "affiliates"
+	TableProductAffiliateSettings = "product_affiliate_settings"
+	TableCheckouts                = "checkouts"
+	TableOrderBumps               = "order_bumps"
+	TableReviews                  = "reviews"
+	TablePixels                   = "pixels"
+	TablePlans                    = "plans"
+	TableDiscounts                = "discounts"
+	TableOfferCheckoutCountShards = "offer_checkout_count_shards"
+	TableJobCursors               = "job_cursors"
+	TableCustomers                = "customers"
+)
+
+// Global secondary index names. Some follow the naming of the TypeScript
+// service that shares the tables.
+const (
+	IndexUUID                    = "UuidIndex"
+	IndexProductID               = "ProductIdIndex"
+	IndexOfferID                 = "OfferIdIndex"
+	IndexReviewsByCheckoutConfig = "checkoutConfigId-index"
+	IndexPixelsByProductAndUser  = "productId-userId-index"
+	IndexPlansByOffer            = "offerId-index"
+	IndexCheckoutsByProduct      = "ProductIdCreatedAtIndex"
+	IndexCheckoutsByOffer        = "OfferIdCreatedAtIndex"
+	IndexCheckoutsByAffiliate    = "AffiliateIdCreatedAtIndex"
+	IndexCheckoutsByStatus       = "StatusShardCreatedAtIndex"
+	IndexDiscountsByCode         = "ProductIdCodeIndex"
+)
+
+// KeyAttribute is a key attribute of a table or index
+type KeyAttribute struct {
+	Name string
+	Type types.ScalarAttributeType
+}
+
+// IndexSchema describes a global secondary index projecting all attributes
+type IndexSchema struct {
+	Name     string
+	HashKey  KeyAttribute
+	RangeKey *KeyAttribute
+}
+
+// TableSchema describes a table the service reads or writes
+type TableSchema struct {
+	// Name is the base name in Tables and the physical name from TablesFor
+	Name     string
+	HashKey  KeyAttribute
+	RangeKey *KeyAttribute
+	Indexes  []IndexSchema
+	// Stream enables a NEW_AND_OLD_IMAGES stream for cache invalidation
+	Stream bool
+	// TTLAttribute enables time to live on the given attribute
+	TTLAttribute string
+	// Model is the struct the items decode into, checked by DetectDrift
+	Model interface{}
+}
+
+var (
+	numberID   = KeyAttribute{Name: "id", Type: types.ScalarAttributeTypeN}
+	stringUUID = KeyAttribute{Name: "uuid", Type: types.ScalarAttributeTypeS}
+	createdAt  = &KeyAttribute{Name: "created_at", Type: types.ScalarAttributeTypeS}
+)
+
+// Tables is the single definition of the tables and indexes the repositories
+// depend on; cmd/dbctl creates them from it
+var Tables = []TableSchema{
+	{
+		Name:    TableOffers,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{Name: IndexUUID, HashKey: stringUUID}},
+		Stream:  true,
+		Model:   repositories.Offer{},
+	},
+	{Name: TableProducts, HashKey: numberID, Stream: true, Model: repositories.Product{}},
+	{Name: TableUsers, HashKey: numberID, Stream: true, Model: repositories.User{}},
+	{Name: TableCompanies, HashKey: numberID, Stream: true, Model: repositories.Company{}},
+	{Name: TableFormats, HashKey: numberID, Stream: true, Model: repositories.Format{}},
+	{Name: TableCheckoutConfigs, HashKey: numberID, Stream: true, Model: repositories.CheckoutConfig{}},
+	{
+		Name:    TableAffiliates,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{Name: IndexUUID, HashKey: stringUUID}},
+		Model:   repositories.Affiliate{},
+	},
+	{
+		Name:    TableProductAffiliateSettings,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{Name: IndexProductID, HashKey: KeyAttribute{Name: "product_id", Type: types.ScalarAttributeTypeN}}},
+		Model:   repositories.ProductAffiliateSettings{},
+	},
+	{
+		Name:    TableCheckouts,
+		HashKey: stringUUID,
+		Indexes: []IndexSchema{
+			{Name: IndexCheckoutsByProduct, HashKey: KeyAttribute{Name: "product_id", Type: types.ScalarAttributeTypeN}, RangeKey: createdAt},
+			{Name: IndexCheckoutsByOffer, HashKey: KeyAttribute{Name: "offer_id", Type: types.ScalarAttributeTypeN}, RangeKey: createdAt},
+			{Name: IndexCheckoutsByAffiliate, HashKey: KeyAttribute{Name: "affiliate_id", Type: types.ScalarAttributeTypeN}, RangeKey: createdAt},
+			{Name: IndexCheckoutsByStatus, HashKey: KeyAttribute{Name: "status_shard", Type: types.ScalarAttributeTypeS}, RangeKey: createdAt},
+		},
+		Model: checkoutItem{},
+	},
+	{
+		Name:    TableOrderBumps,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{Name: IndexOfferID, HashKey: KeyAttribute{Name: "offer_id", Type: types.ScalarAttributeTypeN}}},
+		Stream:  true,
+		Model:   repositories.OrderBump{},
+	},
+	{
+		Name:    TableReviews,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{Name: IndexReviewsByCheckoutConfig, HashKey: KeyAttribute{Name: "checkoutConfigId", Type: types.ScalarAttributeTypeN}}},
+		Stream:  true,
+		Model:   repositories.Review{},
+	},
+	{
+		Name:    TablePixels,
+		HashKey: numberID,
+		Indexes: []IndexSchema{{
+			Name:     IndexPixelsByProductAndUser,
+			HashKey:  KeyAttribute{Name: "productId", Type: types.ScalarAttributeTypeN},
+			RangeKey: &KeyAttribute{Name: "userId", Type: types.ScalarAttributeTypeN},
+		}},
+		Stream: true,
+		Model:  repositories.Pixel{},
+	},
+	{
+		Name:    TablePlans,
+		HashKey: numberID,
+		Indexes: []IndexSchema{
+			{Name: IndexUUID, HashKey: stringUUID},
+			{Name: IndexPlansByOffer, HashKey: KeyAttribute{Name: "offerId", Type: types.ScalarAttributeTypeN}},
+		},
+		Stream: true,
+		Model:  repositories.Plan{},
+	},
+	{
+		Name:    TableDiscounts,
+		HashKey: numberID,
+		Indexes: []IndexSchema{
+			{Name: IndexProductID, HashKey: KeyAttribute{Name: "product_id", Type: types.ScalarAttributeTypeN}},
+			{
+				Name:     IndexDiscountsByCode,
+				HashKey:  KeyAttribute{Name: "product_id", Type: types.ScalarAttributeTypeN},
+				RangeKey: &KeyAttribute{Name: "code", Type: types.ScalarAttributeTypeS},
+			},
+		},
+		Model: repositories.Discount{},
+	},
+	{
+		Name:     TableOfferCheckoutCountShards,
+		HashKey:  KeyAttribute{Name: "offer_id", Type: types.ScalarAttributeTypeN},
+		RangeKey: &KeyAttribute{Name: "shard", Type: types.ScalarAttributeTypeN},
+		Model:    offerCountShard{},
+	},
+	{Name: TableCustomers, HashKey: numberID, Model: repositories.Customer{}},
+	{Name: TableJobCursors, HashKey: KeyAttribute{Name: "job", Type: types.ScalarAttributeTypeS}, Model: jobCursor{}},
+}
+
+// invalidationsTable describes the cache invalidation events table, whose
+// base name is configurable
+func invalidationsTable(baseName string) TableSchema {
+	return TableSchema{
+		Name:         baseName,
+		HashKey:      KeyAttribute{Name: "channel", Type: types.ScalarAttributeTypeS},
+		RangeKey:     &KeyAttribute{Name: "sequence", Type: types.ScalarAttributeTypeS},
+		TTLAttribute: "expires_at",
+		Model:        invalidationItem{},
+	}
+}
+
+// BaseTableNames returns the base name of every table of the service,
+// including the configured invalidations table
+func BaseTableNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(Tables)+1)
+	for _, table := range Tables {
+		names = append(names, table.Name)
+	}
+	return append(names, cfg.InvalidationTable)
+}
+
+// TablesFor returns every table of the service, including the invalidations
+// table, with its physical name in the configured environment
+func TablesFor(cfg *config.Config) []TableSchema {
+	tables := make([]TableSchema, 0, len(Tables)+1)
+	tables = append(tables, Tables...)
+	tables = append(tables, invalidationsTable(cfg.InvalidationTable))
+	for i := range tables {
+		tables[i].Name = aws.GetTableName(cfg, tables[i].Name)
+	}
+	return tables
+}
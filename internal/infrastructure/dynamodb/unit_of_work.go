@@ -0,0 +1,135 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"checkout-go/internal/config"
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/infrastructure/aws"
+	"checkout-go/internal/repositories"
+)
+
+// maxTransactItems is the DynamoDB limit of actions per TransactWriteItems call
+const maxTransactItems = 100
+
+// UnitOfWorkFactory starts units of work committed with TransactWriteItems
+type UnitOfWorkFactory struct {
+	*BaseRepository
+	checkoutsTable string
+	offersTable    string
+}
+
+func NewUnitOfWorkFactory(client *Client, cfg *config.Config) *UnitOfWorkFactory {
+	return &UnitOfWorkFactory{
+		BaseRepository: NewBaseRepository(client),
+		checkoutsTable: aws.GetTableName(cfg, TableCheckouts),
+		offersTable:    aws.GetTableName(cfg, TableOffers),
+	}
+}
+
+func (f *UnitOfWorkFactory) Begin() repositories.UnitOfWork {
+	return &UnitOfWork{factory: f}
+}
+
+// UnitOfWork collects transactional writes; errors found while registering
+// them are reported by Commit
+type UnitOfWork struct {
+	factory *UnitOfWorkFactory
+	items   []types.TransactWriteItem
+	// descriptions names each item in cancellation errors
+	descriptions []string
+	err          error
+}
+
+func (u *UnitOfWork) CreateCheckout(checkout *entities.Checkout) {
+	item, err := marshalCheckout(checkout)
+	if err != nil {
+		u.fail(fmt.Errorf("failed to marshal checkout: %w", err))
+		return
+	}
+
+	u.add("create checkout "+checkout.UUID, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:           &u.factory.checkoutsTable,
+			Item:                item,
+			ConditionExpression: stringPtr("attribute_not_exists(#uuid)"),
+			ExpressionAttributeNames: map[string]string{
+				"#uuid": "uuid",
+			},
+		},
+	})
+}
+
+func (u *UnitOfWork) IncrementOfferCheckoutCount(offerID int) {
+	u.add(fmt.Sprintf("increment checkout count of offer %d", offerID), types.TransactWriteItem{
+		Update: &types.Update{
+			TableName: &u.factory.offersTable,
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", offerID)},
+			},
+			// The condition keeps ADD from creating a stub offer item
+			UpdateExpression:    stringPtr("ADD checkout_count :inc"),
+			ConditionExpression: stringPtr("attribute_exists(id)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":inc": &types.AttributeValueMemberN{Value: "1"},
+			},
+		},
+	})
+}
+
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	if u.err != nil {
+		return u.err
+	}
+	if len(u.items) == 0 {
+		return nil
+	}
+	if len(u.items) > maxTransactItems {
+		return fmt.Errorf("unit of work has %d writes, the limit is %d", len(u.items), maxTransactItems)
+	}
+
+	_, err := u.factory.client.GetDynamoDB().TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: u.items,
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			return fmt.Errorf("transaction canceled: %s: %w", u.cancellationReasons(canceled), err)
+		}
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (u *UnitOfWork) add(description string, item types.TransactWriteItem) {
+	u.items = append(u.items, item)
+	u.descriptions = append(u.descriptions, description)
+}
+
+func (u *UnitOfWork) fail(err error) {
+	if u.err == nil {
+		u.err = err
+	}
+}
+
+// cancellationReasons describes the writes that made the transaction fail
+func (u *UnitOfWork) cancellationReasons(canceled *types.TransactionCanceledException) string {
+	var reasons []string
+	for i, reason := range canceled.CancellationReasons {
+		if reason.Code == nil || *reason.Code == "None" || i >= len(u.descriptions) {
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", u.descriptions[i], *reason.Code))
+	}
+	if len(reasons) == 0 {
+		return "unknown reason"
+	}
+	return strings.Join(reasons, ", ")
+}
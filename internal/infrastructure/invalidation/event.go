@@ -0,0 +1,61 @@
+package invalidation
+
+import (
+	"context"
+	"time"
+)
+
+// Event tells the running services that a record of a cached table changed
+type Event struct {
+	// Sequence orders events; it is assigned by the transport that stores them
+	Sequence string `json:"sequence,omitempty" dynamodb:"sequence"`
+	// Table is the base table name (offers, checkout_configs, ...)
+	Table string `json:"table" dynamodb:"table"`
+	// Action is the stream event name: INSERT, MODIFY or REMOVE
+	Action string `json:"action" dynamodb:"action"`
+	// ID and UUID identify the changed record when the table has them
+	ID   *int   `json:"id,omitempty" dynamodb:"id,omitempty"`
+	UUID string `json:"uuid,omitempty" dynamodb:"uuid,omitempty"`
+	// Keys holds the foreign keys of list records (offer_id, checkoutConfigId,
+	// ...) so that caches of list queries can drop the affected lists
+	Keys       map[string]string `json:"keys,omitempty" dynamodb:"keys,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at" dynamodb:"occurred_at"`
+}
+
+// Publisher delivers invalidation events to the running services
+type Publisher interface {
+	Publish(ctx context.Context, events []Event) error
+}
+
+// Handler applies an invalidation event
+type Handler func(event Event)
+
+// Applier routes invalidation events to the handlers registered for their table
+type Applier struct {
+	handlers map[string][]Handler
+}
+
+// NewApplier creates an applier without handlers
+func NewApplier() *Applier {
+	return &Applier{handlers: make(map[string][]Handler)}
+}
+
+// Register adds a handler for the events of a base table. Registration is not
+// synchronized and must happen before events are applied.
+func (a *Applier) Register(table string, handler Handler) {
+	a.handlers[table] = append(a.handlers[table], handler)
+}
+
+// Apply runs the handlers registered for the event's table
+func (a *Applier) Apply(event Event) {
+	for _, handler := range a.handlers[event.Table] {
+		handler(event)
+	}
+}
+
+// ApplyAll applies events in order
+func (a *Applier) ApplyAll(events []Event) {
+	for _, event := range events {
+		a.Apply(event)
+	}
+}
@@ -0,0 +1,45 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LocalBus is the in-process stand-in for the invalidation transport: events
+// published to it are applied synchronously to the subscribed appliers, so
+// the stream consumer and the caches can run in the same process without AWS.
+type LocalBus struct {
+	mu       sync.RWMutex
+	sequence uint64
+	appliers []*Applier
+}
+
+// NewLocalBus creates a bus without subscribers
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+// Subscribe makes the bus apply every published event to applier
+func (b *LocalBus) Subscribe(applier *Applier) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.appliers = append(b.appliers, applier)
+}
+
+// Publish applies events to every subscriber
+func (b *LocalBus) Publish(ctx context.Context, events []Event) error {
+	b.mu.Lock()
+	for i := range events {
+		b.sequence++
+		events[i].Sequence = fmt.Sprintf("%020d", b.sequence)
+	}
+	appliers := append([]*Applier(nil), b.appliers...)
+	b.mu.Unlock()
+
+	for _, applier := range appliers {
+		applier.ApplyAll(events)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pollBatchSize is the number of events read per poll request
+const pollBatchSize = 100
+
+// Source reads stored invalidation events in sequence order
+type Source interface {
+	FindSince(ctx context.Context, after string, limit int) ([]Event, error)
+}
+
+// SequenceAt returns the sequence prefix of events published at t. Sequences
+// are zero-padded so that they sort lexicographically by time.
+func SequenceAt(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// sequenceTime returns the publish time a sequence starts with
+func sequenceTime(sequence string) (time.Time, bool) {
+	if len(sequence) < 20 {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(sequence[:20], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
+
+// Poller applies the events a Source accumulates after the poller started.
+//
+// Sequences come from the publishers' clocks, so an event of a skewed or slow
+// publisher can be stored behind events already read. Every poll therefore
+// reads again the overlap window before the newest sequence seen, and skips
+// the events of the window it already applied.
+type Poller struct {
+	source   Source
+	applier  *Applier
+	interval time.Duration
+	overlap  time.Duration
+
+	mu       sync.Mutex
+	newest   time.Time
+	applied  map[string]time.Time
+	lastPoll time.Time
+}
+
+// NewPoller creates a poller that ignores the events published more than
+// overlap before now: the caches of a fresh process are empty, so older
+// events are irrelevant
+func NewPoller(source Source, applier *Applier, interval, overlap time.Duration) *Poller {
+	return &Poller{
+		source:   source,
+		applier:  applier,
+		interval: interval,
+		overlap:  overlap,
+		newest:   time.Now(),
+		applied:  make(map[string]time.Time),
+	}
+}
+
+// Poll applies every event published since the previous poll
+func (p *Poller) Poll(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastPoll = time.Now()
+	after := SequenceAt(p.newest.Add(-p.overlap))
+	for {
+		events, err := p.source.FindSince(ctx, after, pollBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to poll invalidation events: %w", err)
+		}
+
+		for _, event := range events {
+			if _, ok := p.applied[event.Sequence]; ok {
+				continue
+			}
+			p.applier.Apply(event)
+
+			publishedAt, ok := sequenceTime(event.Sequence)
+			if !ok {
+				publishedAt = p.newest
+			}
+			p.applied[event.Sequence] = publishedAt
+			if publishedAt.After(p.newest) {
+				p.newest = publishedAt
+			}
+		}
+		if len(events) > 0 {
+			after = events[len(events)-1].Sequence
+		}
+		if len(events) < pollBatchSize {
+			break
+		}
+	}
+
+	// Forget the events that left the window; they are not read again
+	windowStart := p.newest.Add(-p.overlap)
+	for sequence, publishedAt := range p.applied {
+		if publishedAt.Before(windowStart) {
+			delete(p.applied, sequence)
+		}
+	}
+	return nil
+}
+
+// PollIfDue polls when the interval elapsed since the previous poll. It suits
+// runtimes without background work, such as Lambda, where it runs at the start
+// of each invocation.
+func (p *Poller) PollIfDue(ctx context.Context) error {
+	p.mu.Lock()
+	due := time.Since(p.lastPoll) >= p.interval
+	p.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return p.Poll(ctx)
+}
+
+// Run polls every interval until ctx is done. Failures are logged and retried
+// on the next tick.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Poll(ctx); err != nil {
+				log.Printf("Cache invalidation poll failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package invalidation
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+// memorySource stores events the way the invalidations table does: sorted
+// by sequence, whatever order they were written in
+type memorySource struct {
+	events []Event
+}
+
+func (s *memorySource) add(publishedAt time.Time, table string) {
+	s.events = append(s.events, Event{Sequence: SequenceAt(publishedAt) + "-1", Table: table})
+	sort.Slice(s.events, func(i, j int) bool { return s.events[i].Sequence < s.events[j].Sequence })
+}
+
+func (s *memorySource) FindSince(ctx context.Context, after string, limit int) ([]Event, error) {
+	var found []Event
+	for _, event := range s.events {
+		if event.Sequence > after && len(found) < limit {
+			found = append(found, event)
+		}
+	}
+	return found, nil
+}
+
+func TestPollerAppliesLateEventsOnce(t *testing.T) {
+	source := &memorySource{}
+	applier := NewApplier()
+	var applied []string
+	for _, table := range []string{"offers", "products", "users"} {
+		table := table
+		applier.Register(table, func(event Event) { applied = append(applied, table) })
+	}
+
+	poller := NewPoller(source, applier, time.Second, 10*time.Second)
+	now := time.Now()
+
+	source.add(now.Add(time.Second), "offers")
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	// A publisher whose clock is 5s late writes after the poll
+	source.add(now.Add(-4*time.Second), "products")
+	// One beyond the overlap is lost, as documented
+	source.add(now.Add(-time.Minute), "users")
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	want := []string{"offers", "products"}
+	if len(applied) != len(want) {
+		t.Fatalf("applied %v, want %v", applied, want)
+	}
+	for i := range want {
+		if applied[i] != want[i] {
+			t.Errorf("applied %v, want %v", applied, want)
+		}
+	}
+}
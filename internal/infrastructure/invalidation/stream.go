@@ -0,0 +1,222 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// StreamTables are the base tables whose DynamoDB Streams are consumed
+var StreamTables = []string{
+	"offers",
+	"products",
+	"users",
+	"companies",
+	"formats",
+	"checkout_configs",
+	"order_bumps",
+	"plans",
+	"pixels",
+	"reviews",
+}
+
+// foreignKeys lists, per list table, the attributes its list queries filter on
+var foreignKeys = map[string][]string{
+	"order_bumps": {"offer_id"},
+	"plans":       {"offer_id", "offerId"},
+	"pixels":      {"userId", "productId"},
+	"reviews":     {"checkoutConfigId"},
+}
+
+// counterAttributes lists, per table, the attributes that only count things
+// and are not cached, so changing them alone invalidates nothing. Every
+// checkout view adds to the offer's checkout_count.
+var counterAttributes = map[string][]string{
+	"offers": {"checkout_count"},
+}
+
+// TableResolver maps a physical table name to its base table name
+type TableResolver func(physicalName string) (baseName string, ok bool)
+
+// StreamHandler translates DynamoDB Stream records into invalidation events
+type StreamHandler struct {
+	publisher Publisher
+	resolve   TableResolver
+}
+
+// NewStreamHandler creates a handler publishing to publisher
+func NewStreamHandler(publisher Publisher, resolve TableResolver) *StreamHandler {
+	return &StreamHandler{publisher: publisher, resolve: resolve}
+}
+
+// Handle publishes one invalidation event per relevant record. Records of
+// unknown tables are skipped; a publish failure fails the whole batch so the
+// stream retries it, which is safe because invalidations are idempotent.
+func (h *StreamHandler) Handle(ctx context.Context, event events.DynamoDBEvent) error {
+	var invalidations []Event
+	for _, record := range event.Records {
+		invalidation, ok := h.translate(record)
+		if !ok {
+			continue
+		}
+		invalidations = append(invalidations, invalidation)
+	}
+
+	if len(invalidations) == 0 {
+		return nil
+	}
+
+	if err := h.publisher.Publish(ctx, invalidations); err != nil {
+		return fmt.Errorf("failed to publish invalidation events: %w", err)
+	}
+
+	log.Printf("Published %d invalidation events from %d stream records", len(invalidations), len(event.Records))
+	return nil
+}
+
+// translate converts a stream record into an invalidation event
+func (h *StreamHandler) translate(record events.DynamoDBEventRecord) (Event, bool) {
+	physicalName := tableFromStreamARN(record.EventSourceArn)
+	table, ok := h.resolve(physicalName)
+	if !ok {
+		log.Printf("Skipping stream record %s from unknown table %q", record.EventID, physicalName)
+		return Event{}, false
+	}
+
+	if onlyCountersChanged(table, record) {
+		return Event{}, false
+	}
+
+	// REMOVE records only carry the old image, INSERT only the new one
+	image := record.Change.NewImage
+	if len(image) == 0 {
+		image = record.Change.OldImage
+	}
+
+	event := Event{
+		Table:      table,
+		Action:     record.EventName,
+		OccurredAt: record.Change.ApproximateCreationDateTime.Time,
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if id, ok := numberAttribute(record.Change.Keys, "id"); ok {
+		event.ID = &id
+	} else if id, ok := numberAttribute(image, "id"); ok {
+		event.ID = &id
+	}
+	if uuid, ok := stringAttribute(record.Change.Keys, "uuid"); ok {
+		event.UUID = uuid
+	} else if uuid, ok := stringAttribute(image, "uuid"); ok {
+		event.UUID = uuid
+	}
+
+	for _, name := range foreignKeys[table] {
+		if value, ok := rawAttribute(image, name); ok {
+			if event.Keys == nil {
+				event.Keys = make(map[string]string)
+			}
+			event.Keys[name] = value
+		}
+	}
+
+	return event, true
+}
+
+// onlyCountersChanged reports whether a MODIFY record changes nothing but
+// counter attributes. Records without both images (KEYS_ONLY streams) are
+// assumed to change something.
+func onlyCountersChanged(table string, record events.DynamoDBEventRecord) bool {
+	counters := counterAttributes[table]
+	oldImage, newImage := record.Change.OldImage, record.Change.NewImage
+	if record.EventName != "MODIFY" || len(counters) == 0 || len(oldImage) == 0 || len(newImage) == 0 {
+		return false
+	}
+
+	isCounter := func(name string) bool {
+		for _, counter := range counters {
+			if counter == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for name, value := range newImage {
+		if isCounter(name) {
+			continue
+		}
+		if old, ok := oldImage[name]; !ok || !reflect.DeepEqual(old, value) {
+			return false
+		}
+	}
+	for name := range oldImage {
+		if _, ok := newImage[name]; !ok && !isCounter(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// tableFromStreamARN extracts the table name from a stream ARN such as
+// arn:aws:dynamodb:us-east-1:123456789012:table/offers/stream/2024-01-01T00:00:00.000
+func tableFromStreamARN(arn string) string {
+	_, resource, found := strings.Cut(arn, ":table/")
+	if !found {
+		return ""
+	}
+	table, _, _ := strings.Cut(resource, "/")
+	return table
+}
+
+func numberAttribute(image map[string]events.DynamoDBAttributeValue, name string) (int, bool) {
+	value, ok := image[name]
+	if !ok || value.DataType() != events.DataTypeNumber {
+		return 0, false
+	}
+	parsed, err := strconv.Atoi(value.Number())
+	if err != nil {
+		return 0, false
+	}
+	return parsed, true
+}
+
+func stringAttribute(image map[string]events.DynamoDBAttributeValue, name string) (string, bool) {
+	value, ok := image[name]
+	if !ok || value.DataType() != events.DataTypeString {
+		return "", false
+	}
+	return value.String(), true
+}
+
+// rawAttribute returns a string or number attribute in its string form
+func rawAttribute(image map[string]events.DynamoDBAttributeValue, name string) (string, bool) {
+	if value, ok := stringAttribute(image, name); ok {
+		return value, true
+	}
+	if value, ok := image[name]; ok && value.DataType() == events.DataTypeNumber {
+		return value.Number(), true
+	}
+	return "", false
+}
+
+// NewTableResolver builds a resolver for the given base tables, using
+// physicalName to compute their names in the current environment
+func NewTableResolver(baseNames []string, physicalName func(baseName string) string) TableResolver {
+	tables := make(map[string]string, len(baseNames))
+	for _, baseName := range baseNames {
+		tables[physicalName(baseName)] = baseName
+	}
+	return func(name string) (string, bool) {
+		baseName, ok := tables[name]
+		return baseName, ok
+	}
+}
@@ -0,0 +1,71 @@
+package invalidation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type recordingPublisher struct {
+	published []Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, events []Event) error {
+	p.published = append(p.published, events...)
+	return nil
+}
+
+func offerRecord(eventName string, oldImage, newImage map[string]events.DynamoDBAttributeValue) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventID:        "1",
+		EventName:      eventName,
+		EventSourceArn: "arn:aws:dynamodb:us-east-1:123456789012:table/offers/stream/2024-01-01T00:00:00.000",
+		Change: events.DynamoDBStreamRecord{
+			Keys:     map[string]events.DynamoDBAttributeValue{"id": events.NewNumberAttribute("1")},
+			OldImage: oldImage,
+			NewImage: newImage,
+		},
+	}
+}
+
+func offerImage(price, checkoutCount string) map[string]events.DynamoDBAttributeValue {
+	return map[string]events.DynamoDBAttributeValue{
+		"id":             events.NewNumberAttribute("1"),
+		"uuid":           events.NewStringAttribute("123e4567-e89b-12d3-a456-426614174000"),
+		"price":          events.NewNumberAttribute(price),
+		"checkout_count": events.NewNumberAttribute(checkoutCount),
+	}
+}
+
+func TestStreamHandlerSkipsCounterOnlyChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		record events.DynamoDBEventRecord
+		want   int
+	}{
+		{"checkout count only", offerRecord("MODIFY", offerImage("19700", "5"), offerImage("19700", "6")), 0},
+		{"price and checkout count", offerRecord("MODIFY", offerImage("19700", "5"), offerImage("9900", "6")), 1},
+		{"attribute removed", offerRecord("MODIFY", offerImage("19700", "5"), map[string]events.DynamoDBAttributeValue{
+			"id":             events.NewNumberAttribute("1"),
+			"checkout_count": events.NewNumberAttribute("6"),
+		}), 1},
+		{"keys only", offerRecord("MODIFY", nil, nil), 1},
+		{"insert", offerRecord("INSERT", nil, offerImage("19700", "0")), 1},
+	}
+
+	resolve := func(name string) (string, bool) { return name, name == "offers" }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			handler := NewStreamHandler(publisher, resolve)
+
+			if err := handler.Handle(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{tt.record}}); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if len(publisher.published) != tt.want {
+				t.Errorf("published %d events, want %d", len(publisher.published), tt.want)
+			}
+		})
+	}
+}
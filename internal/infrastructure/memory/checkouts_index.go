@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// checkoutIndexes mirror the created_at GSIs of the DynamoDB checkouts table:
+// each maps a partition value to the UUIDs of its checkouts
+type checkoutIndexes struct {
+	byProduct   map[int]map[string]struct{}
+	byOffer     map[int]map[string]struct{}
+	byAffiliate map[int]map[string]struct{}
+	byStatus    map[entities.CheckoutStatus]map[string]struct{}
+}
+
+func newCheckoutIndexes() checkoutIndexes {
+	return checkoutIndexes{
+		byProduct:   make(map[int]map[string]struct{}),
+		byOffer:     make(map[int]map[string]struct{}),
+		byAffiliate: make(map[int]map[string]struct{}),
+		byStatus:    make(map[entities.CheckoutStatus]map[string]struct{}),
+	}
+}
+
+// putCheckout stores checkout and keeps the indexes in sync. The caller must
+// hold the write lock.
+func (s *Store) putCheckout(checkout *entities.Checkout) {
+	if previous, exists := s.checkouts[checkout.UUID]; exists {
+		s.checkoutIndexes.remove(previous)
+	}
+	s.checkouts[checkout.UUID] = checkout
+	s.checkoutIndexes.add(checkout)
+}
+
+func (i checkoutIndexes) add(checkout *entities.Checkout) {
+	addToIndex(i.byProduct, checkout.ProductID, checkout.UUID)
+	if checkout.OfferID != nil {
+		addToIndex(i.byOffer, *checkout.OfferID, checkout.UUID)
+	}
+	if checkout.AffiliateID != nil {
+		addToIndex(i.byAffiliate, *checkout.AffiliateID, checkout.UUID)
+	}
+	addToIndex(i.byStatus, checkout.Status, checkout.UUID)
+}
+
+func (i checkoutIndexes) remove(checkout *entities.Checkout) {
+	removeFromIndex(i.byProduct, checkout.ProductID, checkout.UUID)
+	if checkout.OfferID != nil {
+		removeFromIndex(i.byOffer, *checkout.OfferID, checkout.UUID)
+	}
+	if checkout.AffiliateID != nil {
+		removeFromIndex(i.byAffiliate, *checkout.AffiliateID, checkout.UUID)
+	}
+	removeFromIndex(i.byStatus, checkout.Status, checkout.UUID)
+}
+
+func addToIndex[K comparable](index map[K]map[string]struct{}, key K, uuid string) {
+	entries, ok := index[key]
+	if !ok {
+		entries = make(map[string]struct{})
+		index[key] = entries
+	}
+	entries[uuid] = struct{}{}
+}
+
+func removeFromIndex[K comparable](index map[K]map[string]struct{}, key K, uuid string) {
+	entries := index[key]
+	delete(entries, uuid)
+	if len(entries) == 0 {
+		delete(index, key)
+	}
+}
+
+func (r *CheckoutsRepository) FindByProduct(ctx context.Context, productID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.findPage(r.store.checkoutIndexes.byProduct[productID], query)
+}
+
+func (r *CheckoutsRepository) FindByOffer(ctx context.Context, offerID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.findPage(r.store.checkoutIndexes.byOffer[offerID], query)
+}
+
+func (r *CheckoutsRepository) FindByAffiliate(ctx context.Context, affiliateID int, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.findPage(r.store.checkoutIndexes.byAffiliate[affiliateID], query)
+}
+
+func (r *CheckoutsRepository) FindByStatus(ctx context.Context, status entities.CheckoutStatus, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.findPage(r.store.checkoutIndexes.byStatus[status], query)
+}
+
+// findPage orders the indexed checkouts by (created_at, uuid), like the sort
+// key of a DynamoDB index, and returns the page after the cursor. The caller
+// must hold the read lock.
+func (r *CheckoutsRepository) findPage(uuids map[string]struct{}, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	after, err := decodeCheckoutCursor(query.Cursor)
+	if err != nil {
+		return nil, errors.NewInvalidParameterValueError("cursor", query.Cursor)
+	}
+
+	matches := make([]*entities.Checkout, 0, len(uuids))
+	for uuid := range uuids {
+		checkout := r.store.checkouts[uuid]
+		if !query.From.IsZero() && checkout.CreatedAt.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !checkout.CreatedAt.Before(query.To) {
+			continue
+		}
+		matches = append(matches, checkout)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return checkoutPosition(matches[i]).before(checkoutPosition(matches[j]), query.Descending)
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matches), func(i int) bool {
+			return after.before(checkoutPosition(matches[i]), query.Descending)
+		})
+	}
+
+	end := min(start+query.PageSize(), len(matches))
+	page := &repositories.CheckoutPage{Items: make([]*entities.Checkout, 0, end-start)}
+	for _, checkout := range matches[start:end] {
+		page.Items = append(page.Items, cloneCheckout(checkout))
+	}
+	if end < len(matches) {
+		page.NextCursor = encodeCheckoutCursor(checkoutPosition(matches[end-1]))
+	}
+	return page, nil
+}
+
+// position is the sort key of a checkout within an index
+type position struct {
+	createdAt time.Time
+	uuid      string
+}
+
+func checkoutPosition(checkout *entities.Checkout) position {
+	return position{createdAt: checkout.CreatedAt, uuid: checkout.UUID}
+}
+
+// before reports whether p sorts before other in the requested direction
+func (p position) before(other position, descending bool) bool {
+	if descending {
+		p, other = other, p
+	}
+	if !p.createdAt.Equal(other.createdAt) {
+		return p.createdAt.Before(other.createdAt)
+	}
+	return p.uuid < other.uuid
+}
+
+func encodeCheckoutCursor(p position) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(p.createdAt.UTC().Format(time.RFC3339Nano) + "|" + p.uuid))
+}
+
+func decodeCheckoutCursor(cursor string) (*position, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, uuid, found := strings.Cut(string(data), "|")
+	if !found {
+		return nil, errors.NewInvalidParameterValueError("cursor", cursor)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &position{createdAt: parsed, uuid: uuid}, nil
+}
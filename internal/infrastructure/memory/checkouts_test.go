@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+func TestCheckoutTransitionsBumpTheVersion(t *testing.T) {
+	checkouts := NewCheckoutsRepository(NewStore())
+	ctx := context.Background()
+	uuid := "366b643f-3ad1-4204-9655-cdd079d2498c"
+	created := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	if err := checkouts.Create(ctx, &entities.Checkout{UUID: uuid, ProductID: 1, Status: entities.CheckoutStatusAccessed, UpdatedAt: created, Version: 1}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	steps := []struct {
+		status entities.CheckoutStatus
+		mark   func(*entities.Checkout, time.Time) error
+	}{
+		{entities.CheckoutStatusAbandonedCart, (*entities.Checkout).MarkAbandoned},
+		{entities.CheckoutStatusRecovered, (*entities.Checkout).MarkRecovered},
+		{entities.CheckoutStatusSaleFinalized, (*entities.Checkout).MarkSaleFinalized},
+	}
+	for i, step := range steps {
+		checkout, err := repositories.UpdateCheckoutWithRetry(ctx, checkouts, uuid, 1, func(checkout *entities.Checkout) error {
+			return step.mark(checkout, time.Now())
+		})
+		if err != nil {
+			t.Fatalf("mark %s: %v", step.status, err)
+		}
+
+		stored, _ := checkouts.FindByUUID(ctx, uuid)
+		if stored.Status != step.status || stored.Version != i+2 || !stored.UpdatedAt.After(created) {
+			t.Errorf("stored = %s version %d updated at %v, want %s version %d", stored.Status, stored.Version, stored.UpdatedAt, step.status, i+2)
+		}
+		if stored.Version != checkout.Version {
+			t.Errorf("returned version %d, stored %d", checkout.Version, stored.Version)
+		}
+	}
+
+	// A refused transition is not written
+	_, err := repositories.UpdateCheckoutWithRetry(ctx, checkouts, uuid, 1, func(checkout *entities.Checkout) error {
+		return checkout.MarkAbandoned(time.Now())
+	})
+	var invalid *errors.InvalidStatusTransitionError
+	if !stdErrors.As(err, &invalid) {
+		t.Errorf("MarkAbandoned of a finalized checkout = %v, want InvalidStatusTransitionError", err)
+	}
+	if stored, _ := checkouts.FindByUUID(ctx, uuid); stored.Version != 4 {
+		t.Errorf("version = %d after a refused transition, want 4", stored.Version)
+	}
+}
+
+func TestCheckoutUpdateRefusesStaleVersions(t *testing.T) {
+	checkouts := NewCheckoutsRepository(NewStore())
+	ctx := context.Background()
+	uuid := "366b643f-3ad1-4204-9655-cdd079d2498c"
+	if err := checkouts.Create(ctx, &entities.Checkout{UUID: uuid, ProductID: 1, Status: entities.CheckoutStatusAccessed, Version: 1}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	first, _ := checkouts.FindByUUID(ctx, uuid)
+	second, _ := checkouts.FindByUUID(ctx, uuid)
+	first.MarkAbandoned(time.Now())
+	if err := checkouts.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	second.MarkSaleFinalized(time.Now())
+	var conflict *errors.ConcurrentModificationError
+	if err := checkouts.Update(ctx, second); !stdErrors.As(err, &conflict) {
+		t.Errorf("stale Update = %v, want ConcurrentModificationError", err)
+	}
+	if stored, _ := checkouts.FindByUUID(ctx, uuid); stored.Status != entities.CheckoutStatusAbandonedCart || stored.Version != 2 {
+		t.Errorf("stored = %s version %d, want %s version 2", stored.Status, stored.Version, entities.CheckoutStatusAbandonedCart)
+	}
+}
@@ -0,0 +1,31 @@
+package memory
+
+import "context"
+
+// JobCursorsRepository keeps job cursors in the store
+type JobCursorsRepository struct {
+	store *Store
+}
+
+func NewJobCursorsRepository(store *Store) *JobCursorsRepository {
+	return &JobCursorsRepository{store: store}
+}
+
+func (r *JobCursorsRepository) Load(ctx context.Context, job string) (string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.jobCursors[job], nil
+}
+
+func (r *JobCursorsRepository) Save(ctx context.Context, job, cursor string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if cursor == "" {
+		delete(r.store.jobCursors, job)
+		return nil
+	}
+	r.store.jobCursors[job] = cursor
+	return nil
+}
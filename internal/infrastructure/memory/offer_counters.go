@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"checkout-go/internal/core/errors"
+)
+
+// OfferCountersRepository keeps shard 0 on the offer and the other shards in
+// the store, like the DynamoDB counter shards table
+type OfferCountersRepository struct {
+	store *Store
+}
+
+func NewOfferCountersRepository(store *Store) *OfferCountersRepository {
+	return &OfferCountersRepository{store: store}
+}
+
+func (r *OfferCountersRepository) AddCheckoutCount(ctx context.Context, offerID, shard int, delta int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if shard > 0 {
+		if r.store.checkoutCountShards[offerID] == nil {
+			r.store.checkoutCountShards[offerID] = make(map[int]int64)
+		}
+		r.store.checkoutCountShards[offerID][shard] += delta
+		return nil
+	}
+
+	offer, exists := r.store.offers[offerID]
+	if !exists {
+		return errors.NewEntityNotFoundError("offer", fmt.Sprintf("offer %d not found", offerID))
+	}
+	offer.CheckoutCount += delta
+	return nil
+}
@@ -0,0 +1,453 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// The memory repositories mirror the DynamoDB implementations: lookups that
+// find nothing return (nil, nil), and every value handed out is a shallow
+// copy, so callers can set its fields without touching the store. Slices,
+// pointed-to values and maps other than the checkout pixel data are shared
+// with the store and must not be changed in place.
+
+// OffersRepository implementation
+type OffersRepository struct {
+	store *Store
+}
+
+func NewOffersRepository(store *Store) *OffersRepository {
+	return &OffersRepository{store: store}
+}
+
+func (r *OffersRepository) FindByUUID(ctx context.Context, uuid string) (*repositories.Offer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, offer := range r.store.offers {
+		if offer.UUID == uuid {
+			return clone(offer), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *OffersRepository) Find(ctx context.Context, id int) (*repositories.Offer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.offers[id]), nil
+}
+
+func (r *OffersRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Offer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return findMany(r.store.offers, ids), nil
+}
+
+func (r *OffersRepository) IncrementCheckoutCount(ctx context.Context, uuid string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, offer := range r.store.offers {
+		if offer.UUID == uuid {
+			offer.CheckoutCount++
+			return nil
+		}
+	}
+	return fmt.Errorf("offer not found")
+}
+
+// CheckoutsRepository implementation
+type CheckoutsRepository struct {
+	store *Store
+}
+
+func NewCheckoutsRepository(store *Store) *CheckoutsRepository {
+	return &CheckoutsRepository{store: store}
+}
+
+func (r *CheckoutsRepository) Create(ctx context.Context, checkout *entities.Checkout) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.checkouts[checkout.UUID]; exists {
+		return fmt.Errorf("failed to create checkout: checkout %s already exists", checkout.UUID)
+	}
+	r.store.putCheckout(cloneCheckout(checkout))
+	return nil
+}
+
+func (r *CheckoutsRepository) FindByUUID(ctx context.Context, uuid string) (*entities.Checkout, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return cloneCheckout(r.store.checkouts[uuid]), nil
+}
+
+func (r *CheckoutsRepository) Update(ctx context.Context, checkout *entities.Checkout) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, exists := r.store.checkouts[checkout.UUID]
+	if !exists || stored.Version != checkout.Version {
+		return errors.NewConcurrentModificationError("checkout", checkout.Version)
+	}
+
+	checkout.UpdateTimestamp()
+	checkout.Version++
+	r.store.putCheckout(cloneCheckout(checkout))
+	return nil
+}
+
+// ProductsRepository implementation
+type ProductsRepository struct {
+	store *Store
+}
+
+func NewProductsRepository(store *Store) *ProductsRepository {
+	return &ProductsRepository{store: store}
+}
+
+func (r *ProductsRepository) Find(ctx context.Context, id int) (*repositories.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.products[id]), nil
+}
+
+func (r *ProductsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return findMany(r.store.products, ids), nil
+}
+
+// UsersRepository implementation
+type UsersRepository struct {
+	store *Store
+}
+
+func NewUsersRepository(store *Store) *UsersRepository {
+	return &UsersRepository{store: store}
+}
+
+func (r *UsersRepository) Find(ctx context.Context, id int) (*repositories.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.users[id]), nil
+}
+
+// CompaniesRepository implementation
+type CompaniesRepository struct {
+	store *Store
+}
+
+func NewCompaniesRepository(store *Store) *CompaniesRepository {
+	return &CompaniesRepository{store: store}
+}
+
+func (r *CompaniesRepository) Find(ctx context.Context, id int) (*repositories.Company, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.companies[id]), nil
+}
+
+// FormatsRepository implementation
+type FormatsRepository struct {
+	store *Store
+}
+
+func NewFormatsRepository(store *Store) *FormatsRepository {
+	return &FormatsRepository{store: store}
+}
+
+func (r *FormatsRepository) Find(ctx context.Context, id int) (*repositories.Format, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.formats[id]), nil
+}
+
+func (r *FormatsRepository) FindMany(ctx context.Context, ids []int) (map[int]*repositories.Format, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return findMany(r.store.formats, ids), nil
+}
+
+// CheckoutConfigsRepository implementation
+type CheckoutConfigsRepository struct {
+	store *Store
+}
+
+func NewCheckoutConfigsRepository(store *Store) *CheckoutConfigsRepository {
+	return &CheckoutConfigsRepository{store: store}
+}
+
+func (r *CheckoutConfigsRepository) Find(ctx context.Context, id int) (*repositories.CheckoutConfig, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.checkoutConfigs[id]), nil
+}
+
+// AffiliatesRepository implementation
+type AffiliatesRepository struct {
+	store *Store
+}
+
+func NewAffiliatesRepository(store *Store) *AffiliatesRepository {
+	return &AffiliatesRepository{store: store}
+}
+
+func (r *AffiliatesRepository) FindByUUID(ctx context.Context, uuid string) (*repositories.Affiliate, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, affiliate := range r.store.affiliates {
+		if affiliate.UUID == uuid {
+			return clone(affiliate), nil
+		}
+	}
+	return nil, nil
+}
+
+// ProductAffiliateSettingsRepository implementation
+type ProductAffiliateSettingsRepository struct {
+	store *Store
+}
+
+func NewProductAffiliateSettingsRepository(store *Store) *ProductAffiliateSettingsRepository {
+	return &ProductAffiliateSettingsRepository{store: store}
+}
+
+func (r *ProductAffiliateSettingsRepository) FindByProduct(ctx context.Context, productID int) (*repositories.ProductAffiliateSettings, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, settings := range sortedByID(r.store.productAffiliateSettings) {
+		if settings.ProductID == productID {
+			result := clone(settings)
+			result.LastOffers = append([]string(nil), settings.LastOffers...)
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// OrderBumpsRepository implementation
+type OrderBumpsRepository struct {
+	store *Store
+}
+
+func NewOrderBumpsRepository(store *Store) *OrderBumpsRepository {
+	return &OrderBumpsRepository{store: store}
+}
+
+func (r *OrderBumpsRepository) FindAllByOffer(ctx context.Context, offerID int) ([]*repositories.OrderBump, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var orderBumps []*repositories.OrderBump
+	for _, orderBump := range sortedByID(r.store.orderBumps) {
+		if orderBump.OfferID == offerID {
+			orderBumps = append(orderBumps, clone(orderBump))
+		}
+	}
+	return orderBumps, nil
+}
+
+// ReviewsRepository implementation
+type ReviewsRepository struct {
+	store *Store
+}
+
+func NewReviewsRepository(store *Store) *ReviewsRepository {
+	return &ReviewsRepository{store: store}
+}
+
+func (r *ReviewsRepository) FindByCheckoutConfig(ctx context.Context, checkoutConfigID int) ([]*repositories.Review, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reviews []*repositories.Review
+	for _, review := range sortedByID(r.store.reviews) {
+		// Filter by status = ACTIVE (same as the DynamoDB repository)
+		if review.CheckoutConfigID == checkoutConfigID && review.Status == repositories.ReviewStatusActive {
+			reviews = append(reviews, clone(review))
+		}
+	}
+	return reviews, nil
+}
+
+// PixelsRepository implementation
+type PixelsRepository struct {
+	store *Store
+}
+
+func NewPixelsRepository(store *Store) *PixelsRepository {
+	return &PixelsRepository{store: store}
+}
+
+func (r *PixelsRepository) FindAllByUserAndProduct(ctx context.Context, userID, productID int) ([]*repositories.Pixel, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var pixels []*repositories.Pixel
+	for _, pixel := range sortedByID(r.store.pixels) {
+		if pixel.UserID == userID && pixel.ProductID == productID {
+			pixels = append(pixels, clone(pixel))
+		}
+	}
+	return pixels, nil
+}
+
+// PlansRepository implementation
+type PlansRepository struct {
+	store *Store
+}
+
+func NewPlansRepository(store *Store) *PlansRepository {
+	return &PlansRepository{store: store}
+}
+
+func (r *PlansRepository) FindByUuid(ctx context.Context, uuid string) (*repositories.Plan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, plan := range r.store.plans {
+		if plan.UUID == uuid {
+			return clone(plan), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *PlansRepository) FindByOffer(ctx context.Context, offerID int) ([]*repositories.Plan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var plans []*repositories.Plan
+	for _, plan := range sortedByID(r.store.plans) {
+		if plan.OfferID == offerID {
+			plans = append(plans, clone(plan))
+		}
+	}
+	return plans, nil
+}
+
+// DiscountsRepository implementation
+type DiscountsRepository struct {
+	store *Store
+}
+
+func NewDiscountsRepository(store *Store) *DiscountsRepository {
+	return &DiscountsRepository{store: store}
+}
+
+func (r *DiscountsRepository) CheckHasDiscounts(ctx context.Context, productID int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, discount := range r.store.discounts {
+		if discount.ProductID == productID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *DiscountsRepository) FindByCode(ctx context.Context, productID int, code string) (*repositories.Discount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, discount := range sortedByID(r.store.discounts) {
+		if discount.ProductID == productID && discount.Code != "" && strings.EqualFold(discount.Code, code) {
+			return clone(discount), nil
+		}
+	}
+	return nil, nil
+}
+
+// CustomersRepository implementation
+type CustomersRepository struct {
+	store *Store
+}
+
+func NewCustomersRepository(store *Store) *CustomersRepository {
+	return &CustomersRepository{store: store}
+}
+
+func (r *CustomersRepository) Find(ctx context.Context, id int) (*repositories.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return clone(r.store.customers[id]), nil
+}
+
+// Helper functions
+
+// clone returns a shallow copy of value, or nil if value is nil
+func clone[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// cloneCheckout copies a checkout including its pixel data map
+func cloneCheckout(checkout *entities.Checkout) *entities.Checkout {
+	copied := clone(checkout)
+	if copied == nil {
+		return nil
+	}
+	// Pending domain events belong to the caller's instance, not to the store
+	copied.PullEvents()
+	if checkout.PixelData == nil {
+		return copied
+	}
+
+	copied.PixelData = make(map[string]interface{}, len(checkout.PixelData))
+	for key, value := range checkout.PixelData {
+		copied.PixelData[key] = value
+	}
+	return copied
+}
+
+// findMany returns copies of the table rows whose primary key is in ids
+func findMany[T any](table map[int]*T, ids []int) map[int]*T {
+	found := make(map[int]*T, len(ids))
+	for _, id := range ids {
+		if value, ok := table[id]; ok {
+			found[id] = clone(value)
+		}
+	}
+	return found
+}
+
+// sortedByID returns the table rows ordered by primary key, giving list
+// queries a stable order like a DynamoDB index would
+func sortedByID[T any](table map[int]*T) []*T {
+	ids := make([]int, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	rows := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, table[id])
+	}
+	return rows
+}
@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/repositories"
+)
+
+// Store holds the in-memory tables shared by the memory repositories
+type Store struct {
+	mu sync.RWMutex
+
+	offers                   map[int]*repositories.Offer
+	products                 map[int]*repositories.Product
+	users                    map[int]*repositories.User
+	companies                map[int]*repositories.Company
+	formats                  map[int]*repositories.Format
+	checkoutConfigs          map[int]*repositories.CheckoutConfig
+	affiliates               map[int]*repositories.Affiliate
+	productAffiliateSettings map[int]*repositories.ProductAffiliateSettings
+	checkouts                map[string]*entities.Checkout
+	orderBumps               map[int]*repositories.OrderBump
+	reviews                  map[int]*repositories.Review
+	pixels                   map[int]*repositories.Pixel
+	plans                    map[int]*repositories.Plan
+	discounts                map[int]*repositories.Discount
+	customers                map[int]*repositories.Customer
+	// checkoutCountShards holds the shards above 0 of the offer counters
+	checkoutCountShards map[int]map[int]int64
+	jobCursors          map[string]string
+
+	checkoutIndexes checkoutIndexes
+}
+
+// NewStore creates an empty in-memory store
+func NewStore() *Store {
+	return &Store{
+		offers:                   make(map[int]*repositories.Offer),
+		products:                 make(map[int]*repositories.Product),
+		users:                    make(map[int]*repositories.User),
+		companies:                make(map[int]*repositories.Company),
+		formats:                  make(map[int]*repositories.Format),
+		checkoutConfigs:          make(map[int]*repositories.CheckoutConfig),
+		affiliates:               make(map[int]*repositories.Affiliate),
+		productAffiliateSettings: make(map[int]*repositories.ProductAffiliateSettings),
+		checkouts:                make(map[string]*entities.Checkout),
+		orderBumps:               make(map[int]*repositories.OrderBump),
+		reviews:                  make(map[int]*repositories.Review),
+		pixels:                   make(map[int]*repositories.Pixel),
+		plans:                    make(map[int]*repositories.Plan),
+		discounts:                make(map[int]*repositories.Discount),
+		customers:                make(map[int]*repositories.Customer),
+		checkoutCountShards:      make(map[int]map[int]int64),
+		jobCursors:               make(map[string]string),
+		checkoutIndexes:          newCheckoutIndexes(),
+	}
+}
+
+// LoadStore creates a store seeded from the fixtures in dir
+func LoadStore(dir string) (*Store, error) {
+	store := NewStore()
+	if err := store.LoadFixtures(dir); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// LoadFixtures seeds the store from a fixtures directory.
+//
+// Each table is read from a file named after its DynamoDB base table
+// (offers.json, checkout_configs.yaml, ...) holding a list of records that
+// use the same field names as the JSON tags of the repository models.
+// Missing files leave the table empty.
+func (s *Store) LoadFixtures(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open fixtures directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("fixtures path %s is not a directory", dir)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, table := range fixtureTables {
+		path, err := findFixtureFile(dir, table.name)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read fixture %s: %w", path, err)
+		}
+
+		jsonData, err := fixtureToJSON(path, data)
+		if err != nil {
+			return fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+
+		if err := table.load(s, jsonData); err != nil {
+			return fmt.Errorf("failed to load fixture %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// fixtureTable binds a fixture file name to the store table it seeds
+type fixtureTable struct {
+	name string
+	load func(s *Store, data []byte) error
+}
+
+var fixtureTables = []fixtureTable{
+	{"offers", func(s *Store, data []byte) error {
+		return loadRecords(data, s.offers, func(o *repositories.Offer) int { return o.ID })
+	}},
+	{"products", func(s *Store, data []byte) error {
+		return loadRecords(data, s.products, func(p *repositories.Product) int { return p.ID })
+	}},
+	{"users", func(s *Store, data []byte) error {
+		return loadRecords(data, s.users, func(u *repositories.User) int { return u.ID })
+	}},
+	{"companies", func(s *Store, data []byte) error {
+		return loadRecords(data, s.companies, func(c *repositories.Company) int { return c.ID })
+	}},
+	{"formats", func(s *Store, data []byte) error {
+		return loadRecords(data, s.formats, func(f *repositories.Format) int { return f.ID })
+	}},
+	{"checkout_configs", func(s *Store, data []byte) error {
+		return loadRecords(data, s.checkoutConfigs, func(c *repositories.CheckoutConfig) int { return c.ID })
+	}},
+	{"affiliates", func(s *Store, data []byte) error {
+		return loadRecords(data, s.affiliates, func(a *repositories.Affiliate) int { return a.ID })
+	}},
+	{"product_affiliate_settings", func(s *Store, data []byte) error {
+		return loadRecords(data, s.productAffiliateSettings, func(p *repositories.ProductAffiliateSettings) int { return p.ID })
+	}},
+	{"checkouts", func(s *Store, data []byte) error {
+		checkouts := make(map[string]*entities.Checkout)
+		if err := loadRecords(data, checkouts, func(c *entities.Checkout) string { return c.UUID }); err != nil {
+			return err
+		}
+		for _, checkout := range checkouts {
+			s.putCheckout(checkout)
+		}
+		return nil
+	}},
+	{"order_bumps", func(s *Store, data []byte) error {
+		return loadRecords(data, s.orderBumps, func(o *repositories.OrderBump) int { return o.ID })
+	}},
+	{"reviews", func(s *Store, data []byte) error {
+		return loadRecords(data, s.reviews, func(r *repositories.Review) int { return r.ID })
+	}},
+	{"pixels", func(s *Store, data []byte) error {
+		return loadRecords(data, s.pixels, func(p *repositories.Pixel) int { return p.ID })
+	}},
+	{"plans", func(s *Store, data []byte) error {
+		return loadRecords(data, s.plans, func(p *repositories.Plan) int { return p.ID })
+	}},
+	{"discounts", func(s *Store, data []byte) error {
+		return loadRecords(data, s.discounts, func(d *repositories.Discount) int { return d.ID })
+	}},
+	{"customers", func(s *Store, data []byte) error {
+		return loadRecords(data, s.customers, func(c *repositories.Customer) int { return c.ID })
+	}},
+}
+
+// loadRecords decodes a JSON list of records into the given table
+func loadRecords[K comparable, T any](data []byte, table map[K]*T, key func(*T) K) error {
+	var records []*T
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		table[key(record)] = record
+	}
+	return nil
+}
+
+// findFixtureFile returns the fixture file for a table, or "" if there is none
+func findFixtureFile(dir, table string) (string, error) {
+	var found string
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		path := filepath.Join(dir, table+ext)
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to stat fixture %s: %w", path, err)
+		}
+		if found != "" {
+			return "", fmt.Errorf("multiple fixture files for table %s: %s and %s", table, found, path)
+		}
+		found = path
+	}
+	return found, nil
+}
+
+// fixtureToJSON normalizes YAML fixtures to JSON so that every table is
+// decoded through the same json tags as the rest of the application
+func fixtureToJSON(path string, data []byte) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return data, nil
+	}
+
+	var records interface{}
+	if err := yaml.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return json.Marshal(records)
+}
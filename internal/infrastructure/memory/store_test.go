@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"checkout-go/internal/repositories"
+)
+
+// writeFixtures writes the given files into a new fixtures directory
+func writeFixtures(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadStoreReadsJSONAndYAML(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{
+		"offers.json": `[
+			{"id": 1, "uuid": "123e4567-e89b-12d3-a456-426614174000", "product_id": 1, "status": "ACTIVE", "price": 19700},
+			null
+		]`,
+		"products.yaml":  "- id: 1\n  uuid: 8a1f0c4e-2b7d-4d3a-9e5f-6c7b8a9d0e1f\n  name: Curso de Go\n  currency: BRL\n",
+		"companies.yml":  "- id: 2\n  name: Acme\n",
+		"checkouts.json": `[{"uuid": "366b643f-3ad1-4204-9655-cdd079d2498c", "offer_id": 1, "product_id": 1, "status": "ACCESSED", "version": 1}]`,
+	})
+
+	store, err := LoadStore(dir)
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	ctx := context.Background()
+
+	offer, _ := NewOffersRepository(store).FindByUUID(ctx, "123e4567-e89b-12d3-a456-426614174000")
+	if offer == nil || offer.ID != 1 || offer.Price != 19700 || offer.Status != "ACTIVE" {
+		t.Errorf("offer = %+v", offer)
+	}
+	if len(store.offers) != 1 {
+		t.Errorf("loaded %d offers, want the null record skipped", len(store.offers))
+	}
+	product, _ := NewProductsRepository(store).Find(ctx, 1)
+	if product == nil || product.Name != "Curso de Go" || product.Currency != "BRL" {
+		t.Errorf("product = %+v", product)
+	}
+	company, _ := NewCompaniesRepository(store).Find(ctx, 2)
+	if company == nil {
+		t.Error("company from the .yml fixture not loaded")
+	}
+
+	// Checkouts are indexed as they are loaded
+	page, err := NewCheckoutsRepository(store).FindByOffer(ctx, 1, repositories.CheckoutQuery{})
+	if err != nil || len(page.Items) != 1 {
+		t.Errorf("FindByOffer = %+v, %v, want the fixture checkout", page, err)
+	}
+
+	// Tables without a fixture file are left empty
+	if user, err := NewUsersRepository(store).Find(ctx, 1); user != nil || err != nil {
+		t.Errorf("user = %+v, %v, want none", user, err)
+	}
+}
+
+func TestLoadStoreRejectsBadFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "json and yaml of one table",
+			files:   map[string]string{"offers.json": "[]", "offers.yaml": "[]"},
+			wantErr: "multiple fixture files for table offers",
+		},
+		{
+			name:    "yaml and yml of one table",
+			files:   map[string]string{"products.yaml": "[]", "products.yml": "[]"},
+			wantErr: "multiple fixture files for table products",
+		},
+		{
+			name:    "malformed json",
+			files:   map[string]string{"offers.json": `[{"id": 1`},
+			wantErr: "offers.json",
+		},
+		{
+			name:    "malformed yaml",
+			files:   map[string]string{"offers.yaml": "- id: [1"},
+			wantErr: "failed to parse fixture",
+		},
+		{
+			name:    "not a list",
+			files:   map[string]string{"offers.json": `{"id": 1}`},
+			wantErr: "failed to load fixture",
+		},
+		{
+			name:    "mistyped field",
+			files:   map[string]string{"offers.yaml": "- id: one\n"},
+			wantErr: "failed to load fixture",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadStore(writeFixtures(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadStore error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadStoreNeedsADirectory(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"offers.json": "[]"})
+
+	if _, err := LoadStore(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadStore of a missing directory returned no error")
+	}
+	if _, err := LoadStore(filepath.Join(dir, "offers.json")); err == nil {
+		t.Error("LoadStore of a file returned no error")
+	}
+
+	store, err := LoadStore(t.TempDir())
+	if err != nil || len(store.offers) != 0 {
+		t.Errorf("LoadStore of an empty directory = %d offers, %v", len(store.offers), err)
+	}
+}
+
+func TestLoadStoreReadsTheRepositoryFixtures(t *testing.T) {
+	store, err := LoadStore(filepath.Join("..", "..", "..", "fixtures"))
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	if offer, _ := NewOffersRepository(store).FindByUUID(context.Background(), "123e4567-e89b-12d3-a456-426614174000"); offer == nil {
+		t.Error("fixture offer not found")
+	}
+}
+
+func TestRepositoriesHandOutCopies(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{
+		"offers.json": `[{"id": 1, "uuid": "123e4567-e89b-12d3-a456-426614174000", "status": "ACTIVE"}]`,
+	})
+	store, err := LoadStore(dir)
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	offers := NewOffersRepository(store)
+	ctx := context.Background()
+
+	offer, _ := offers.Find(ctx, 1)
+	offer.Status = "INACTIVE"
+	many, _ := offers.FindMany(ctx, []int{1})
+	many[1].Status = "INACTIVE"
+
+	if offer, _ := offers.Find(ctx, 1); offer.Status != "ACTIVE" {
+		t.Errorf("stored offer status changed to %s", offer.Status)
+	}
+}
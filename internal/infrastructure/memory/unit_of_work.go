@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/repositories"
+)
+
+// UnitOfWorkFactory starts units of work applied to the store under one lock
+type UnitOfWorkFactory struct {
+	store *Store
+}
+
+func NewUnitOfWorkFactory(store *Store) *UnitOfWorkFactory {
+	return &UnitOfWorkFactory{store: store}
+}
+
+func (f *UnitOfWorkFactory) Begin() repositories.UnitOfWork {
+	return &UnitOfWork{store: f.store}
+}
+
+// UnitOfWork validates every registered write before applying any, which
+// mirrors the all-or-nothing semantics of a DynamoDB transaction
+type UnitOfWork struct {
+	store           *Store
+	checkouts       []*entities.Checkout
+	offerIncrements []int
+}
+
+func (u *UnitOfWork) CreateCheckout(checkout *entities.Checkout) {
+	u.checkouts = append(u.checkouts, cloneCheckout(checkout))
+}
+
+func (u *UnitOfWork) IncrementOfferCheckoutCount(offerID int) {
+	u.offerIncrements = append(u.offerIncrements, offerID)
+}
+
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	created := make(map[string]bool, len(u.checkouts))
+	for _, checkout := range u.checkouts {
+		if _, exists := u.store.checkouts[checkout.UUID]; exists || created[checkout.UUID] {
+			return fmt.Errorf("transaction canceled: checkout %s already exists", checkout.UUID)
+		}
+		created[checkout.UUID] = true
+	}
+	for _, offerID := range u.offerIncrements {
+		if _, exists := u.store.offers[offerID]; !exists {
+			return fmt.Errorf("transaction canceled: offer %d not found", offerID)
+		}
+	}
+
+	for _, checkout := range u.checkouts {
+		u.store.putCheckout(checkout)
+	}
+	for _, offerID := range u.offerIncrements {
+		u.store.offers[offerID].CheckoutCount++
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+)
+
+// Page sizes of checkout listings
+const (
+	DefaultCheckoutPageSize = 50
+	MaxCheckoutPageSize     = 500
+)
+
+// CheckoutQuery filters a checkout listing by creation time and selects a page
+type CheckoutQuery struct {
+	// From and To bound created_at; From is inclusive, To is exclusive and a
+	// zero value leaves that side open
+	From time.Time
+	To   time.Time
+	// Descending lists the newest checkouts first
+	Descending bool
+	// Limit is the page size; 0 means DefaultCheckoutPageSize
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+}
+
+// PageSize returns the effective page size of the query
+func (q CheckoutQuery) PageSize() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultCheckoutPageSize
+	case q.Limit > MaxCheckoutPageSize:
+		return MaxCheckoutPageSize
+	default:
+		return q.Limit
+	}
+}
+
+// CheckoutPage is one page of a checkout listing
+type CheckoutPage struct {
+	Items []*entities.Checkout
+	// NextCursor fetches the next page; it is empty on the last page
+	NextCursor string
+}
+
+// DefaultCheckoutUpdateAttempts is the number of attempts UpdateCheckoutWithRetry
+// makes when called with attempts <= 0
+const DefaultCheckoutUpdateAttempts = 3
+
+// UpdateCheckoutWithRetry loads the checkout, applies change and writes it,
+// starting over from a fresh read when the write loses a race with another
+// update. change must be safe to run more than once; when it returns an error
+// the checkout is not written and the error is returned as is.
+func UpdateCheckoutWithRetry(ctx context.Context, repo CheckoutsRepository, uuid string, attempts int, change func(checkout *entities.Checkout) error) (*entities.Checkout, error) {
+	if attempts <= 0 {
+		attempts = DefaultCheckoutUpdateAttempts
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var checkout *entities.Checkout
+		checkout, err = repo.FindByUUID(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+		if checkout == nil {
+			return nil, errors.NewEntityNotFoundError("checkout", "Checkout não encontrado")
+		}
+
+		if err := change(checkout); err != nil {
+			return nil, err
+		}
+
+		err = repo.Update(ctx, checkout)
+		if err == nil {
+			return checkout, nil
+		}
+
+		var conflict *errors.ConcurrentModificationError
+		if !stdErrors.As(err, &conflict) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
@@ -3,18 +3,23 @@ package repositories
 import (
 	"checkout-go/internal/core/entities"
 	"context"
+	"time"
 )
 
 // OffersRepository defines the interface for offer data access
 type OffersRepository interface {
 	FindByUUID(ctx context.Context, uuid string) (*Offer, error)
 	Find(ctx context.Context, id int) (*Offer, error)
+	// FindMany returns the offers found among ids, keyed by ID
+	FindMany(ctx context.Context, ids []int) (map[int]*Offer, error)
 	IncrementCheckoutCount(ctx context.Context, uuid string) error
 }
 
 // ProductsRepository defines the interface for product data access
 type ProductsRepository interface {
 	Find(ctx context.Context, id int) (*Product, error)
+	// FindMany returns the products found among ids, keyed by ID
+	FindMany(ctx context.Context, ids []int) (map[int]*Product, error)
 }
 
 // UsersRepository defines the interface for user data access
@@ -30,6 +35,8 @@ type CompaniesRepository interface {
 // FormatsRepository defines the interface for format data access
 type FormatsRepository interface {
 	Find(ctx context.Context, id int) (*Format, error)
+	// FindMany returns the formats found among ids, keyed by ID
+	FindMany(ctx context.Context, ids []int) (map[int]*Format, error)
 }
 
 // CheckoutConfigsRepository defines the interface for checkout config data access
@@ -52,6 +59,55 @@ type CheckoutsRepository interface {
 	Create(ctx context.Context, checkout *entities.Checkout) error
 	FindByUUID(ctx context.Context, uuid string) (*entities.Checkout, error)
 	Update(ctx context.Context, checkout *entities.Checkout) error
+	// The listings below are ordered by created_at and filtered by query
+	FindByProduct(ctx context.Context, productID int, query CheckoutQuery) (*CheckoutPage, error)
+	FindByOffer(ctx context.Context, offerID int, query CheckoutQuery) (*CheckoutPage, error)
+	FindByAffiliate(ctx context.Context, affiliateID int, query CheckoutQuery) (*CheckoutPage, error)
+	FindByStatus(ctx context.Context, status entities.CheckoutStatus, query CheckoutQuery) (*CheckoutPage, error)
+}
+
+// UnitOfWork collects writes that must succeed or fail together. Nothing is
+// written until Commit.
+type UnitOfWork interface {
+	// CreateCheckout registers a new checkout; the commit fails if its UUID exists
+	CreateCheckout(checkout *entities.Checkout)
+	// IncrementOfferCheckoutCount registers a checkout counter increment
+	IncrementOfferCheckoutCount(offerID int)
+	// Commit applies every registered write atomically
+	Commit(ctx context.Context) error
+}
+
+// UnitOfWorkFactory starts units of work
+type UnitOfWorkFactory interface {
+	Begin() UnitOfWork
+}
+
+// OfferCountersRepository applies aggregated checkout count increments. Shard
+// 0 is the checkout_count of the offer item itself; hot offers spread their
+// increments over further shards so that no single item takes every write.
+type OfferCountersRepository interface {
+	// AddCheckoutCount adds delta to one shard of the offer counter. It
+	// returns an EntityNotFoundError when the offer does not exist.
+	AddCheckoutCount(ctx context.Context, offerID, shard int, delta int64) error
+}
+
+// CheckoutCounter counts checkouts outside the checkout transaction
+type CheckoutCounter interface {
+	Increment(offerID int)
+}
+
+// CheckoutEventPublisher delivers the domain events of written checkouts
+type CheckoutEventPublisher interface {
+	Publish(ctx context.Context, events []entities.CheckoutEvent) error
+}
+
+// JobCursorsRepository keeps the position of batch jobs between runs, so an
+// interrupted run resumes where it stopped
+type JobCursorsRepository interface {
+	// Load returns the saved cursor of the job, empty when there is none
+	Load(ctx context.Context, job string) (string, error)
+	// Save stores the cursor of the job; an empty cursor clears it
+	Save(ctx context.Context, job, cursor string) error
 }
 
 // OrderBumpsRepository defines the interface for order bump data access
@@ -78,6 +134,14 @@ type PlansRepository interface {
 // DiscountsRepository defines the interface for discount data access
 type DiscountsRepository interface {
 	CheckHasDiscounts(ctx context.Context, productID int) (bool, error)
+	// FindByCode returns the coupon of a product with the given code,
+	// matched case-insensitively, or nil
+	FindByCode(ctx context.Context, productID int, code string) (*Discount, error)
+}
+
+// CustomersRepository defines the interface for customer data access
+type CustomersRepository interface {
+	Find(ctx context.Context, id int) (*Customer, error)
 }
 
 // FileDriver defines the interface for file operations
@@ -100,6 +164,9 @@ type Offer struct {
 	BackRedirectURL        string `json:"back_redirect_url" dynamodb:"back_redirect_url"`
 	BackRedirectURLEnabled bool   `json:"back_redirect_url_enabled" dynamodb:"back_redirect_url_enabled"`
 	OrderBumpsEnabled      bool   `json:"order_bumps_enabled" dynamodb:"order_bumps_enabled"`
+	// CheckoutCount is shard 0 of the checkout counter; the count of a
+	// sharded offer also includes its offer_checkout_count_shards items
+	CheckoutCount int64 `json:"checkout_count" dynamodb:"checkout_count"`
 }
 
 type Product struct {
@@ -127,6 +194,9 @@ type Company struct {
 	ID            int    `json:"id" dynamodb:"id"`
 	Type          string `json:"type" dynamodb:"type"`
 	MovingpayEcID string `json:"movingpay_ec_id" dynamodb:"movingpay_ec_id"`
+	// InstallmentInterestRate is the monthly interest percentage of the
+	// company's installments; nil uses the configured default
+	InstallmentInterestRate *float64 `json:"installment_interest_rate,omitempty" dynamodb:"installment_interest_rate,omitempty"`
 }
 
 type Format struct {
@@ -225,7 +295,7 @@ type Pixel struct {
 type Plan struct {
 	ID                      int     `json:"id" dynamodb:"id"`
 	UUID                    string  `json:"uuid" dynamodb:"uuid"`
-	OfferID                 int     `json:"offer_id" dynamodb:"offer_id"`
+	OfferID                 int     `json:"offer_id" dynamodb:"offerId"` // Fixed to match TypeScript
 	Title                   string  `json:"title" dynamodb:"title"`
 	Tag                     string  `json:"tag" dynamodb:"tag"`
 	Price                   int64   `json:"price" dynamodb:"price"` // stored as cents
@@ -247,6 +317,93 @@ type OrderBump struct {
 	Order            int    `json:"order" dynamodb:"order"`
 }
 
+// Discount represents a product discount, applied as a coupon by the buyers
+// who type its code
+type Discount struct {
+	ID        int    `json:"id" dynamodb:"id"`
+	UUID      string `json:"uuid" dynamodb:"uuid"`
+	ProductID int    `json:"product_id" dynamodb:"product_id"`
+	// Code is the coupon code, stored uppercase
+	Code   string `json:"code,omitempty" dynamodb:"code,omitempty"`
+	Status string `json:"status,omitempty" dynamodb:"status,omitempty"`
+	// Type is DiscountTypePercentage, taking Percentage off the price, or
+	// DiscountTypeFixed, taking Amount off it
+	Type       string  `json:"type,omitempty" dynamodb:"type,omitempty"`
+	Percentage float64 `json:"percentage,omitempty" dynamodb:"percentage,omitempty"`
+	Amount     int64   `json:"amount,omitempty" dynamodb:"amount,omitempty"` // stored as cents
+	// StartsAt and ExpiresAt bound the validity window; nil leaves that
+	// side open
+	StartsAt  *time.Time `json:"starts_at,omitempty" dynamodb:"starts_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" dynamodb:"expires_at,omitempty"`
+	// UsageLimit is the number of sales the coupon can be used in, 0 for no
+	// limit; UsageCount is incremented by the payment service on every sale
+	UsageLimit int `json:"usage_limit,omitempty" dynamodb:"usage_limit,omitempty"`
+	UsageCount int `json:"usage_count,omitempty" dynamodb:"usage_count,omitempty"`
+	// OfferIDs restricts the coupon to some offers of the product; empty
+	// applies it to all of them
+	OfferIDs []int `json:"offer_ids,omitempty" dynamodb:"offer_ids,omitempty"`
+}
+
+// IsActiveAt reports whether the coupon is active and within its validity
+// window at now
+func (d *Discount) IsActiveAt(now time.Time) bool {
+	if d.Status != DiscountStatusActive {
+		return false
+	}
+	if d.StartsAt != nil && now.Before(*d.StartsAt) {
+		return false
+	}
+	return d.ExpiresAt == nil || now.Before(*d.ExpiresAt)
+}
+
+// IsExhausted reports whether the coupon reached its usage limit
+func (d *Discount) IsExhausted() bool {
+	return d.UsageLimit > 0 && d.UsageCount >= d.UsageLimit
+}
+
+// AppliesTo reports whether the coupon can be used on an offer of its
+// product
+func (d *Discount) AppliesTo(offerID int) bool {
+	if len(d.OfferIDs) == 0 {
+		return true
+	}
+	for _, id := range d.OfferIDs {
+		if id == offerID {
+			return true
+		}
+	}
+	return false
+}
+
+// Customer represents a buyer whose details prefill the checkout form
+type Customer struct {
+	ID           int             `json:"id" dynamodb:"id"`
+	UUID         string          `json:"uuid" dynamodb:"uuid"`
+	Name         string          `json:"name" dynamodb:"name"`
+	Email        string          `json:"email" dynamodb:"email"`
+	Phone        string          `json:"phone,omitempty" dynamodb:"phone,omitempty"`
+	Document     string          `json:"document,omitempty" dynamodb:"document,omitempty"`
+	DocumentType string          `json:"document_type,omitempty" dynamodb:"document_type,omitempty"`
+	Address      CustomerAddress `json:"address,omitempty" dynamodb:"address,omitempty"`
+}
+
+// CustomerAddress is the billing address of a customer
+type CustomerAddress struct {
+	ZipCode      string `json:"zip_code,omitempty" dynamodb:"zip_code,omitempty"`
+	Street       string `json:"street,omitempty" dynamodb:"street,omitempty"`
+	Number       string `json:"number,omitempty" dynamodb:"number,omitempty"`
+	Complement   string `json:"complement,omitempty" dynamodb:"complement,omitempty"`
+	Neighborhood string `json:"neighborhood,omitempty" dynamodb:"neighborhood,omitempty"`
+	City         string `json:"city,omitempty" dynamodb:"city,omitempty"`
+	State        string `json:"state,omitempty" dynamodb:"state,omitempty"`
+	Country      string `json:"country,omitempty" dynamodb:"country,omitempty"`
+}
+
+// IsEmpty reports whether no address field is set
+func (a CustomerAddress) IsEmpty() bool {
+	return a == CustomerAddress{}
+}
+
 // Affiliate represents an affiliate
 type Affiliate struct {
 	ID     int    `json:"id" dynamodb:"id"`
@@ -277,4 +434,9 @@ const (
 	CompanyTypeLegalPerson         = "LEGAL_PERSON"
 	CheckoutConfigFaviconTypeFile  = "FILE"
 	OfferBillingTypeOneTime        = "ONE_TIME"
+	DiscountStatusActive           = "ACTIVE"
+	DiscountTypePercentage         = "PERCENTAGE"
+	DiscountTypeFixed              = "FIXED"
+	CustomerDocumentTypeCPF        = "CPF"
+	CustomerDocumentTypeCNPJ       = "CNPJ"
 )
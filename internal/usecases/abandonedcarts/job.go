@@ -0,0 +1,158 @@
+package abandonedcarts
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/repositories"
+)
+
+// JobName identifies the job's cursor in the job cursors repository
+const JobName = "abandoned_carts"
+
+// Clock returns the current time; tests replace it to move time forward
+type Clock func() time.Time
+
+// Options configures the job
+type Options struct {
+	// IdleThreshold is how long an ACCESSED checkout must go without a visit
+	// before it is considered abandoned
+	IdleThreshold time.Duration
+	// BatchSize is the number of checkouts read per page
+	BatchSize int
+}
+
+// Result summarizes a run
+type Result struct {
+	// Scanned is the number of ACCESSED checkouts read
+	Scanned int `json:"scanned"`
+	// Abandoned is the number of checkouts marked ABANDONED_CART
+	Abandoned int `json:"abandoned"`
+	// Skipped counts checkouts visited recently or changed by someone else
+	// since they were read
+	Skipped int `json:"skipped"`
+	// Failed counts checkouts whose update failed; the next run retries them
+	Failed int `json:"failed"`
+	// Completed is false when the run stopped before the last page; the
+	// next run resumes from the saved cursor
+	Completed bool `json:"completed"`
+}
+
+// errNotIdle skips a checkout whose fresh read is no longer abandonable
+var errNotIdle = stdErrors.New("checkout is not idle")
+
+// Job marks idle ACCESSED checkouts as ABANDONED_CART. It walks the status
+// index oldest first and saves its cursor after every page, so an interrupted
+// run resumes where it stopped; a completed run clears the cursor and the next
+// one starts over. Checkouts are re-read before they are marked, so running
+// the job twice, or alongside a visit, never marks a checkout twice or marks
+// one that was just visited. The transition events are published by the
+// checkouts repository.
+type Job struct {
+	checkouts repositories.CheckoutsRepository
+	cursors   repositories.JobCursorsRepository
+	clock     Clock
+	options   Options
+}
+
+// NewJob creates the job; a nil clock uses the system time
+func NewJob(checkouts repositories.CheckoutsRepository, cursors repositories.JobCursorsRepository, clock Clock, options Options) *Job {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &Job{
+		checkouts: checkouts,
+		cursors:   cursors,
+		clock:     clock,
+		options:   options,
+	}
+}
+
+// Run processes pages until the last one or until ctx is done. The returned
+// result is valid even when an error is returned.
+func (j *Job) Run(ctx context.Context) (*Result, error) {
+	result := &Result{}
+
+	cursor, err := j.cursors.Load(ctx, JobName)
+	if err != nil {
+		return result, err
+	}
+	if cursor != "" {
+		log.Printf("Abandoned cart job resuming from saved cursor")
+	}
+
+	// Checkouts created after the cutoff cannot have been idle long enough
+	now := j.clock().UTC()
+	cutoff := now.Add(-j.options.IdleThreshold)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		page, err := j.checkouts.FindByStatus(ctx, entities.CheckoutStatusAccessed, repositories.CheckoutQuery{
+			To:     cutoff,
+			Limit:  j.options.BatchSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to list accessed checkouts: %w", err)
+		}
+
+		for _, checkout := range page.Items {
+			result.Scanned++
+			j.process(ctx, checkout, cutoff, now, result)
+		}
+
+		// A page cut short is read again by the next run
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		cursor = page.NextCursor
+		if err := j.cursors.Save(ctx, JobName, cursor); err != nil {
+			return result, err
+		}
+		if cursor == "" {
+			result.Completed = true
+			return result, nil
+		}
+	}
+}
+
+// process marks one checkout abandoned when it is still idle
+func (j *Job) process(ctx context.Context, checkout *entities.Checkout, cutoff, now time.Time, result *Result) {
+	if !isIdle(checkout, cutoff) {
+		result.Skipped++
+		return
+	}
+
+	_, err := repositories.UpdateCheckoutWithRetry(ctx, j.checkouts, checkout.UUID, 0, func(fresh *entities.Checkout) error {
+		if !isIdle(fresh, cutoff) {
+			return errNotIdle
+		}
+		return fresh.MarkAbandoned(now)
+	})
+
+	var transition *errors.InvalidStatusTransitionError
+	var notFound *errors.EntityNotFoundError
+	switch {
+	case err == nil:
+		result.Abandoned++
+	case stdErrors.Is(err, errNotIdle), stdErrors.As(err, &transition), stdErrors.As(err, &notFound):
+		result.Skipped++
+	default:
+		result.Failed++
+		log.Printf("Failed to mark checkout %s abandoned: %v", checkout.UUID, err)
+	}
+}
+
+// isIdle reports whether an ACCESSED checkout was last visited before cutoff
+func isIdle(checkout *entities.Checkout, cutoff time.Time) bool {
+	return checkout.IsAccessedStatus() && checkout.SaleFinalizedAt == nil && !checkout.UpdatedAt.After(cutoff)
+}
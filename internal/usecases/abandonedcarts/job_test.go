@@ -0,0 +1,170 @@
+package abandonedcarts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/infrastructure/memory"
+	"checkout-go/internal/repositories"
+)
+
+var start = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// fakeClock is a Clock the test moves by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// createCheckout stores a checkout created and last visited the given time
+// before start
+func createCheckout(t *testing.T, checkouts repositories.CheckoutsRepository, uuid string, status entities.CheckoutStatus, created, visited time.Duration) {
+	t.Helper()
+	err := checkouts.Create(context.Background(), &entities.Checkout{
+		UUID:      uuid,
+		ProductID: 1,
+		Status:    status,
+		CreatedAt: start.Add(-created),
+		UpdatedAt: start.Add(-visited),
+	})
+	if err != nil {
+		t.Fatalf("Create %s: %v", uuid, err)
+	}
+}
+
+func statusOf(t *testing.T, checkouts repositories.CheckoutsRepository, uuid string) entities.CheckoutStatus {
+	t.Helper()
+	checkout, err := checkouts.FindByUUID(context.Background(), uuid)
+	if err != nil || checkout == nil {
+		t.Fatalf("FindByUUID %s: %v, %v", uuid, checkout, err)
+	}
+	return checkout.Status
+}
+
+func TestJobMarksIdleCheckoutsAcrossRuns(t *testing.T) {
+	store := memory.NewStore()
+	checkouts := memory.NewCheckoutsRepository(store)
+	cursors := memory.NewJobCursorsRepository(store)
+
+	createCheckout(t, checkouts, "idle-1", entities.CheckoutStatusAccessed, 3*time.Hour, 2*time.Hour)
+	createCheckout(t, checkouts, "idle-2", entities.CheckoutStatusAccessed, 2*time.Hour, time.Hour)
+	createCheckout(t, checkouts, "idle-3", entities.CheckoutStatusAccessed, 90*time.Minute, 31*time.Minute)
+	createCheckout(t, checkouts, "at-threshold", entities.CheckoutStatusAccessed, 40*time.Minute, 30*time.Minute)
+	createCheckout(t, checkouts, "visited", entities.CheckoutStatusAccessed, 3*time.Hour, 10*time.Minute)
+	createCheckout(t, checkouts, "new", entities.CheckoutStatusAccessed, 5*time.Minute, 5*time.Minute)
+	createCheckout(t, checkouts, "sold", entities.CheckoutStatusSaleFinalized, 3*time.Hour, 3*time.Hour)
+
+	clock := &fakeClock{now: start}
+	job := NewJob(checkouts, cursors, clock.Now, Options{IdleThreshold: 30 * time.Minute, BatchSize: 2})
+
+	runs := []struct {
+		name    string
+		advance time.Duration
+		want    Result
+		marked  []string
+	}{
+		{
+			name:   "first run",
+			want:   Result{Scanned: 5, Abandoned: 4, Skipped: 1, Completed: true},
+			marked: []string{"idle-1", "idle-2", "idle-3", "at-threshold"},
+		},
+		{
+			name: "re-run at the same time",
+			want: Result{Scanned: 1, Skipped: 1, Completed: true},
+		},
+		{
+			name:    "re-run an hour later",
+			advance: time.Hour,
+			want:    Result{Scanned: 2, Abandoned: 2, Completed: true},
+			marked:  []string{"visited", "new"},
+		},
+		{
+			name: "nothing left",
+			want: Result{Completed: true},
+		},
+	}
+
+	for _, run := range runs {
+		clock.now = clock.now.Add(run.advance)
+
+		result, err := job.Run(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Run: %v", run.name, err)
+		}
+		if *result != run.want {
+			t.Errorf("%s: result = %+v, want %+v", run.name, *result, run.want)
+		}
+
+		for _, uuid := range run.marked {
+			checkout, _ := checkouts.FindByUUID(context.Background(), uuid)
+			if checkout.Status != entities.CheckoutStatusAbandonedCart {
+				t.Errorf("%s: %s is %s, want %s", run.name, uuid, checkout.Status, entities.CheckoutStatusAbandonedCart)
+			} else if checkout.AbandonedAt == nil || !checkout.AbandonedAt.Equal(clock.now) {
+				t.Errorf("%s: %s abandoned at %v, want %v", run.name, uuid, checkout.AbandonedAt, clock.now)
+			}
+		}
+
+		if cursor, _ := cursors.Load(context.Background(), JobName); cursor != "" {
+			t.Errorf("%s: cursor %q left after a completed run", run.name, cursor)
+		}
+	}
+
+	if status := statusOf(t, checkouts, "sold"); status != entities.CheckoutStatusSaleFinalized {
+		t.Errorf("sold checkout is %s", status)
+	}
+}
+
+// cancellingCheckouts cancels the run once it reads the second page
+type cancellingCheckouts struct {
+	repositories.CheckoutsRepository
+	cancel context.CancelFunc
+	pages  int
+}
+
+func (r *cancellingCheckouts) FindByStatus(ctx context.Context, status entities.CheckoutStatus, query repositories.CheckoutQuery) (*repositories.CheckoutPage, error) {
+	r.pages++
+	if r.pages == 2 {
+		r.cancel()
+	}
+	return r.CheckoutsRepository.FindByStatus(ctx, status, query)
+}
+
+func TestJobResumesInterruptedRun(t *testing.T) {
+	store := memory.NewStore()
+	checkouts := memory.NewCheckoutsRepository(store)
+	cursors := memory.NewJobCursorsRepository(store)
+	for _, uuid := range []string{"idle-1", "idle-2", "idle-3"} {
+		createCheckout(t, checkouts, uuid, entities.CheckoutStatusAccessed, 2*time.Hour, time.Hour)
+	}
+	options := Options{IdleThreshold: 30 * time.Minute, BatchSize: 2}
+	clock := func() time.Time { return start }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := NewJob(&cancellingCheckouts{CheckoutsRepository: checkouts, cancel: cancel}, cursors, clock, options)
+	result, err := interrupted.Run(ctx)
+	if err == nil {
+		t.Fatal("interrupted Run returned no error")
+	}
+	// The second page is cut short and read again by the next run
+	if result.Completed || result.Abandoned != 2 || result.Failed != 1 {
+		t.Errorf("interrupted result = %+v", *result)
+	}
+
+	result, err = NewJob(checkouts, cursors, clock, options).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := (Result{Scanned: 1, Abandoned: 1, Completed: true}); *result != want {
+		t.Errorf("resumed result = %+v, want %+v", *result, want)
+	}
+	for _, uuid := range []string{"idle-1", "idle-2", "idle-3"} {
+		if status := statusOf(t, checkouts, uuid); status != entities.CheckoutStatusAbandonedCart {
+			t.Errorf("%s is %s", uuid, status)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package capturelead
+
+import "time"
+
+// CaptureLeadRequest represents the input for the CaptureLead use case. Nil
+// fields were not sent and keep their stored value.
+type CaptureLeadRequest struct {
+	CheckoutUUID string  `json:"-" validate:"required,uuid"`
+	Name         *string `json:"name,omitempty"`
+	Email        *string `json:"email,omitempty"`
+	Phone        *string `json:"phone,omitempty"`
+	Document     *string `json:"document,omitempty"`
+}
+
+// CaptureLeadResponse represents the output of the CaptureLead use case. It
+// acknowledges the capture without echoing the stored details, which anyone
+// knowing the checkout UUID could otherwise read back.
+type CaptureLeadResponse struct {
+	CheckoutUUID string `json:"checkout_uuid"`
+	Status       string `json:"status"`
+	// Captured names the fields stored on the checkout so far
+	Captured       []string  `json:"captured"`
+	LeadCapturedAt time.Time `json:"lead_captured_at"`
+}
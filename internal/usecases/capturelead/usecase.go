@@ -0,0 +1,169 @@
+package capturelead
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/core/valueobjects"
+	"checkout-go/internal/repositories"
+)
+
+// Limits of the captured fields
+const (
+	minNameLength  = 2
+	maxNameLength  = 255
+	maxEmailLength = 254
+	minPhoneDigits = 10
+	maxPhoneDigits = 15
+)
+
+// UseCase attaches what the buyer typed in the checkout form to the checkout,
+// so abandoned carts carry contact data to recover them with. Fields can be
+// sent one at a time as the buyer fills the form in.
+//
+// Anyone who knows the checkout UUID may write the lead: the buyer has no
+// session yet, and the UUID travels in the checkout parameter and the
+// cookie. The lead is therefore unverified. It only fills the customer_*
+// fields of the checkout, never the customers table, is not echoed back, and
+// a customer linked to the checkout takes precedence over it when the
+// checkout is shown.
+type UseCase struct {
+	checkoutsRepo repositories.CheckoutsRepository
+}
+
+// NewUseCase creates a new CaptureLead use case
+func NewUseCase(checkoutsRepo repositories.CheckoutsRepository) *UseCase {
+	return &UseCase{
+		checkoutsRepo: checkoutsRepo,
+	}
+}
+
+// Execute validates the sent fields and stores them on the checkout. It
+// returns a ValidationError for invalid fields, an EntityNotFoundError for
+// an unknown checkout and a CheckoutFinalizedError once the sale is
+// finalized.
+func (uc *UseCase) Execute(ctx context.Context, req *CaptureLeadRequest) (*CaptureLeadResponse, error) {
+	lead, err := normalizeLead(req)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout, err := repositories.UpdateCheckoutWithRetry(ctx, uc.checkoutsRepo, req.CheckoutUUID, 0, func(checkout *entities.Checkout) error {
+		return checkout.CaptureLead(lead, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildResponse(checkout), nil
+}
+
+// normalizeLead trims the sent fields, lowercases the email and keeps only
+// the digits of the phone and document, collecting every invalid field in a
+// ValidationError
+func normalizeLead(req *CaptureLeadRequest) (entities.CheckoutLead, error) {
+	lead := entities.CheckoutLead{}
+	details := make(map[string]string)
+
+	if req.Name == nil && req.Email == nil && req.Phone == nil && req.Document == nil {
+		details["lead"] = "Informe ao menos um dos campos name, email, phone ou document"
+		return lead, errors.NewValidationError(details)
+	}
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		length := utf8.RuneCountInString(name)
+		if length < minNameLength || length > maxNameLength {
+			details["name"] = "Nome inválido"
+		} else {
+			lead.Name = &name
+		}
+	}
+
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		if !validEmail(email) {
+			details["email"] = "E-mail inválido"
+		} else {
+			lead.Email = &email
+		}
+	}
+
+	if req.Phone != nil {
+		phone := digitsOf(*req.Phone)
+		if len(phone) < minPhoneDigits || len(phone) > maxPhoneDigits {
+			details["phone"] = "Telefone inválido"
+		} else {
+			lead.Phone = &phone
+		}
+	}
+
+	if req.Document != nil {
+		document, err := valueobjects.NewDocument(*req.Document)
+		if err != nil {
+			details["document"] = "CPF ou CNPJ inválido"
+		} else {
+			value := document.Value()
+			lead.Document = &value
+		}
+	}
+
+	if len(details) > 0 {
+		return lead, errors.NewValidationError(details)
+	}
+	return lead, nil
+}
+
+// validEmail accepts a bare address, without a display name or angle brackets
+func validEmail(email string) bool {
+	if email == "" || len(email) > maxEmailLength {
+		return false
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return false
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	return strings.Contains(domain, ".")
+}
+
+func digitsOf(value string) string {
+	var digits strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+func buildResponse(checkout *entities.Checkout) *CaptureLeadResponse {
+	response := &CaptureLeadResponse{
+		CheckoutUUID: checkout.UUID,
+		Status:       string(checkout.Status),
+		Captured:     []string{},
+	}
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"name", checkout.CustomerName},
+		{"email", checkout.CustomerEmail},
+		{"phone", checkout.CustomerPhone},
+		{"document", checkout.CustomerDocument},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			response.Captured = append(response.Captured, field.name)
+		}
+	}
+	if checkout.LeadCapturedAt != nil {
+		response.LeadCapturedAt = *checkout.LeadCapturedAt
+	}
+	return response
+}
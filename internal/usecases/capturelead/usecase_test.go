@@ -0,0 +1,123 @@
+package capturelead
+
+import (
+	stdErrors "errors"
+	"strings"
+	"testing"
+
+	"checkout-go/internal/core/errors"
+)
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"maria.santos@example.com", true},
+		{"maria+loja@example.com.br", true},
+		{"a@b.co", true},
+		{"", false},
+		{"maria", false},
+		{"maria@", false},
+		{"@example.com", false},
+		{"maria@localhost", false},
+		{"maria@@example.com", false},
+		{"maria santos@example.com", false},
+		{"Maria <maria@example.com>", false},
+		{"<maria@example.com>", false},
+		{strings.Repeat("a", 243) + "@example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := validEmail(tt.email); got != tt.want {
+			t.Errorf("validEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestNormalizeLead(t *testing.T) {
+	req := &CaptureLeadRequest{
+		Name:     stringPtr("  Ána   Júlia \t de Araújo "),
+		Email:    stringPtr(" Maria.Santos@Example.COM "),
+		Phone:    stringPtr("+55 (11) 98765-4321"),
+		Document: stringPtr("123.456.789-09"),
+	}
+
+	lead, err := normalizeLead(req)
+	if err != nil {
+		t.Fatalf("normalizeLead: %v", err)
+	}
+	want := map[string]*string{
+		"name":     stringPtr("Ána Júlia de Araújo"),
+		"email":    stringPtr("maria.santos@example.com"),
+		"phone":    stringPtr("5511987654321"),
+		"document": stringPtr("12345678909"),
+	}
+	got := map[string]*string{"name": lead.Name, "email": lead.Email, "phone": lead.Phone, "document": lead.Document}
+	for field, value := range want {
+		if got[field] == nil || *got[field] != *value {
+			t.Errorf("%s = %v, want %q", field, got[field], *value)
+		}
+	}
+
+	// Fields left out stay nil so the stored values are kept
+	lead, err = normalizeLead(&CaptureLeadRequest{Email: stringPtr("maria@example.com")})
+	if err != nil || lead.Email == nil || lead.Name != nil || lead.Phone != nil || lead.Document != nil {
+		t.Errorf("normalizeLead with only an email = %+v, %v", lead, err)
+	}
+}
+
+func TestNormalizeLeadRejectsInvalidFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        CaptureLeadRequest
+		wantFields []string
+	}{
+		{"no field", CaptureLeadRequest{}, []string{"lead"}},
+		{"short name", CaptureLeadRequest{Name: stringPtr(" A ")}, []string{"name"}},
+		{"blank name", CaptureLeadRequest{Name: stringPtr("   ")}, []string{"name"}},
+		{"long name", CaptureLeadRequest{Name: stringPtr(strings.Repeat("á", 256))}, []string{"name"}},
+		{"empty email", CaptureLeadRequest{Email: stringPtr("")}, []string{"email"}},
+		{"display name email", CaptureLeadRequest{Email: stringPtr("Maria <maria@example.com>")}, []string{"email"}},
+		{"short phone", CaptureLeadRequest{Phone: stringPtr("98765-4321")}, []string{"phone"}},
+		{"long phone", CaptureLeadRequest{Phone: stringPtr("+55 11 98765-4321 1234")}, []string{"phone"}},
+		{"bad check digits", CaptureLeadRequest{Document: stringPtr("123.456.789-00")}, []string{"document"}},
+		{
+			name: "every field",
+			req: CaptureLeadRequest{
+				Name:     stringPtr("M"),
+				Email:    stringPtr("maria"),
+				Phone:    stringPtr("123"),
+				Document: stringPtr("abc"),
+			},
+			wantFields: []string{"name", "email", "phone", "document"},
+		},
+		{
+			name:       "valid fields are not reported",
+			req:        CaptureLeadRequest{Name: stringPtr("Maria Santos"), Email: stringPtr("maria")},
+			wantFields: []string{"email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeLead(&tt.req)
+			var validationErr *errors.ValidationError
+			if !stdErrors.As(err, &validationErr) {
+				t.Fatalf("normalizeLead error = %v, want ValidationError", err)
+			}
+			if len(validationErr.Details) != len(tt.wantFields) {
+				t.Errorf("details = %v, want fields %v", validationErr.Details, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := validationErr.Details[field]; !ok {
+					t.Errorf("details = %v, missing %s", validationErr.Details, field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package pricing
+
+// Coupon is the discount of a coupon code: a percentage of the price, or a
+// fixed amount in cents when Percentage is zero
+type Coupon struct {
+	Percentage float64
+	Amount     int64
+}
+
+// ApplyCoupon takes the coupon discount off price, in cents, returning the
+// discounted price and the discount. Percentages are rounded half up to the
+// cent, and the discount never exceeds the price.
+func ApplyCoupon(price int64, coupon Coupon) (final, discount int64) {
+	if price <= 0 {
+		return price, 0
+	}
+	if coupon.Percentage > 0 {
+		return ApplyDiscount(price, coupon.Percentage)
+	}
+
+	discount = coupon.Amount
+	if discount < 0 {
+		discount = 0
+	}
+	if discount > price {
+		discount = price
+	}
+	return price - discount, discount
+}
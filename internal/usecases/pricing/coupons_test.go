@@ -0,0 +1,33 @@
+package pricing
+
+import "testing"
+
+func TestApplyCoupon(t *testing.T) {
+	tests := []struct {
+		name         string
+		price        int64
+		coupon       Coupon
+		wantFinal    int64
+		wantDiscount int64
+	}{
+		{"percentage", 19700, Coupon{Percentage: 10}, 17730, 1970},
+		{"percentage at half a cent", 4990, Coupon{Percentage: 5}, 4740, 250},
+		{"percentage above 100 clamps", 19700, Coupon{Percentage: 120}, 0, 19700},
+		{"percentage wins over amount", 19700, Coupon{Percentage: 10, Amount: 5000}, 17730, 1970},
+		{"fixed amount", 19700, Coupon{Amount: 2000}, 17700, 2000},
+		{"fixed amount above the price clamps", 1500, Coupon{Amount: 2000}, 0, 1500},
+		{"negative amount", 19700, Coupon{Amount: -2000}, 19700, 0},
+		{"negative percentage falls back to the amount", 19700, Coupon{Percentage: -10, Amount: 2000}, 17700, 2000},
+		{"empty coupon", 19700, Coupon{}, 19700, 0},
+		{"free", 0, Coupon{Amount: 2000}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			final, discount := ApplyCoupon(tt.price, tt.coupon)
+			if final != tt.wantFinal || discount != tt.wantDiscount {
+				t.Errorf("ApplyCoupon(%d, %+v) = %d, %d, want %d, %d", tt.price, tt.coupon, final, discount, tt.wantFinal, tt.wantDiscount)
+			}
+		})
+	}
+}
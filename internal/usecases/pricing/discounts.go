@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"math"
+
+	"checkout-go/internal/core/valueobjects"
+)
+
+// Payment methods with automatic discounts
+const (
+	PaymentMethodCreditCard = "credit_card"
+	PaymentMethodPix        = "pix"
+	PaymentMethodBankSlip   = "bank_slip"
+	PaymentMethodNupay      = "nupay"
+	PaymentMethodPicpay     = "picpay"
+	PaymentMethodApplePay   = "apple_pay"
+	PaymentMethodGooglePay  = "google_pay"
+)
+
+// PaymentMethodDiscount is the automatic discount of an enabled payment
+// method
+type PaymentMethodDiscount struct {
+	Method string
+	// Percentage is the discount percentage, e.g. 5 for 5%. It is rounded to
+	// hundredths of a percent and clamped between 0 and 100.
+	Percentage float64
+}
+
+// PaymentPrice is the price of an order paid with one payment method.
+// Amounts are in cents.
+type PaymentPrice struct {
+	Method             string
+	DiscountPercentage float64
+	// Price is the price of the main item after the discount
+	Price int64
+	// Discount is the discount on the main item and the order bumps
+	Discount int64
+	// Total is the main item and the order bumps after the discount
+	Total int64
+}
+
+// CalculatePaymentPrices returns the price of the main item and the selected
+// order bumps, in cents, for each payment method. The discount is rounded
+// per item, so Total is Price plus the ApplyDiscount price of each bump and
+// clients can add a bump to a total without rounding anything themselves.
+func CalculatePaymentPrices(price int64, orderBumps []int64, methods []PaymentMethodDiscount) []PaymentPrice {
+	prices := make([]PaymentPrice, 0, len(methods))
+	for _, method := range methods {
+		final, discount := ApplyDiscount(price, method.Percentage)
+		paymentPrice := PaymentPrice{
+			Method:             method.Method,
+			DiscountPercentage: method.Percentage,
+			Price:              final,
+			Discount:           discount,
+			Total:              final,
+		}
+		for _, orderBump := range orderBumps {
+			bumpFinal, bumpDiscount := ApplyDiscount(orderBump, method.Percentage)
+			paymentPrice.Discount += bumpDiscount
+			paymentPrice.Total += bumpFinal
+		}
+		prices = append(prices, paymentPrice)
+	}
+	return prices
+}
+
+// ApplyDiscount takes percentage off amount, in cents, rounding the
+// discount half up to the cent with valueobjects.Money.Percentage. It
+// returns the discounted amount and the discount.
+func ApplyDiscount(amount int64, percentage float64) (final, discount int64) {
+	if amount <= 0 || percentage <= 0 || math.IsNaN(percentage) {
+		return amount, 0
+	}
+	if percentage > 100 {
+		percentage = 100
+	}
+	discount = valueobjects.NewMoney(amount, "").Percentage(percentage).Amount()
+	return amount - discount, discount
+}
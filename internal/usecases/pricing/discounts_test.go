@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       int64
+		percentage   float64
+		wantFinal    int64
+		wantDiscount int64
+	}{
+		{"whole cents", 19700, 5, 18715, 985},
+		{"half cent rounds up", 4990, 5, 4740, 250},
+		{"below half a cent rounds down", 29, 5, 28, 1},
+		{"one cent at half", 1, 50, 0, 1},
+		{"percentage rounded to hundredths", 10000, 2.504, 9750, 250},
+		{"above 100 clamps", 1000, 150, 0, 1000},
+		{"zero percentage", 1000, 0, 1000, 0},
+		{"negative percentage", 1000, -5, 1000, 0},
+		{"NaN percentage", 1000, math.NaN(), 1000, 0},
+		{"free", 0, 5, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			final, discount := ApplyDiscount(tt.amount, tt.percentage)
+			if final != tt.wantFinal || discount != tt.wantDiscount {
+				t.Errorf("ApplyDiscount(%d, %v) = %d, %d, want %d, %d", tt.amount, tt.percentage, final, discount, tt.wantFinal, tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestCalculatePaymentPricesRoundsPerItem(t *testing.T) {
+	// 5% of each bump is half a cent over, so rounding the sum of the items
+	// instead would give a discount of 1384
+	prices := CalculatePaymentPrices(19700, []int64{4990, 2990}, []PaymentMethodDiscount{
+		{Method: PaymentMethodPix, Percentage: 5},
+		{Method: PaymentMethodCreditCard},
+	})
+
+	want := []PaymentPrice{
+		{Method: PaymentMethodPix, DiscountPercentage: 5, Price: 18715, Discount: 1385, Total: 26295},
+		{Method: PaymentMethodCreditCard, Price: 19700, Discount: 0, Total: 27680},
+	}
+	if len(prices) != len(want) {
+		t.Fatalf("got %d prices, want %d", len(prices), len(want))
+	}
+	for i := range want {
+		if prices[i] != want[i] {
+			t.Errorf("prices[%d] = %+v, want %+v", i, prices[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"math"
+	"math/big"
+)
+
+// MaxInstallments bounds the installment table whatever the checkout config
+// allows
+const MaxInstallments = 24
+
+// rateScale is the denominator of interest rates: rates are handled as whole
+// hundredths of a percent, so 2.99% is 299/10000
+const rateScale = 10000
+
+// InstallmentOptions configures an installment table
+type InstallmentOptions struct {
+	// Limit is the largest installment count offered; values below 1 offer
+	// a single installment
+	Limit int
+	// InterestFree is the largest installment count charged without
+	// interest; a single installment never has interest
+	InterestFree int
+	// MonthlyInterestRate is the monthly interest percentage charged above
+	// InterestFree, e.g. 2.99 for 2.99%. It is rounded to hundredths of a
+	// percent.
+	MonthlyInterestRate float64
+}
+
+// Installment is one row of an installment table. Amounts are in cents.
+type Installment struct {
+	Count int
+	// Amount is the value of every installment but the first
+	Amount int64
+	// FirstAmount is the value of the first installment, which carries the
+	// cents left over when an interest-free price does not divide evenly
+	FirstAmount int64
+	// Total is the sum of all installments
+	Total int64
+	// HasInterest reports whether Total exceeds the price
+	HasInterest bool
+}
+
+// CalculateInstallments returns the installment table of price, in cents,
+// from one installment up to the limit. Interest-free rows split the price
+// exactly, and rows with interest use the Price table (equal installments
+// under compound interest) rounded half up to the cent. A price of zero or
+// less has no table.
+func CalculateInstallments(price int64, options InstallmentOptions) []Installment {
+	if price <= 0 {
+		return nil
+	}
+
+	limit := options.Limit
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > MaxInstallments {
+		limit = MaxInstallments
+	}
+	interestFree := options.InterestFree
+	if interestFree < 1 {
+		interestFree = 1
+	}
+	rate := rateOf(options.MonthlyInterestRate)
+
+	table := make([]Installment, 0, limit)
+	for count := 1; count <= limit; count++ {
+		if count <= interestFree || rate == 0 {
+			table = append(table, splitEvenly(price, count))
+			continue
+		}
+		amount := priceTableAmount(price, count, rate)
+		table = append(table, Installment{
+			Count:       count,
+			Amount:      amount,
+			FirstAmount: amount,
+			Total:       amount * int64(count),
+			HasInterest: true,
+		})
+	}
+	return table
+}
+
+// rateOf converts a percentage into hundredths of a percent, treating
+// negative rates as zero
+func rateOf(percentage float64) int64 {
+	if percentage <= 0 || math.IsNaN(percentage) {
+		return 0
+	}
+	return int64(math.Round(percentage * 100))
+}
+
+// splitEvenly divides price into count installments without interest, the
+// first one carrying the remainder
+func splitEvenly(price int64, count int) Installment {
+	amount := price / int64(count)
+	return Installment{
+		Count:       count,
+		Amount:      amount,
+		FirstAmount: amount + price%int64(count),
+		Total:       price,
+	}
+}
+
+// priceTableAmount returns the installment of price paid in count monthly
+// installments at rate/rateScale interest per month:
+//
+//	price * i * (1+i)^n / ((1+i)^n - 1)
+//
+// With i = rate/rateScale this is computed exactly as
+//
+//	price * rate * (rateScale+rate)^n / (rateScale * ((rateScale+rate)^n - rateScale^n))
+//
+// and rounded half up to the cent.
+func priceTableAmount(price int64, count int, rate int64) int64 {
+	n := big.NewInt(int64(count))
+	growth := new(big.Int).Exp(big.NewInt(rateScale+rate), n, nil)
+	base := new(big.Int).Exp(big.NewInt(rateScale), n, nil)
+
+	numerator := new(big.Int).Mul(big.NewInt(price), big.NewInt(rate))
+	numerator.Mul(numerator, growth)
+	denominator := new(big.Int).Sub(growth, base)
+	denominator.Mul(denominator, big.NewInt(rateScale))
+
+	return roundHalfUp(numerator, denominator)
+}
+
+// roundHalfUp divides two positive integers rounding to the nearest integer,
+// halves up
+func roundHalfUp(numerator, denominator *big.Int) int64 {
+	doubled := new(big.Int).Lsh(numerator, 1)
+	doubled.Add(doubled, denominator)
+	return doubled.Quo(doubled, new(big.Int).Lsh(denominator, 1)).Int64()
+}
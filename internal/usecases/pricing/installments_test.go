@@ -0,0 +1,113 @@
+package pricing
+
+import "testing"
+
+func TestCalculateInstallments(t *testing.T) {
+	tests := []struct {
+		name    string
+		price   int64
+		options InstallmentOptions
+		count   int
+		want    Installment
+	}{
+		{
+			name:    "single installment",
+			price:   19700,
+			options: InstallmentOptions{Limit: 12, InterestFree: 1, MonthlyInterestRate: 2.99},
+			count:   1,
+			want:    Installment{Count: 1, Amount: 19700, FirstAmount: 19700, Total: 19700},
+		},
+		{
+			name:    "interest-free remainder on the first installment",
+			price:   10000,
+			options: InstallmentOptions{Limit: 3, InterestFree: 3},
+			count:   3,
+			want:    Installment{Count: 3, Amount: 3333, FirstAmount: 3334, Total: 10000},
+		},
+		{
+			name:    "interest-free remainder of several cents",
+			price:   19700,
+			options: InstallmentOptions{Limit: 6, InterestFree: 6},
+			count:   6,
+			want:    Installment{Count: 6, Amount: 3283, FirstAmount: 3285, Total: 19700},
+		},
+		{
+			name:    "zero rate splits evenly",
+			price:   10000,
+			options: InstallmentOptions{Limit: 12, InterestFree: 1},
+			count:   12,
+			want:    Installment{Count: 12, Amount: 833, FirstAmount: 837, Total: 10000},
+		},
+		{
+			name:    "negative rate splits evenly",
+			price:   10000,
+			options: InstallmentOptions{Limit: 3, InterestFree: 1, MonthlyInterestRate: -1},
+			count:   3,
+			want:    Installment{Count: 3, Amount: 3333, FirstAmount: 3334, Total: 10000},
+		},
+		{
+			// 1000.00 at 1% a month over 12 months is 88.8488
+			name:    "price table rounds down",
+			price:   100000,
+			options: InstallmentOptions{Limit: 12, InterestFree: 1, MonthlyInterestRate: 1},
+			count:   12,
+			want:    Installment{Count: 12, Amount: 8885, FirstAmount: 8885, Total: 106620, HasInterest: true},
+		},
+		{
+			// 4.10 at 5% a month over 2 months is exactly 2.205
+			name:    "price table rounds half up",
+			price:   410,
+			options: InstallmentOptions{Limit: 2, InterestFree: 1, MonthlyInterestRate: 5},
+			count:   2,
+			want:    Installment{Count: 2, Amount: 221, FirstAmount: 221, Total: 442, HasInterest: true},
+		},
+		{
+			name:    "rate rounded to hundredths of a percent",
+			price:   100000,
+			options: InstallmentOptions{Limit: 12, InterestFree: 1, MonthlyInterestRate: 1.004},
+			count:   12,
+			want:    Installment{Count: 12, Amount: 8885, FirstAmount: 8885, Total: 106620, HasInterest: true},
+		},
+		{
+			name:    "interest above the interest-free count",
+			price:   19700,
+			options: InstallmentOptions{Limit: 12, InterestFree: 6, MonthlyInterestRate: 2.99},
+			count:   12,
+			want:    Installment{Count: 12, Amount: 1978, FirstAmount: 1978, Total: 23736, HasInterest: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := CalculateInstallments(tt.price, tt.options)
+			if len(table) < tt.count {
+				t.Fatalf("table has %d rows, want at least %d", len(table), tt.count)
+			}
+			if got := table[tt.count-1]; got != tt.want {
+				t.Errorf("row %d = %+v, want %+v", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateInstallmentsLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		price   int64
+		options InstallmentOptions
+		want    int
+	}{
+		{"free", 0, InstallmentOptions{Limit: 12}, 0},
+		{"negative price", -100, InstallmentOptions{Limit: 12}, 0},
+		{"limit below one", 10000, InstallmentOptions{Limit: 0}, 1},
+		{"limit above the maximum", 10000, InstallmentOptions{Limit: 48}, MaxInstallments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(CalculateInstallments(tt.price, tt.options)); got != tt.want {
+				t.Errorf("table has %d rows, want %d", got, tt.want)
+			}
+		})
+	}
+}
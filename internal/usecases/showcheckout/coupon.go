@@ -0,0 +1,223 @@
+package showcheckout
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/core/valueobjects"
+	"checkout-go/internal/repositories"
+	"checkout-go/internal/usecases/pricing"
+)
+
+// ApplyCouponRequest represents the input for the ApplyCoupon use case
+type ApplyCouponRequest struct {
+	CheckoutUUID string `json:"-" validate:"required,uuid"`
+	Code         string `json:"code" validate:"required,max=64"`
+	// OrderBumps are the UUIDs of the order bumps the buyer selected, priced
+	// together with the offer
+	OrderBumps []string `json:"order_bumps,omitempty" validate:"max=20,dive,uuid"`
+}
+
+// ApplyCouponResponse represents the output of the ApplyCoupon use case
+type ApplyCouponResponse struct {
+	CheckoutUUID  string                 `json:"checkout_uuid"`
+	Coupon        ResponseCoupon         `json:"coupon"`
+	PaymentPrices []ResponsePaymentPrice `json:"payment_prices,omitempty"`
+	Installments  []ResponseInstallment  `json:"installments,omitempty"`
+}
+
+// ApplyCoupon validates a coupon code for the offer of a checkout, records
+// it on the checkout and returns the order prices with the coupon discount.
+// It returns an InvalidCouponError telling why a code cannot be used, an
+// EntityNotFoundError for an unknown checkout, a CheckoutFinalizedError
+// once the sale is finalized and, like Execute, a DontWorryError when the
+// offer or its product is no longer on sale.
+func (uc *UseCase) ApplyCoupon(ctx context.Context, req *ApplyCouponRequest) (*ApplyCouponResponse, error) {
+	code := normalizeCouponCode(req.Code)
+	if code == "" {
+		return nil, errors.NewValidationError(map[string]string{"code": "Informe o código do cupom"})
+	}
+
+	checkout, err := uc.checkoutsRepo.FindByUUID(ctx, req.CheckoutUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checkout: %w", err)
+	}
+	if checkout == nil || checkout.OfferID == nil {
+		return nil, errors.NewEntityNotFoundError("checkout", "Checkout não encontrado")
+	}
+	if checkout.IsSaleFinalizedStatus() {
+		return nil, errors.NewCheckoutFinalizedError(checkout.UUID)
+	}
+
+	offer, err := uc.offersRepo.Find(ctx, *checkout.OfferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find offer: %w", err)
+	}
+	if offer == nil || offer.Status != repositories.OfferStatusActive || offer.IsTemporary {
+		return nil, errors.NewDontWorryError(StringPtr("Oferta não encontrada"))
+	}
+
+	product, err := uc.productsRepo.Find(ctx, offer.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.Status != repositories.ProductStatusActive || product.EvaluationStatus == repositories.ProductEvaluationStatusRefused {
+		return nil, errors.NewDontWorryError(StringPtr("Produto não encontrado"))
+	}
+
+	discount, err := uc.findCoupon(ctx, code, product.ID, offer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkoutConfig, err := uc.checkoutConfigsRepo.Find(ctx, offer.CheckoutConfigID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checkout config: %w", err)
+	}
+	if checkoutConfig == nil {
+		return nil, errors.NewDontWorryError(StringPtr("Configuração de checkout não encontrada"))
+	}
+
+	company, err := uc.companiesRepo.Find(ctx, product.CompanyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company: %w", err)
+	}
+	if company == nil {
+		return nil, errors.NewDontWorryError(StringPtr("Empresa não encontrada"))
+	}
+
+	plans, err := uc.plansRepo.FindByOffer(ctx, offer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plans: %w", err)
+	}
+
+	orderBumps, err := uc.selectedOrderBumps(ctx, offer, req.OrderBumps)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout, err = repositories.UpdateCheckoutWithRetry(ctx, uc.checkoutsRepo, checkout.UUID, 0, func(checkout *entities.Checkout) error {
+		return checkout.ApplyCoupon(discount.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	price := valueobjects.NewMoney(chargedPrice(offer, selectedPlan(plans, checkout.PlanUUID)), product.Currency)
+	coupon := validCoupon(discount, price)
+	response := &ApplyCouponResponse{
+		CheckoutUUID: checkout.UUID,
+		Coupon:       *coupon,
+	}
+	if offer.IsFree {
+		return response, nil
+	}
+
+	var config CheckoutConfig
+	uc.setPaymentOptions(&config, offer, checkoutConfig, company)
+	price, err = price.Sub(*coupon.Discount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply coupon discount: %w", err)
+	}
+	response.PaymentPrices, response.Installments = uc.quoteOrder(price, orderBumps, paymentMethodDiscounts(&config), checkoutConfig, company)
+
+	return response, nil
+}
+
+// findCoupon returns the coupon of the product with the given code, or an
+// InvalidCouponError telling the buyer why it cannot be used on the offer
+func (uc *UseCase) findCoupon(ctx context.Context, code string, productID, offerID int) (*repositories.Discount, error) {
+	discount, err := uc.discountsRepo.FindByCode(ctx, productID, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find coupon: %w", err)
+	}
+
+	switch {
+	case discount == nil:
+		return nil, errors.NewInvalidCouponError(code, "Cupom não encontrado")
+	case !discount.IsActiveAt(time.Now()):
+		return nil, errors.NewInvalidCouponError(code, "Cupom expirado")
+	case discount.IsExhausted():
+		return nil, errors.NewInvalidCouponError(code, "Cupom esgotado")
+	case !discount.AppliesTo(offerID):
+		return nil, errors.NewInvalidCouponError(code, "Cupom não válido para esta oferta")
+	}
+	return discount, nil
+}
+
+// buildCoupon returns the coupon of the response from the outcome of
+// findCoupon, or nil when the coupon could not be checked
+func buildCoupon(code string, discount *repositories.Discount, err error, price valueobjects.Money) *ResponseCoupon {
+	if err == nil {
+		return validCoupon(discount, price)
+	}
+
+	var invalidCoupon *errors.InvalidCouponError
+	if !stdErrors.As(err, &invalidCoupon) {
+		log.Printf("Failed to check coupon %s: %v", code, err)
+		return nil
+	}
+	return &ResponseCoupon{
+		Code:    code,
+		Message: StringPtr(invalidCoupon.Message),
+	}
+}
+
+// validCoupon returns the coupon of the response with its discount on price
+func validCoupon(discount *repositories.Discount, price valueobjects.Money) *ResponseCoupon {
+	coupon := pricing.Coupon{Amount: discount.Amount}
+	if discount.Type == repositories.DiscountTypePercentage {
+		coupon = pricing.Coupon{Percentage: discount.Percentage}
+	}
+	_, amount := pricing.ApplyCoupon(price.Amount(), coupon)
+
+	couponDiscount := valueobjects.NewMoney(amount, price.Currency())
+	return &ResponseCoupon{
+		Code:       discount.Code,
+		Valid:      true,
+		Type:       discount.Type,
+		Percentage: coupon.Percentage,
+		Discount:   &couponDiscount,
+	}
+}
+
+// selectedOrderBumps returns the prices of the order bumps of the offer
+// named by uuids, or a ValidationError when one is not offered
+func (uc *UseCase) selectedOrderBumps(ctx context.Context, offer *repositories.Offer, uuids []string) ([]valueobjects.Money, error) {
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+
+	orderBumps, err := uc.buildOrderBumps(ctx, offer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find order bumps: %w", err)
+	}
+
+	prices := make([]valueobjects.Money, 0, len(uuids))
+	for _, uuid := range uuids {
+		found := false
+		for _, orderBump := range orderBumps {
+			if orderBump.UUID == uuid {
+				prices = append(prices, orderBump.Price)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.NewValidationError(map[string]string{"order_bumps": "Order bump não encontrado: " + uuid})
+		}
+	}
+	return prices, nil
+}
+
+// normalizeCouponCode trims the code typed by the buyer and uppercases it,
+// the way codes are stored
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
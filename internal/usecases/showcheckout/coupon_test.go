@@ -0,0 +1,181 @@
+package showcheckout
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/errors"
+	"checkout-go/internal/core/valueobjects"
+	"checkout-go/internal/infrastructure/memory"
+	"checkout-go/internal/repositories"
+)
+
+// discountsByCode is a DiscountsRepository holding the coupons of one
+// product
+type discountsByCode struct {
+	discounts map[string]*repositories.Discount
+	err       error
+}
+
+func (r *discountsByCode) CheckHasDiscounts(ctx context.Context, productID int) (bool, error) {
+	return len(r.discounts) > 0, r.err
+}
+
+func (r *discountsByCode) FindByCode(ctx context.Context, productID int, code string) (*repositories.Discount, error) {
+	return r.discounts[code], r.err
+}
+
+func TestFindCoupon(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	coupon := func(code string, change func(*repositories.Discount)) *repositories.Discount {
+		discount := &repositories.Discount{
+			Code:       code,
+			Status:     repositories.DiscountStatusActive,
+			Type:       repositories.DiscountTypePercentage,
+			Percentage: 10,
+		}
+		if change != nil {
+			change(discount)
+		}
+		return discount
+	}
+
+	uc := &UseCase{discountsRepo: &discountsByCode{discounts: map[string]*repositories.Discount{
+		"BEMVINDO10": coupon("BEMVINDO10", nil),
+		"LIMITED":    coupon("LIMITED", func(d *repositories.Discount) { d.UsageLimit, d.UsageCount = 10, 9 }),
+		"INACTIVE":   coupon("INACTIVE", func(d *repositories.Discount) { d.Status = "INACTIVE" }),
+		"EXPIRED":    coupon("EXPIRED", func(d *repositories.Discount) { d.ExpiresAt = &past }),
+		"UPCOMING":   coupon("UPCOMING", func(d *repositories.Discount) { d.StartsAt = &future }),
+		"USEDUP":     coupon("USEDUP", func(d *repositories.Discount) { d.UsageLimit, d.UsageCount = 10, 10 }),
+		"OTHEROFFER": coupon("OTHEROFFER", func(d *repositories.Discount) { d.OfferIDs = []int{2, 3} }),
+		"THISOFFER":  coupon("THISOFFER", func(d *repositories.Discount) { d.OfferIDs = []int{1, 2} }),
+	}}}
+
+	tests := []struct {
+		code        string
+		wantMessage string
+	}{
+		{"BEMVINDO10", ""},
+		{"LIMITED", ""},
+		{"THISOFFER", ""},
+		{"UNKNOWN", "Cupom não encontrado"},
+		{"INACTIVE", "Cupom expirado"},
+		{"EXPIRED", "Cupom expirado"},
+		{"UPCOMING", "Cupom expirado"},
+		{"USEDUP", "Cupom esgotado"},
+		{"OTHEROFFER", "Cupom não válido para esta oferta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			discount, err := uc.findCoupon(context.Background(), tt.code, 1, 1)
+			if tt.wantMessage == "" {
+				if err != nil || discount == nil || discount.Code != tt.code {
+					t.Errorf("findCoupon = %v, %v, want coupon %s", discount, err, tt.code)
+				}
+				return
+			}
+
+			var invalidCoupon *errors.InvalidCouponError
+			if !stdErrors.As(err, &invalidCoupon) {
+				t.Fatalf("findCoupon error = %v, want InvalidCouponError", err)
+			}
+			if invalidCoupon.Message != tt.wantMessage || invalidCoupon.CouponCode != tt.code {
+				t.Errorf("findCoupon error = %q for %s, want %q for %s", invalidCoupon.Message, invalidCoupon.CouponCode, tt.wantMessage, tt.code)
+			}
+		})
+	}
+}
+
+func TestFindCouponRepositoryError(t *testing.T) {
+	uc := &UseCase{discountsRepo: &discountsByCode{err: stdErrors.New("throttled")}}
+
+	_, err := uc.findCoupon(context.Background(), "BEMVINDO10", 1, 1)
+	var invalidCoupon *errors.InvalidCouponError
+	if err == nil || stdErrors.As(err, &invalidCoupon) {
+		t.Errorf("findCoupon error = %v, want the repository error", err)
+	}
+
+	// The response leaves the coupon out rather than calling it invalid
+	if coupon := buildCoupon("BEMVINDO10", nil, err, valueobjects.NewMoney(19700, "BRL")); coupon != nil {
+		t.Errorf("buildCoupon = %+v, want nil", coupon)
+	}
+}
+
+// singleOffer is an OffersRepository finding one offer under any ID
+type singleOffer struct {
+	repositories.OffersRepository
+	offer *repositories.Offer
+}
+
+func (r *singleOffer) Find(ctx context.Context, id int) (*repositories.Offer, error) {
+	return r.offer, nil
+}
+
+// singleProduct is a ProductsRepository finding one product under any ID
+type singleProduct struct {
+	repositories.ProductsRepository
+	product *repositories.Product
+}
+
+func (r *singleProduct) Find(ctx context.Context, id int) (*repositories.Product, error) {
+	return r.product, nil
+}
+
+func TestApplyCouponRefusesOffersNoLongerOnSale(t *testing.T) {
+	checkouts := memory.NewCheckoutsRepository(memory.NewStore())
+	offerID := 1
+	err := checkouts.Create(context.Background(), &entities.Checkout{
+		UUID:      "366b643f-3ad1-4204-9655-cdd079d2498c",
+		OfferID:   &offerID,
+		ProductID: 1,
+		Status:    entities.CheckoutStatusAccessed,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		offer      func(*repositories.Offer)
+		product    func(*repositories.Product)
+		wantReason string
+	}{
+		{"inactive offer", func(o *repositories.Offer) { o.Status = "INACTIVE" }, nil, "Oferta não encontrada"},
+		{"temporary offer", func(o *repositories.Offer) { o.IsTemporary = true }, nil, "Oferta não encontrada"},
+		{"inactive product", nil, func(p *repositories.Product) { p.Status = "INACTIVE" }, "Produto não encontrado"},
+		{"refused product", nil, func(p *repositories.Product) { p.EvaluationStatus = repositories.ProductEvaluationStatusRefused }, "Produto não encontrado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := &repositories.Offer{ID: 1, ProductID: 1, Status: repositories.OfferStatusActive}
+			product := &repositories.Product{ID: 1, Status: repositories.ProductStatusActive, Currency: "BRL"}
+			if tt.offer != nil {
+				tt.offer(offer)
+			}
+			if tt.product != nil {
+				tt.product(product)
+			}
+			uc := &UseCase{
+				checkoutsRepo: checkouts,
+				offersRepo:    &singleOffer{offer: offer},
+				productsRepo:  &singleProduct{product: product},
+				discountsRepo: &discountsByCode{err: stdErrors.New("coupon looked up")},
+			}
+
+			_, err := uc.ApplyCoupon(context.Background(), &ApplyCouponRequest{CheckoutUUID: "366b643f-3ad1-4204-9655-cdd079d2498c", Code: "BEMVINDO10"})
+			var dontWorry *errors.DontWorryError
+			if !stdErrors.As(err, &dontWorry) {
+				t.Fatalf("ApplyCoupon error = %v, want DontWorryError", err)
+			}
+			if reason := dontWorry.InternalReason; reason == nil || *reason != tt.wantReason {
+				t.Errorf("ApplyCoupon error = %v, want reason %q", err, tt.wantReason)
+			}
+		})
+	}
+}
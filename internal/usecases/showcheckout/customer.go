@@ -0,0 +1,207 @@
+package showcheckout
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/valueobjects"
+	"checkout-go/internal/repositories"
+)
+
+// The customer linked to a checkout prefills the form. A checkout is linked
+// by a prefill token; a checkout without a customer is prefilled with the
+// buyer's details captured from the form, if any. Only viewers who arrive
+// through a signed link, i.e. with a prefill or recovery token sent to the
+// buyer, see the details in full. Anyone else who reaches the checkout, e.g.
+// through the cookie or the checkout parameter, gets the first name, a hint
+// of the email and phone, the last digits of the document and the city of
+// the address.
+
+// prefillCustomerID returns the customer named by the prefill token of the
+// request, or nil when there is no valid token
+func (uc *UseCase) prefillCustomerID(req *ShowCheckoutRequest) *int {
+	if uc.options.RecoveryTokens == nil || req.PrefillToken == nil {
+		return nil
+	}
+
+	customerID, err := uc.options.RecoveryTokens.VerifyPrefill(*req.PrefillToken, time.Now())
+	if err != nil {
+		log.Printf("Ignoring prefill link for offer %s: %v", req.OfferUUID, err)
+		return nil
+	}
+	return &customerID
+}
+
+// buildCustomer returns the customer linked to the checkout, or the lead
+// captured on it, masked unless the viewer came through a signed link
+func (uc *UseCase) buildCustomer(ctx context.Context, checkout *entities.Checkout, signedLink bool) (*ResponseCustomer, error) {
+	customer := leadCustomer(checkout)
+	if checkout.CustomerID != nil {
+		linked, err := uc.customersRepo.Find(ctx, *checkout.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if linked != nil {
+			customer = linked
+		}
+	}
+	if customer == nil {
+		return nil, nil
+	}
+
+	if signedLink {
+		return fullCustomer(customer), nil
+	}
+	return maskedCustomer(customer), nil
+}
+
+// leadCustomer returns the buyer's details captured on the checkout as a
+// customer without UUID, or nil when no lead was captured
+func leadCustomer(checkout *entities.Checkout) *repositories.Customer {
+	if checkout.LeadCapturedAt == nil {
+		return nil
+	}
+
+	customer := &repositories.Customer{
+		Name:     valueOf(checkout.CustomerName),
+		Email:    valueOf(checkout.CustomerEmail),
+		Phone:    valueOf(checkout.CustomerPhone),
+		Document: valueOf(checkout.CustomerDocument),
+	}
+	if document, err := valueobjects.NewDocument(customer.Document); err == nil {
+		customer.DocumentType = document.Type()
+	}
+	return customer
+}
+
+func fullCustomer(customer *repositories.Customer) *ResponseCustomer {
+	response := &ResponseCustomer{
+		UUID:         customer.UUID,
+		Name:         customer.Name,
+		Email:        customer.Email,
+		Phone:        optionalString(customer.Phone),
+		Document:     optionalString(customer.Document),
+		DocumentType: optionalString(customer.DocumentType),
+	}
+
+	if address := customer.Address; !address.IsEmpty() {
+		response.Address = &ResponseCustomerAddress{
+			ZipCode:      address.ZipCode,
+			Street:       optionalString(address.Street),
+			Number:       optionalString(address.Number),
+			Complement:   optionalString(address.Complement),
+			Neighborhood: optionalString(address.Neighborhood),
+			City:         address.City,
+			State:        address.State,
+			Country:      address.Country,
+		}
+	}
+	return response
+}
+
+func maskedCustomer(customer *repositories.Customer) *ResponseCustomer {
+	response := &ResponseCustomer{
+		UUID:         customer.UUID,
+		Name:         firstName(customer.Name),
+		Email:        maskEmail(customer.Email),
+		Phone:        optionalString(maskDigits(customer.Phone, 4)),
+		Document:     optionalString(maskDigits(customer.Document, 2)),
+		DocumentType: optionalString(customer.DocumentType),
+		Masked:       true,
+	}
+
+	// The street part of the address is left out entirely
+	if address := customer.Address; !address.IsEmpty() {
+		response.Address = &ResponseCustomerAddress{
+			ZipCode: maskZipCode(address.ZipCode),
+			City:    address.City,
+			State:   address.State,
+			Country: address.Country,
+		}
+	}
+	return response
+}
+
+// firstName returns the first word of a full name
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// maskEmail keeps the domain and at most two characters of the local part,
+// never more than half of it: maria.santos@example.com becomes
+// ma***@example.com and a@example.com becomes ***@example.com
+func maskEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return "***"
+	}
+
+	runes := []rune(local)
+	visible := min(2, len(runes)/2)
+	return string(runes[:visible]) + "***@" + domain
+}
+
+// maskDigits replaces every digit but the last keep ones with '*', leaving
+// the formatting characters in place. Short values keep at most half of
+// their digits
+func maskDigits(value string, keep int) string {
+	digits := 0
+	for _, r := range value {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	keep = min(keep, digits/2)
+
+	masked := []rune(value)
+	for i, r := range masked {
+		if !unicode.IsDigit(r) {
+			continue
+		}
+		if digits > keep {
+			masked[i] = '*'
+		}
+		digits--
+	}
+	return string(masked)
+}
+
+// maskZipCode keeps the region part of a CEP: 01310-100 becomes 01310-***
+func maskZipCode(zipCode string) string {
+	masked := []rune(zipCode)
+	seen := 0
+	for i, r := range masked {
+		if !unicode.IsDigit(r) {
+			continue
+		}
+		seen++
+		if seen > 5 {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,145 @@
+package showcheckout
+
+import (
+	"testing"
+
+	"checkout-go/internal/repositories"
+)
+
+func TestFirstName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Maria Santos", "Maria"},
+		{"  Ana  ", "Ana"},
+		{"Ána Júlia de Araújo", "Ána"},
+		{"José Silva", "José"},
+		{"", ""},
+		{"   ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := firstName(tt.name); got != tt.want {
+			t.Errorf("firstName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"maria.santos@example.com", "ma***@example.com"},
+		{"abcd@example.com", "ab***@example.com"},
+		{"abc@example.com", "a***@example.com"},
+		{"ab@example.com", "a***@example.com"},
+		{"a@example.com", "***@example.com"},
+		{"@example.com", "***@example.com"},
+		{"joão@exemplo.com.br", "jo***@exemplo.com.br"},
+		{"çé@exemplo.com.br", "ç***@exemplo.com.br"},
+		{"maria.santos", "***"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := maskEmail(tt.email); got != tt.want {
+			t.Errorf("maskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestMaskDigits(t *testing.T) {
+	tests := []struct {
+		value string
+		keep  int
+		want  string
+	}{
+		{"123.456.789-09", 2, "***.***.***-09"},
+		{"12.345.678/0001-95", 2, "**.***.***/****-95"},
+		{"+55 (11) 98765-4321", 4, "+** (**) *****-4321"},
+		{"11987654321", 4, "*******4321"},
+		{"12345", 4, "***45"},
+		{"123", 2, "**3"},
+		{"12", 2, "*2"},
+		{"1", 2, "*"},
+		{"abc", 2, "abc"},
+		{"", 2, ""},
+		{"١٢٣٤٥٦", 2, "****٥٦"},
+	}
+
+	for _, tt := range tests {
+		if got := maskDigits(tt.value, tt.keep); got != tt.want {
+			t.Errorf("maskDigits(%q, %d) = %q, want %q", tt.value, tt.keep, got, tt.want)
+		}
+	}
+}
+
+func TestMaskZipCode(t *testing.T) {
+	tests := []struct {
+		zipCode string
+		want    string
+	}{
+		{"01310-100", "01310-***"},
+		{"01310100", "01310***"},
+		{"01310", "01310"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := maskZipCode(tt.zipCode); got != tt.want {
+			t.Errorf("maskZipCode(%q) = %q, want %q", tt.zipCode, got, tt.want)
+		}
+	}
+}
+
+func TestMaskedCustomer(t *testing.T) {
+	customer := &repositories.Customer{
+		UUID:         "0f5c3a4e-8d2b-4c1a-9e7f-6b5a4c3d2e1f",
+		Name:         "Ána Júlia de Araújo",
+		Email:        "ana.julia@example.com",
+		Phone:        "+55 11 98765-4321",
+		Document:     "123.456.789-09",
+		DocumentType: "CPF",
+		Address: repositories.CustomerAddress{
+			ZipCode:      "01310-100",
+			Street:       "Avenida Paulista",
+			Number:       "1000",
+			Complement:   "Apto 12",
+			Neighborhood: "Bela Vista",
+			City:         "São Paulo",
+			State:        "SP",
+			Country:      "BR",
+		},
+	}
+
+	masked := maskedCustomer(customer)
+	if !masked.Masked || masked.Name != "Ána" || masked.Email != "an***@example.com" {
+		t.Errorf("masked = %q %q masked %v", masked.Name, masked.Email, masked.Masked)
+	}
+	if valueOf(masked.Phone) != "+** ** *****-4321" || valueOf(masked.Document) != "***.***.***-09" || valueOf(masked.DocumentType) != "CPF" {
+		t.Errorf("masked phone %q document %q (%q)", valueOf(masked.Phone), valueOf(masked.Document), valueOf(masked.DocumentType))
+	}
+	address := masked.Address
+	if address == nil {
+		t.Fatal("masked address is nil")
+	}
+	if address.Street != nil || address.Number != nil || address.Complement != nil || address.Neighborhood != nil {
+		t.Errorf("masked address keeps the street: %+v", *address)
+	}
+	if address.ZipCode != "01310-***" || address.City != "São Paulo" || address.State != "SP" || address.Country != "BR" {
+		t.Errorf("masked address = %+v", *address)
+	}
+}
+
+func TestMaskedCustomerWithoutDetails(t *testing.T) {
+	masked := maskedCustomer(&repositories.Customer{})
+
+	if !masked.Masked || masked.Name != "" || masked.Email != "" {
+		t.Errorf("masked = %q %q masked %v", masked.Name, masked.Email, masked.Masked)
+	}
+	if masked.Phone != nil || masked.Document != nil || masked.DocumentType != nil || masked.Address != nil {
+		t.Errorf("masked = %+v, want no phone, document or address", *masked)
+	}
+}
@@ -0,0 +1,112 @@
+package showcheckout
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LoadBudgets bounds how long Execute waits for each dependency.
+//
+// Every lookup runs under a context derived from the request context, so the
+// request deadline always wins over a larger budget. A zero budget (the zero
+// value of LoadBudgets) means the lookup is bounded by the request context only.
+type LoadBudgets struct {
+	// Required covers the lookups the checkout cannot be shown without
+	// (offer, product, user, company, format, checkout config and affiliate)
+	Required time.Duration
+
+	// Optional sections degrade to empty results when they miss their budget
+	OrderBumps time.Duration
+	Reviews    time.Duration
+	Pixels     time.Duration
+	Plans      time.Duration
+	Discounts  time.Duration
+
+	// ResponseReserve is kept out of the optional budgets when the request has
+	// a deadline, leaving time to persist the checkout and write the response
+	ResponseReserve time.Duration
+}
+
+// optional returns the budget for an optional lookup, shrunk to what is left of
+// the request deadline after the response reserve
+func (b LoadBudgets) optional(ctx context.Context, limit time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return limit
+	}
+
+	remaining := time.Until(deadline) - b.ResponseReserve
+	if remaining <= 0 {
+		// Leave a token budget so the lookup fails fast instead of not running
+		remaining = time.Millisecond
+	}
+	if limit <= 0 || remaining < limit {
+		return remaining
+	}
+	return limit
+}
+
+// pending is the eventual result of a lookup started with load
+type pending[T any] struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	value  T
+	err    error
+}
+
+// load starts fn in its own goroutine under a context bounded by budget
+func load[T any](ctx context.Context, name string, budget time.Duration, fn func(ctx context.Context) (T, error)) *pending[T] {
+	p := &pending[T]{name: name, done: make(chan struct{})}
+	if budget > 0 {
+		p.ctx, p.cancel = context.WithTimeout(ctx, budget)
+	} else {
+		p.ctx, p.cancel = context.WithCancel(ctx)
+	}
+
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.err = fmt.Errorf("%s: panic: %v", name, r)
+			}
+		}()
+		p.value, p.err = fn(p.ctx)
+	}()
+
+	return p
+}
+
+// wait blocks until the lookup finishes or its budget runs out. A lookup that
+// ignores its context is abandoned once the budget is spent.
+func (p *pending[T]) wait() (T, error) {
+	defer p.cancel()
+
+	select {
+	case <-p.done:
+		return p.value, p.err
+	default:
+	}
+
+	select {
+	case <-p.done:
+		return p.value, p.err
+	case <-p.ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("%s: %w", p.name, p.ctx.Err())
+	}
+}
+
+// waitOptional waits for an optional lookup, falling back to the given value
+// when it fails or misses its budget
+func waitOptional[T any](p *pending[T], fallback T) T {
+	value, err := p.wait()
+	if err != nil {
+		log.Printf("Failed to build %s: %v", p.name, err)
+		return fallback
+	}
+	return value
+}
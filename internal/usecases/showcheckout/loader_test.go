@@ -0,0 +1,201 @@
+package showcheckout
+
+import (
+	"context"
+	stdErrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"checkout-go/internal/repositories"
+)
+
+// blockingLookup ignores its context and only returns once release is closed
+func blockingLookup[T any](release <-chan struct{}, value T) func(ctx context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		<-release
+		return value, nil
+	}
+}
+
+func TestWaitReturnsTheLookupResult(t *testing.T) {
+	value, err := load(context.Background(), "offer", time.Second, func(ctx context.Context) (int, error) {
+		return 42, nil
+	}).wait()
+	if err != nil || value != 42 {
+		t.Errorf("wait = %d, %v, want 42", value, err)
+	}
+
+	lookupErr := stdErrors.New("connection reset")
+	_, err = load(context.Background(), "offer", time.Second, func(ctx context.Context) (int, error) {
+		return 0, lookupErr
+	}).wait()
+	if !stdErrors.Is(err, lookupErr) {
+		t.Errorf("wait error = %v, want %v", err, lookupErr)
+	}
+}
+
+func TestWaitAbandonsLookupsThatMissTheirBudget(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := time.Now()
+	value, err := load(context.Background(), "offer", 20*time.Millisecond, blockingLookup(release, 42)).wait()
+	if !stdErrors.Is(err, context.DeadlineExceeded) || value != 0 {
+		t.Fatalf("wait = %d, %v, want DeadlineExceeded", value, err)
+	}
+	if !strings.HasPrefix(err.Error(), "offer: ") {
+		t.Errorf("wait error %q does not name the lookup", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait took %v with a 20ms budget", elapsed)
+	}
+}
+
+func TestWaitStopsWithTheRequestContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// A zero budget is bounded by the request context only
+	ctx, cancel := context.WithCancel(context.Background())
+	p := load(ctx, "offer", 0, blockingLookup(release, 42))
+	cancel()
+	if _, err := p.wait(); !stdErrors.Is(err, context.Canceled) {
+		t.Errorf("wait error = %v, want Canceled", err)
+	}
+
+	// The request deadline wins over a larger budget
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if _, err := load(ctx, "offer", time.Minute, blockingLookup(release, 42)).wait(); !stdErrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait took %v with a 20ms request deadline", elapsed)
+	}
+}
+
+func TestWaitRecoversPanickingLookups(t *testing.T) {
+	_, err := load(context.Background(), "reviews", time.Second, func(ctx context.Context) (int, error) {
+		panic("nil map")
+	}).wait()
+	if err == nil || err.Error() != "reviews: panic: nil map" {
+		t.Errorf("wait error = %v, want the recovered panic", err)
+	}
+}
+
+func TestWaitOptionalFallsBack(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fallback := []string{}
+
+	tests := []struct {
+		name   string
+		lookup func(ctx context.Context) ([]string, error)
+		want   []string
+	}{
+		{
+			name: "success",
+			lookup: func(ctx context.Context) ([]string, error) {
+				return []string{"review"}, nil
+			},
+			want: []string{"review"},
+		},
+		{
+			name: "error",
+			lookup: func(ctx context.Context) ([]string, error) {
+				return nil, stdErrors.New("throttled")
+			},
+			want: fallback,
+		},
+		{
+			name: "panic",
+			lookup: func(ctx context.Context) ([]string, error) {
+				panic("nil map")
+			},
+			want: fallback,
+		},
+		{
+			name:   "missed budget",
+			lookup: blockingLookup(release, []string{"late review"}),
+			want:   fallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := waitOptional(load(context.Background(), "reviews", 20*time.Millisecond, tt.lookup), fallback)
+			if got == nil || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("waitOptional = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionalBudgetKeepsTheResponseReserve(t *testing.T) {
+	budgets := LoadBudgets{ResponseReserve: 100 * time.Millisecond}
+
+	// Without a request deadline the limit is used as is
+	if got := budgets.optional(context.Background(), 300*time.Millisecond); got != 300*time.Millisecond {
+		t.Errorf("optional without a deadline = %v, want 300ms", got)
+	}
+	if got := budgets.optional(context.Background(), 0); got != 0 {
+		t.Errorf("optional without a deadline or limit = %v, want 0", got)
+	}
+
+	tests := []struct {
+		name     string
+		deadline time.Duration
+		limit    time.Duration
+		// want is matched within the time spent computing the budget
+		want time.Duration
+	}{
+		{"limit under the remaining time", time.Minute, 300 * time.Millisecond, 300 * time.Millisecond},
+		{"remaining time under the limit", time.Second, time.Minute, 900 * time.Millisecond},
+		{"no limit", time.Second, 0, 900 * time.Millisecond},
+		{"reserve over the remaining time", 50 * time.Millisecond, time.Second, time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+
+			got := budgets.optional(ctx, tt.limit)
+			if got > tt.want || got < tt.want-50*time.Millisecond {
+				t.Errorf("optional = %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingOffers never answers until release is closed
+type blockingOffers struct {
+	repositories.OffersRepository
+	release chan struct{}
+}
+
+func (r *blockingOffers) FindByUUID(ctx context.Context, uuid string) (*repositories.Offer, error) {
+	<-r.release
+	return nil, nil
+}
+
+func TestExecuteFailsWhenARequiredLookupMissesItsBudget(t *testing.T) {
+	offers := &blockingOffers{release: make(chan struct{})}
+	defer close(offers.release)
+
+	uc := &UseCase{
+		offersRepo: offers,
+		options:    Options{LoadBudgets: LoadBudgets{Required: 20 * time.Millisecond}},
+	}
+
+	started := time.Now()
+	_, err := uc.Execute(context.Background(), &ShowCheckoutRequest{OfferUUID: "366b643f-3ad1-4204-9655-cdd079d2498c"})
+	if !stdErrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Execute error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Execute took %v with a 20ms required budget", elapsed)
+	}
+}
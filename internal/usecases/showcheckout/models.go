@@ -1,5 +1,12 @@
 package showcheckout
 
+import (
+	"net/http"
+	"time"
+
+	"checkout-go/internal/core/valueobjects"
+)
+
 // ShowCheckoutRequest represents the input for the ShowCheckout use case
 type ShowCheckoutRequest struct {
 	OfferUUID   string     `json:"offer_uuid" validate:"required,uuid"`
@@ -12,6 +19,18 @@ type ShowCheckoutRequest struct {
 	Gclid       *string    `json:"gclid,omitempty"`
 	Ttclid      *string    `json:"ttclid,omitempty"`
 	ClickID     *string    `json:"click_id,omitempty"`
+	// CheckoutUUID names a checkout of a previous visit to resume
+	CheckoutUUID *string `json:"checkout_uuid,omitempty" validate:"omitempty,uuid"`
+	// RecoveryToken is the signed token of a cart recovery link
+	RecoveryToken *string `json:"recovery_token,omitempty" validate:"omitempty,max=512"`
+	// PrefillToken is the signed token naming the customer whose details
+	// prefill the form
+	PrefillToken *string `json:"prefill_token,omitempty" validate:"omitempty,max=512"`
+	// Coupon is a coupon code to apply to the prices
+	Coupon *string `json:"coupon,omitempty" validate:"omitempty,max=64"`
+	// PlanUUID names the plan of the offer to preselect instead of the
+	// default one
+	PlanUUID *string `json:"plan_uuid,omitempty" validate:"omitempty,uuid"`
 }
 
 // ClientInfo contains client device and location information
@@ -53,8 +72,34 @@ type ShowCheckoutResponse struct {
 	Company             *ResponseCompany           `json:"company,omitempty"`
 	AffiliateSettings   *ResponseAffiliateSettings `json:"affiliate_settings,omitempty"`
 	Customer            *ResponseCustomer          `json:"customer,omitempty"`
+	Coupon              *ResponseCoupon            `json:"coupon,omitempty"`
 	Plans               []ResponsePlan             `json:"plans,omitempty"`
+	PaymentPrices       []ResponsePaymentPrice     `json:"payment_prices,omitempty"`
+	Installments        []ResponseInstallment      `json:"installments,omitempty"`
 	GooglePayMerchantID *string                    `json:"google_pay_merchant_id,omitempty"`
+	// CheckoutCookie must be set on the visitor so the next visit resumes
+	// the checkout; it is not part of the body
+	CheckoutCookie *CheckoutCookie `json:"-"`
+}
+
+// CheckoutCookie is the per-offer cookie holding the visitor's checkout
+type CheckoutCookie struct {
+	Name   string
+	Value  string
+	MaxAge time.Duration
+}
+
+// HTTPCookie returns the cookie to send in a Set-Cookie header
+func (c *CheckoutCookie) HTTPCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     "/",
+		MaxAge:   int(c.MaxAge / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
 }
 
 // CheckoutConfig contains checkout configuration settings
@@ -110,24 +155,27 @@ type CheckoutConfig struct {
 
 // ResponseOrderBump represents an order bump offer
 type ResponseOrderBump struct {
-	UUID        string  `json:"uuid"`
-	ProductName string  `json:"product_name"`
-	Name        string  `json:"name"`
-	Tag         string  `json:"tag"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Photo       *string `json:"photo,omitempty"`
-	Format      string  `json:"format"`
-	Order       int     `json:"order"`
+	UUID        string             `json:"uuid"`
+	ProductName string             `json:"product_name"`
+	Name        string             `json:"name"`
+	Tag         string             `json:"tag"`
+	Description string             `json:"description"`
+	Price       valueobjects.Money `json:"price"`
+	Photo       *string            `json:"photo,omitempty"`
+	Format      string             `json:"format"`
+	Order       int                `json:"order"`
+	// PaymentPrices is the bump's price for each enabled payment method; a
+	// selected bump adds its price to the total of that method
+	PaymentPrices []ResponsePaymentPrice `json:"payment_prices,omitempty"`
 }
 
 // ResponseProduct represents product information
 type ResponseProduct struct {
-	UUID   string  `json:"uuid"`
-	Name   string  `json:"name"`
-	Price  float64 `json:"price"`
-	Photo  *string `json:"photo,omitempty"`
-	Format string  `json:"format"`
+	UUID   string             `json:"uuid"`
+	Name   string             `json:"name"`
+	Price  valueobjects.Money `json:"price"`
+	Photo  *string            `json:"photo,omitempty"`
+	Format string             `json:"format"`
 }
 
 // ResponseReview represents a customer review
@@ -163,22 +211,78 @@ type ResponseAffiliateSettings struct {
 	CookieLifetime       int    `json:"cookie_lifetime"`
 }
 
-// ResponseCustomer represents customer information
+// ResponseCustomer represents customer information. Masked customers have
+// their email, phone, document and address partially hidden; the form must
+// not submit masked values back.
 type ResponseCustomer struct {
-	// Add customer fields as needed
+	UUID         string                   `json:"uuid,omitempty"`
+	Name         string                   `json:"name"`
+	Email        string                   `json:"email"`
+	Phone        *string                  `json:"phone,omitempty"`
+	Document     *string                  `json:"document,omitempty"`
+	DocumentType *string                  `json:"document_type,omitempty"`
+	Address      *ResponseCustomerAddress `json:"address,omitempty"`
+	Masked       bool                     `json:"masked"`
+}
+
+// ResponseCustomerAddress represents the billing address of a customer
+type ResponseCustomerAddress struct {
+	ZipCode      string  `json:"zip_code"`
+	Street       *string `json:"street,omitempty"`
+	Number       *string `json:"number,omitempty"`
+	Complement   *string `json:"complement,omitempty"`
+	Neighborhood *string `json:"neighborhood,omitempty"`
+	City         string  `json:"city"`
+	State        string  `json:"state"`
+	Country      string  `json:"country"`
 }
 
 // ResponsePlan represents a subscription plan
 type ResponsePlan struct {
-	UUID                    string  `json:"uuid"`
-	Title                   string  `json:"title"`
-	Tag                     *string `json:"tag,omitempty"`
-	Price                   float64 `json:"price"`
-	PromotionalPrice        float64 `json:"promotional_price"`
-	FirstChargePriceEnabled bool    `json:"first_charge_price_enabled"`
-	FirstChargePrice        float64 `json:"first_charge_price"`
-	ChargeFrequency         string  `json:"charge_frequency"`
-	IsDefault               bool    `json:"is_default"`
+	UUID                    string             `json:"uuid"`
+	Title                   string             `json:"title"`
+	Tag                     *string            `json:"tag,omitempty"`
+	Price                   valueobjects.Money `json:"price"`
+	PromotionalPrice        valueobjects.Money `json:"promotional_price"`
+	FirstChargePriceEnabled bool               `json:"first_charge_price_enabled"`
+	FirstChargePrice        valueobjects.Money `json:"first_charge_price"`
+	ChargeFrequency         string             `json:"charge_frequency"`
+	IsDefault               bool               `json:"is_default"`
+	// IsSelected marks the plan the checkout charges: the plan of the link,
+	// or the default one
+	IsSelected bool `json:"is_selected"`
+}
+
+// ResponseCoupon is a coupon code typed by the buyer. A valid coupon has its
+// discount taken off the price of every payment method; an invalid one has
+// a message telling why it was refused.
+type ResponseCoupon struct {
+	Code       string              `json:"code"`
+	Valid      bool                `json:"valid"`
+	Message    *string             `json:"message,omitempty"`
+	Type       string              `json:"type,omitempty"`
+	Percentage float64             `json:"percentage,omitempty"`
+	Discount   *valueobjects.Money `json:"discount,omitempty"`
+}
+
+// ResponsePaymentPrice is the price of the order paid with one payment
+// method after its automatic discount. Without selected order bumps the
+// total is the price.
+type ResponsePaymentPrice struct {
+	Method             string             `json:"method"`
+	DiscountPercentage float64            `json:"discount_percentage"`
+	Price              valueobjects.Money `json:"price"`
+	Discount           valueobjects.Money `json:"discount"`
+	Total              valueobjects.Money `json:"total"`
+}
+
+// ResponseInstallment is one row of the credit card installment table
+type ResponseInstallment struct {
+	Count       int                `json:"count"`
+	Amount      valueobjects.Money `json:"amount"`
+	FirstAmount valueobjects.Money `json:"first_amount"`
+	Total       valueobjects.Money `json:"total"`
+	HasInterest bool               `json:"has_interest"`
 }
 
 // Helper functions for pointer conversion
@@ -0,0 +1,21 @@
+package showcheckout
+
+import (
+	"time"
+
+	"checkout-go/internal/core/recovery"
+)
+
+// Options holds the tunables of the ShowCheckout use case
+type Options struct {
+	LoadBudgets LoadBudgets
+	// ResumeWindow is how long after its last visit a checkout is reused by
+	// the same visitor instead of creating a new one; 0 always creates one
+	ResumeWindow time.Duration
+	// RecoveryTokens verifies the tokens of cart recovery links; nil ignores
+	// the recovery parameter
+	RecoveryTokens *recovery.Signer
+	// InstallmentInterestRate is the monthly interest percentage of
+	// installments for companies without a rate of their own
+	InstallmentInterestRate float64
+}
@@ -0,0 +1,188 @@
+package showcheckout
+
+import (
+	"context"
+	stdErrors "errors"
+	"log"
+	"strings"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/core/valueobjects"
+	"checkout-go/internal/repositories"
+)
+
+// A visitor keeps one checkout per offer: the response sets a cookie named
+// after the offer holding the checkout UUID, and the next visit within the
+// resume window reuses that checkout instead of creating another one. A
+// `checkout` parameter can name the checkout instead, e.g. in links sent
+// to the visitor. A `recovery` parameter holding a signed recovery token
+// reopens the checkout of an abandoned cart regardless of the window and
+// marks it RECOVERED, see markRecovered.
+
+// checkoutCookiePrefix starts the name of the per-offer checkout cookie
+const checkoutCookiePrefix = "checkout."
+
+// errCheckoutNotResumable stops the resume of a checkout finalized meanwhile
+var errCheckoutNotResumable = stdErrors.New("checkout is not resumable")
+
+// resumeSource tells where the UUID of a previous checkout came from
+type resumeSource int
+
+const (
+	resumeFromRecoveryToken resumeSource = iota
+	resumeFromParameter
+	resumeFromCookie
+)
+
+// resumableCheckout is a checkout of a previous visit that may be reused
+type resumableCheckout struct {
+	checkout *entities.Checkout
+	source   resumeSource
+}
+
+// CheckoutCookieName returns the name of the cookie holding the visitor's
+// checkout of an offer
+func CheckoutCookieName(offerUUID string) string {
+	return checkoutCookiePrefix + offerUUID
+}
+
+// findRecoveredCheckout returns the checkout named by a recovery token when
+// it may be reopened
+func (uc *UseCase) findRecoveredCheckout(ctx context.Context, checkoutUUID string, offer *repositories.Offer, req *ShowCheckoutRequest) (*resumableCheckout, error) {
+	checkout, err := uc.checkoutsRepo.FindByUUID(ctx, checkoutUUID)
+	if err != nil {
+		return nil, err
+	}
+	if checkout == nil || !uc.isResumable(checkout, offer, req, resumeFromRecoveryToken, time.Now()) {
+		return nil, nil
+	}
+	return &resumableCheckout{checkout: checkout, source: resumeFromRecoveryToken}, nil
+}
+
+// findResumableCheckout returns the checkout of a previous visit that may be
+// reused, trying the checkout parameter and then the cookie
+func (uc *UseCase) findResumableCheckout(ctx context.Context, req *ShowCheckoutRequest, offer *repositories.Offer) (*resumableCheckout, error) {
+	if uc.options.ResumeWindow <= 0 {
+		return nil, nil
+	}
+
+	type candidate struct {
+		uuid   *string
+		source resumeSource
+	}
+	candidates := []candidate{
+		{req.CheckoutUUID, resumeFromParameter},
+		{uc.getCookie(CheckoutCookieName(offer.UUID), req.Cookie), resumeFromCookie},
+	}
+
+	now := time.Now()
+	for _, candidate := range candidates {
+		if candidate.uuid == nil || !valueobjects.IsValidUUID(*candidate.uuid) {
+			continue
+		}
+
+		checkout, err := uc.checkoutsRepo.FindByUUID(ctx, *candidate.uuid)
+		if err != nil {
+			return nil, err
+		}
+		if checkout != nil && uc.isResumable(checkout, offer, req, candidate.source, now) {
+			return &resumableCheckout{checkout: checkout, source: candidate.source}, nil
+		}
+	}
+	return nil, nil
+}
+
+// recoveryCheckoutUUID returns the checkout named by the recovery token of
+// the request, or nil when there is no valid token
+func (uc *UseCase) recoveryCheckoutUUID(req *ShowCheckoutRequest) *string {
+	if uc.options.RecoveryTokens == nil || req.RecoveryToken == nil {
+		return nil
+	}
+
+	checkoutUUID, err := uc.options.RecoveryTokens.Verify(*req.RecoveryToken, time.Now())
+	if err != nil {
+		log.Printf("Ignoring recovery link for offer %s: %v", req.OfferUUID, err)
+		return nil
+	}
+	return &checkoutUUID
+}
+
+// isResumable checks that a checkout belongs to the offer and the visitor,
+// is not finalized and was visited within the resume window
+func (uc *UseCase) isResumable(checkout *entities.Checkout, offer *repositories.Offer, req *ShowCheckoutRequest, source resumeSource, now time.Time) bool {
+	if checkout.OfferID == nil || *checkout.OfferID != offer.ID {
+		return false
+	}
+	if checkout.IsSaleFinalizedStatus() {
+		return false
+	}
+
+	// The signed token proves the buyer and bounds the link's lifetime
+	if source == resumeFromRecoveryToken {
+		return true
+	}
+	if now.Sub(checkout.UpdatedAt) > uc.options.ResumeWindow {
+		return false
+	}
+
+	// The cookie proves the visitor; a parameter may come from a shared link,
+	// so it must at least come from the same browser
+	if source == resumeFromParameter {
+		previous, current := checkout.UserAgent, req.ClientInfo.UserAgent
+		if previous == nil || current == nil || !strings.EqualFold(*previous, *current) {
+			return false
+		}
+	}
+	return true
+}
+
+// resumeCheckout records the tracking data of the new visit on a resumable
+// checkout, unless it was finalized since it was found. A checkout reopened
+// from a recovery link is marked RECOVERED.
+func (uc *UseCase) resumeCheckout(ctx context.Context, previous *resumableCheckout, tracking entities.CheckoutProps) (*entities.Checkout, error) {
+	return repositories.UpdateCheckoutWithRetry(ctx, uc.checkoutsRepo, previous.checkout.UUID, repositories.DefaultCheckoutUpdateAttempts, func(checkout *entities.Checkout) error {
+		if checkout.IsSaleFinalizedStatus() {
+			return errCheckoutNotResumable
+		}
+		checkout.RefreshTracking(tracking)
+		if tracking.CustomerID != nil {
+			checkout.LinkCustomer(*tracking.CustomerID)
+		}
+		if tracking.PlanUUID != nil {
+			checkout.SelectPlan(*tracking.PlanUUID)
+		}
+		if tracking.CouponCode != nil {
+			if err := checkout.ApplyCoupon(*tracking.CouponCode); err != nil {
+				return err
+			}
+		}
+		if previous.source == resumeFromRecoveryToken {
+			return markRecovered(checkout, time.Now())
+		}
+		return nil
+	})
+}
+
+// markRecovered attributes a visit through a recovery link to the recovery.
+// A checkout the abandoned cart job has not reached yet, still ACCESSED,
+// moves straight to RECOVERED without an abandonment; a checkout already
+// RECOVERED stays as it is.
+func markRecovered(checkout *entities.Checkout, now time.Time) error {
+	if checkout.IsAccessedStatus() || checkout.IsAbandonedCartStatus() {
+		return checkout.MarkRecovered(now)
+	}
+	return nil
+}
+
+// checkoutCookie returns the cookie that lets the visitor resume checkout
+func (uc *UseCase) checkoutCookie(offer *repositories.Offer, checkout *entities.Checkout) *CheckoutCookie {
+	if uc.options.ResumeWindow <= 0 {
+		return nil
+	}
+	return &CheckoutCookie{
+		Name:   CheckoutCookieName(offer.UUID),
+		Value:  checkout.UUID,
+		MaxAge: uc.options.ResumeWindow,
+	}
+}
@@ -0,0 +1,90 @@
+package showcheckout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"checkout-go/internal/core/entities"
+	"checkout-go/internal/infrastructure/memory"
+)
+
+func TestMarkRecovered(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		status     entities.CheckoutStatus
+		wantEvents []entities.CheckoutEventType
+	}{
+		{entities.CheckoutStatusAccessed, []entities.CheckoutEventType{entities.CheckoutRecovered}},
+		{entities.CheckoutStatusAbandonedCart, []entities.CheckoutEventType{entities.CheckoutRecovered}},
+		{entities.CheckoutStatusRecovered, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			checkout := &entities.Checkout{UUID: "366b643f-3ad1-4204-9655-cdd079d2498c", Status: tt.status}
+			if err := markRecovered(checkout, now); err != nil {
+				t.Fatalf("markRecovered: %v", err)
+			}
+
+			if checkout.Status != entities.CheckoutStatusRecovered {
+				t.Errorf("status = %s, want %s", checkout.Status, entities.CheckoutStatusRecovered)
+			}
+			events := checkout.PullEvents()
+			if len(events) != len(tt.wantEvents) {
+				t.Fatalf("recorded %d events, want %d", len(events), len(tt.wantEvents))
+			}
+			for i, event := range events {
+				if event.Type != tt.wantEvents[i] || !event.OccurredAt.Equal(now) {
+					t.Errorf("event %d = %s at %v, want %s at %v", i, event.Type, event.OccurredAt, tt.wantEvents[i], now)
+				}
+			}
+		})
+	}
+}
+
+func TestResumeCheckoutFromRecoveryLink(t *testing.T) {
+	checkouts := memory.NewCheckoutsRepository(memory.NewStore())
+	uc := &UseCase{checkoutsRepo: checkouts}
+
+	offerID := 1
+	accessed := &entities.Checkout{
+		UUID:      "366b643f-3ad1-4204-9655-cdd079d2498c",
+		OfferID:   &offerID,
+		ProductID: 1,
+		Status:    entities.CheckoutStatusAccessed,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	if err := checkouts.Create(context.Background(), accessed); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	previous := &resumableCheckout{checkout: accessed, source: resumeFromRecoveryToken}
+	checkout, err := uc.resumeCheckout(context.Background(), previous, entities.CheckoutProps{})
+	if err != nil {
+		t.Fatalf("resumeCheckout: %v", err)
+	}
+	if checkout.Status != entities.CheckoutStatusRecovered || checkout.AbandonedAt != nil || checkout.RecoveredAt == nil {
+		t.Errorf("checkout = %s abandoned at %v recovered at %v, want RECOVERED without an abandonment", checkout.Status, checkout.AbandonedAt, checkout.RecoveredAt)
+	}
+
+	// A cookie visit does not count as a recovery
+	cookie := &entities.Checkout{
+		UUID:      "8a1f0c4e-2b7d-4d3a-9e5f-6c7b8a9d0e1f",
+		OfferID:   &offerID,
+		ProductID: 1,
+		Status:    entities.CheckoutStatusAccessed,
+	}
+	if err := checkouts.Create(context.Background(), cookie); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	checkout, err = uc.resumeCheckout(context.Background(), &resumableCheckout{checkout: cookie, source: resumeFromCookie}, entities.CheckoutProps{})
+	if err != nil {
+		t.Fatalf("resumeCheckout: %v", err)
+	}
+	if checkout.Status != entities.CheckoutStatusAccessed {
+		t.Errorf("status = %s, want %s", checkout.Status, entities.CheckoutStatusAccessed)
+	}
+}
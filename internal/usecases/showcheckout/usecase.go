@@ -2,6 +2,7 @@ package showcheckout
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"log"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"checkout-go/internal/core/errors"
 	"checkout-go/internal/core/valueobjects"
 	"checkout-go/internal/repositories"
+	"checkout-go/internal/usecases/pricing"
 )
 
 // UseCase implements the ShowCheckout business logic
@@ -29,7 +31,11 @@ type UseCase struct {
 	pixelsRepo                   repositories.PixelsRepository
 	plansRepo                    repositories.PlansRepository
 	discountsRepo                repositories.DiscountsRepository
+	customersRepo                repositories.CustomersRepository
+	unitOfWork                   repositories.UnitOfWorkFactory
+	checkoutCounter              repositories.CheckoutCounter
 	fileDriver                   repositories.FileDriver
+	options                      Options
 }
 
 // NewUseCase creates a new ShowCheckout use case
@@ -48,7 +54,11 @@ func NewUseCase(
 	pixelsRepo repositories.PixelsRepository,
 	plansRepo repositories.PlansRepository,
 	discountsRepo repositories.DiscountsRepository,
+	customersRepo repositories.CustomersRepository,
+	unitOfWork repositories.UnitOfWorkFactory,
+	checkoutCounter repositories.CheckoutCounter, // nil counts checkouts in the checkout transaction
 	fileDriver repositories.FileDriver,
+	options Options,
 ) *UseCase {
 	return &UseCase{
 		offersRepo:                   offersRepo,
@@ -65,7 +75,11 @@ func NewUseCase(
 		pixelsRepo:                   pixelsRepo,
 		plansRepo:                    plansRepo,
 		discountsRepo:                discountsRepo,
+		customersRepo:                customersRepo,
+		unitOfWork:                   unitOfWork,
+		checkoutCounter:              checkoutCounter,
 		fileDriver:                   fileDriver,
+		options:                      options,
 	}
 }
 
@@ -76,8 +90,16 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		return nil, errors.NewDontWorryError(StringPtr("A oferta informada é inválida"))
 	}
 
+	// Every lookup below is cancelled as soon as Execute returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	budgets := uc.options.LoadBudgets
+
 	// Get offer
-	offer, err := uc.offersRepo.FindByUUID(ctx, req.OfferUUID)
+	offer, err := load(ctx, "offer", budgets.Required, func(ctx context.Context) (*repositories.Offer, error) {
+		return uc.offersRepo.FindByUUID(ctx, req.OfferUUID)
+	}).wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find offer: %w", err)
 	}
@@ -86,8 +108,42 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		return nil, errors.NewDontWorryError(StringPtr("Oferta não encontrada"))
 	}
 
+	// Start the lookups that only depend on the offer
+	productLoad := load(ctx, "product", budgets.Required, func(ctx context.Context) (*repositories.Product, error) {
+		return uc.productsRepo.Find(ctx, offer.ProductID)
+	})
+	checkoutConfigLoad := load(ctx, "checkout config", budgets.Required, func(ctx context.Context) (*repositories.CheckoutConfig, error) {
+		return uc.checkoutConfigsRepo.Find(ctx, offer.CheckoutConfigID)
+	})
+	orderBumpsLoad := load(ctx, "order bumps", budgets.optional(ctx, budgets.OrderBumps), func(ctx context.Context) ([]ResponseOrderBump, error) {
+		return uc.buildOrderBumps(ctx, offer)
+	})
+	reviewsLoad := load(ctx, "reviews", budgets.optional(ctx, budgets.Reviews), func(ctx context.Context) ([]ResponseReview, error) {
+		return uc.buildReviews(ctx, offer.CheckoutConfigID)
+	})
+	plansLoad := load(ctx, "plans", budgets.optional(ctx, budgets.Plans), func(ctx context.Context) ([]*repositories.Plan, error) {
+		return uc.plansRepo.FindByOffer(ctx, offer.ID)
+	})
+	var planLoad *pending[*repositories.Plan]
+	if req.PlanUUID != nil {
+		planLoad = load(ctx, "plan", budgets.Required, func(ctx context.Context) (*repositories.Plan, error) {
+			return uc.plansRepo.FindByUuid(ctx, *req.PlanUUID)
+		})
+	}
+	// A recovery link must not lose its recovery to a slow lookup, so its
+	// checkout is required while the cookie and parameter ones are optional
+	var recoveryLoad *pending[*resumableCheckout]
+	if recoveryUUID := uc.recoveryCheckoutUUID(req); recoveryUUID != nil {
+		recoveryLoad = load(ctx, "recovered checkout", budgets.Required, func(ctx context.Context) (*resumableCheckout, error) {
+			return uc.findRecoveredCheckout(ctx, *recoveryUUID, offer, req)
+		})
+	}
+	resumableLoad := load(ctx, "resumable checkout", budgets.optional(ctx, budgets.Required), func(ctx context.Context) (*resumableCheckout, error) {
+		return uc.findResumableCheckout(ctx, req, offer)
+	})
+
 	// Get product
-	product, err := uc.productsRepo.Find(ctx, offer.ProductID)
+	product, err := productLoad.wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find product: %w", err)
 	}
@@ -96,8 +152,35 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		return nil, errors.NewDontWorryError(StringPtr("Produto não encontrado"))
 	}
 
+	// Start the lookups that depend on the product
+	userLoad := load(ctx, "user", budgets.Required, func(ctx context.Context) (*repositories.User, error) {
+		return uc.usersRepo.Find(ctx, product.UserID)
+	})
+	companyLoad := load(ctx, "company", budgets.Required, func(ctx context.Context) (*repositories.Company, error) {
+		return uc.companiesRepo.Find(ctx, product.CompanyID)
+	})
+	formatLoad := load(ctx, "product format", budgets.Required, func(ctx context.Context) (*repositories.Format, error) {
+		return uc.formatsRepo.Find(ctx, product.FormatID)
+	})
+	affiliateLoad := load(ctx, "affiliate", budgets.Required, func(ctx context.Context) (*affiliateResolution, error) {
+		return uc.resolveAffiliate(ctx, req, product)
+	})
+	discountsLoad := load(ctx, "discounts", budgets.optional(ctx, budgets.Discounts), func(ctx context.Context) (bool, error) {
+		return uc.discountsRepo.CheckHasDiscounts(ctx, product.ID)
+	})
+	var couponLoad *pending[*repositories.Discount]
+	couponCode := ""
+	if req.Coupon != nil {
+		couponCode = normalizeCouponCode(*req.Coupon)
+	}
+	if couponCode != "" {
+		couponLoad = load(ctx, "coupon", budgets.optional(ctx, budgets.Discounts), func(ctx context.Context) (*repositories.Discount, error) {
+			return uc.findCoupon(ctx, couponCode, product.ID, offer.ID)
+		})
+	}
+
 	// Get user
-	user, err := uc.usersRepo.Find(ctx, product.UserID)
+	user, err := userLoad.wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
@@ -112,7 +195,7 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 	}
 
 	// Get company
-	company, err := uc.companiesRepo.Find(ctx, product.CompanyID)
+	company, err := companyLoad.wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find company: %w", err)
 	}
@@ -122,7 +205,7 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 	}
 
 	// Get product format
-	productFormat, err := uc.formatsRepo.Find(ctx, product.FormatID)
+	productFormat, err := formatLoad.wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find product format: %w", err)
 	}
@@ -132,7 +215,7 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 	}
 
 	// Get checkout config
-	checkoutConfig, err := uc.checkoutConfigsRepo.Find(ctx, offer.CheckoutConfigID)
+	checkoutConfig, err := checkoutConfigLoad.wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find checkout config: %w", err)
 	}
@@ -141,62 +224,50 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		return nil, errors.NewDontWorryError(StringPtr("Configuração de checkout não encontrada"))
 	}
 
-	// Handle affiliate logic
-	userID := product.UserID
-	var affiliateID *int
-	var productAffiliateSettings *repositories.ProductAffiliateSettings
-
-	affiliateFromQueryString := req.Aff
-	affiliateCookieID := fmt.Sprintf("aff.%s", product.UUID)
-	affiliateUUIDFromCookie := uc.getCookie(affiliateCookieID, req.Cookie)
-
-	var affiliateUUID *string
-	if affiliateFromQueryString != nil {
-		affiliateUUID = affiliateFromQueryString
-	} else if affiliateUUIDFromCookie != nil {
-		affiliateUUID = affiliateUUIDFromCookie
-	}
-
-	if affiliateUUID != nil {
-		affiliate, err := uc.affiliatesRepo.FindByUUID(ctx, *affiliateUUID)
+	// Get the plan of the link, which must belong to the offer
+	var requestedPlan *repositories.Plan
+	if planLoad != nil {
+		requestedPlan, err = planLoad.wait()
 		if err != nil {
-			log.Printf("Failed to find affiliate: %v", err)
-		} else if affiliate != nil {
-			userAffiliate, err := uc.usersRepo.Find(ctx, affiliate.UserID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find affiliate user: %w", err)
-			}
-
-			if userAffiliate == nil || userAffiliate.Status != repositories.UserStatusActive || userAffiliate.BlockCheckout != repositories.UserBlockCheckoutActive {
-				return nil, errors.NewDontWorryError(StringPtr("Afiliado não encontrado"))
-			}
-
-			productAffiliateSettings, err = uc.productAffiliateSettingsRepo.FindByProduct(ctx, product.ID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find product affiliate settings: %w", err)
-			}
+			return nil, fmt.Errorf("failed to find plan: %w", err)
+		}
 
-			if productAffiliateSettings == nil {
-				return nil, errors.NewDontWorryError(StringPtr("Configurações de afiliação não encontradas"))
-			}
+		if requestedPlan == nil || requestedPlan.OfferID != offer.ID {
+			return nil, errors.NewDontWorryError(StringPtr("Plano não encontrado"))
+		}
+	}
 
-			if productAffiliateSettings.LastOffers == nil || !uc.contains(productAffiliateSettings.LastOffers, req.OfferUUID) {
-				return nil, errors.NewDontWorryError(StringPtr("Afiliado não autorizado"))
-			}
+	// Handle affiliate logic
+	affiliate, err := affiliateLoad.wait()
+	if err != nil {
+		return nil, err
+	}
 
-			affiliateID = &affiliate.ID
-			userID = affiliate.UserID
-		}
+	userID := product.UserID
+	if affiliate.userID != nil {
+		userID = *affiliate.userID
 	}
+	affiliateID := affiliate.affiliateID
+	productAffiliateSettings := affiliate.settings
+
+	// Pixels belong to the affiliate when there is one, so they start last
+	pixelsLoad := load(ctx, "pixels", budgets.optional(ctx, budgets.Pixels), func(ctx context.Context) ([]ResponsePixel, error) {
+		return uc.buildPixels(ctx, userID, product.ID)
+	})
 
 	// Extract pixel data
 	pixelData := uc.extractPixelData(req)
 
+	// A prefill link names the customer of the checkout
+	prefillCustomerID := uc.prefillCustomerID(req)
+
 	// Create checkout
-	checkout := entities.NewCheckout(entities.CheckoutProps{
+	checkoutProps := entities.CheckoutProps{
 		OfferID:        &offer.ID,
 		ProductID:      product.ID,
 		AffiliateID:    affiliateID,
+		CustomerID:     prefillCustomerID,
+		PlanUUID:       req.PlanUUID,
 		Currency:       product.Currency,
 		UserAgent:      req.ClientInfo.UserAgent,
 		OS:             req.ClientInfo.OS,
@@ -217,48 +288,80 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		UTMContent:     req.UTMInfo.UTMContent,
 		PixelData:      pixelData,
 		OriginalURL:    req.OriginalURL,
-	})
-
-	// Debug: Log checkout details before saving
-	fmt.Printf("DEBUG: Creating checkout with UUID: '%s', ProductID: %d\n", checkout.UUID, checkout.ProductID)
-	
-	// Save checkout
-	if err := uc.checkoutsRepo.Create(ctx, checkout); err != nil {
-		return nil, fmt.Errorf("failed to create checkout: %w", err)
 	}
 
-	// Increment checkout count
-	if err := uc.offersRepo.IncrementCheckoutCount(ctx, req.OfferUUID); err != nil {
-		log.Printf("Failed to increment checkout count: %v", err)
+	// A valid coupon of the request is recorded on the checkout; an invalid
+	// one is reported in the response without failing the page
+	var coupon *repositories.Discount
+	var couponErr error
+	if couponLoad != nil {
+		coupon, couponErr = couponLoad.wait()
+		if couponErr == nil {
+			checkoutProps.CouponCode = &coupon.Code
+		}
 	}
 
-	// Build order bumps
-	responseOrderBumps, err := uc.buildOrderBumps(ctx, offer)
-	if err != nil {
-		log.Printf("Failed to build order bumps: %v", err)
-		responseOrderBumps = []ResponseOrderBump{}
+	// Reuse the checkout of the visitor's previous visit when possible
+	var checkout *entities.Checkout
+	recovered := false
+	previous := waitOptional(resumableLoad, nil)
+	if recoveryLoad != nil {
+		recoverable, err := recoveryLoad.wait()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find recovered checkout: %w", err)
+		}
+		if recoverable != nil {
+			previous = recoverable
+		}
 	}
-
-	// Build reviews
-	responseReviews, err := uc.buildReviews(ctx, checkoutConfig)
-	if err != nil {
-		log.Printf("Failed to build reviews: %v", err)
-		responseReviews = []ResponseReview{}
+	if previous != nil {
+		checkout, err = uc.resumeCheckout(ctx, previous, checkoutProps)
+		switch {
+		case err == nil:
+			recovered = previous.source == resumeFromRecoveryToken
+		case previous.source == resumeFromRecoveryToken && !stdErrors.Is(err, errCheckoutNotResumable):
+			return nil, fmt.Errorf("failed to recover checkout %s: %w", previous.checkout.UUID, err)
+		default:
+			log.Printf("Failed to resume checkout %s, creating a new one: %v", previous.checkout.UUID, err)
+			checkout = nil
+		}
 	}
 
-	// Build pixels
-	responsePixels, err := uc.buildPixels(ctx, userID, product.ID)
-	if err != nil {
-		log.Printf("Failed to build pixels: %v", err)
-		responsePixels = []ResponsePixel{}
+	if checkout == nil {
+		checkout = entities.NewCheckout(checkoutProps)
+
+		// Save checkout and increment the offer's checkout count atomically,
+		// unless the count is aggregated outside the transaction
+		unitOfWork := uc.unitOfWork.Begin()
+		unitOfWork.CreateCheckout(checkout)
+		if uc.checkoutCounter == nil {
+			unitOfWork.IncrementOfferCheckoutCount(offer.ID)
+		}
+		if err := unitOfWork.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to create checkout: %w", err)
+		}
+		if uc.checkoutCounter != nil {
+			uc.checkoutCounter.Increment(offer.ID)
+		}
 	}
 
-	// Build plans
-	responsePlans, err := uc.buildPlans(ctx, offer.ID)
-	if err != nil {
-		log.Printf("Failed to build plans: %v", err)
-		responsePlans = []ResponsePlan{}
+	// Prefill the form with the customer of the checkout
+	customerLoad := load(ctx, "customer", budgets.optional(ctx, budgets.Required), func(ctx context.Context) (*ResponseCustomer, error) {
+		return uc.buildCustomer(ctx, checkout, prefillCustomerID != nil || recovered)
+	})
+
+	// Collect the optional sections, degrading to empty results
+	responseOrderBumps := waitOptional(orderBumpsLoad, []ResponseOrderBump{})
+	responseReviews := waitOptional(reviewsLoad, []ResponseReview{})
+	responsePixels := waitOptional(pixelsLoad, []ResponsePixel{})
+	plans := waitOptional(plansLoad, nil)
+	plan := requestedPlan
+	if plan == nil {
+		plan = selectedPlan(plans, checkout.PlanUUID)
 	}
+	responsePlans := uc.buildPlans(plans, plan, product.Currency)
+	hasDiscount := waitOptional(discountsLoad, false)
+	responseCustomer := waitOptional(customerLoad, nil)
 
 	// Build affiliate settings
 	var affiliateSettings *ResponseAffiliateSettings
@@ -302,24 +405,13 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		}
 	}
 
-	// Check for discounts
-	hasDiscount, err := uc.discountsRepo.CheckHasDiscounts(ctx, product.ID)
-	if err != nil {
-		log.Printf("Failed to check discounts: %v", err)
-		hasDiscount = false
-	}
-
-	// Determine payment options
-	creditCardEnabled := checkoutConfig.CreditCardEnabled && (!uc.isProduction() || company.MovingpayEcID != "")
-	applePayEnabled := checkoutConfig.ApplePayEnabled && offer.BillingType == repositories.OfferBillingTypeOneTime
-	googlePayEnabled := checkoutConfig.GooglePayEnabled && offer.BillingType == repositories.OfferBillingTypeOneTime
-
 	// Build response
 	response := &ShowCheckoutResponse{
 		BillingType:         offer.BillingType,
 		IsFree:              offer.IsFree,
 		BackRedirectURL:     uc.getBackRedirectURL(offer),
 		GooglePayMerchantID: uc.getGooglePayMerchantID(checkoutConfig),
+		CheckoutCookie:      uc.checkoutCookie(offer, checkout),
 		Config: CheckoutConfig{
 			CheckoutUUID:                checkout.GetUUID(),
 			CheckoutDate:                checkout.CreatedAt.Format(time.RFC3339),
@@ -339,20 +431,6 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 			AdsText:                     uc.getAdsText(checkoutConfig),
 			CPFEnabled:                  checkoutConfig.CPFEnabled,
 			CNPJEnabled:                 checkoutConfig.CNPJEnabled,
-			BankSlipEnabled:             checkoutConfig.BankSlipEnabled && offer.BillingType == repositories.OfferBillingTypeOneTime,
-			CreditCardEnabled:           creditCardEnabled,
-			PixEnabled:                  checkoutConfig.PixEnabled,
-			NupayEnabled:                false, // TODO: Enable Nupay
-			PicpayEnabled:               checkoutConfig.PicpayEnabled && offer.BillingType == repositories.OfferBillingTypeOneTime,
-			ApplePayEnabled:             applePayEnabled,
-			GooglePayEnabled:            googlePayEnabled,
-			AutomaticDiscountBankSlip:   checkoutConfig.AutomaticDiscountBankSlip,
-			AutomaticDiscountCreditCard: checkoutConfig.AutomaticDiscountCreditCard,
-			AutomaticDiscountPix:        checkoutConfig.AutomaticDiscountPix,
-			AutomaticDiscountNupay:      checkoutConfig.AutomaticDiscountNupay,
-			AutomaticDiscountPicpay:     checkoutConfig.AutomaticDiscountPicpay,
-			AutomaticDiscountApplePay:   checkoutConfig.AutomaticDiscountApplePay,
-			AutomaticDiscountGooglePay:  checkoutConfig.AutomaticDiscountGooglePay,
 			InstallmentsLimit:           checkoutConfig.InstallmentsLimit,
 			PreselectedInstallment:      checkoutConfig.PreselectedInstallment,
 			InterestFreeInstallments:    checkoutConfig.InterestFreeInstallments,
@@ -373,7 +451,7 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		Product: ResponseProduct{
 			UUID:   product.UUID,
 			Name:   product.Name,
-			Price:  uc.databaseToFloat(offer.Price),
+			Price:  valueobjects.NewMoney(offer.Price, product.Currency),
 			Photo:  uc.getProductPhoto(product),
 			Format: productFormat.Slug,
 		},
@@ -381,13 +459,93 @@ func (uc *UseCase) Execute(ctx context.Context, req *ShowCheckoutRequest) (*Show
 		Pixels:            responsePixels,
 		Company:           responseCompany,
 		AffiliateSettings: affiliateSettings,
-		Customer:          nil, // Not implemented in original
+		Customer:          responseCustomer,
 		Plans:             responsePlans,
 	}
 
+	// Determine payment options
+	uc.setPaymentOptions(&response.Config, offer, checkoutConfig, company)
+
+	price := valueobjects.NewMoney(chargedPrice(offer, plan), product.Currency)
+	if couponLoad != nil {
+		response.Coupon = buildCoupon(couponCode, coupon, couponErr, price)
+	}
+
+	// Price the order for each enabled payment method
+	if !offer.IsFree {
+		if err := uc.priceOrder(response, price, checkoutConfig, company); err != nil {
+			return nil, err
+		}
+	}
+
 	return response, nil
 }
 
+// affiliateResolution is the outcome of resolving the affiliate of a request
+type affiliateResolution struct {
+	affiliateID *int
+	userID      *int
+	settings    *repositories.ProductAffiliateSettings
+}
+
+// resolveAffiliate finds the affiliate referenced by the query string or the
+// affiliate cookie and checks that it may sell the requested offer
+func (uc *UseCase) resolveAffiliate(ctx context.Context, req *ShowCheckoutRequest, product *repositories.Product) (*affiliateResolution, error) {
+	resolution := &affiliateResolution{}
+
+	affiliateFromQueryString := req.Aff
+	affiliateCookieID := fmt.Sprintf("aff.%s", product.UUID)
+	affiliateUUIDFromCookie := uc.getCookie(affiliateCookieID, req.Cookie)
+
+	var affiliateUUID *string
+	if affiliateFromQueryString != nil {
+		affiliateUUID = affiliateFromQueryString
+	} else if affiliateUUIDFromCookie != nil {
+		affiliateUUID = affiliateUUIDFromCookie
+	}
+
+	if affiliateUUID == nil {
+		return resolution, nil
+	}
+
+	affiliate, err := uc.affiliatesRepo.FindByUUID(ctx, *affiliateUUID)
+	if err != nil {
+		log.Printf("Failed to find affiliate: %v", err)
+		return resolution, nil
+	}
+	if affiliate == nil {
+		return resolution, nil
+	}
+
+	userAffiliate, err := uc.usersRepo.Find(ctx, affiliate.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find affiliate user: %w", err)
+	}
+
+	if userAffiliate == nil || userAffiliate.Status != repositories.UserStatusActive || userAffiliate.BlockCheckout != repositories.UserBlockCheckoutActive {
+		return nil, errors.NewDontWorryError(StringPtr("Afiliado não encontrado"))
+	}
+
+	productAffiliateSettings, err := uc.productAffiliateSettingsRepo.FindByProduct(ctx, product.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product affiliate settings: %w", err)
+	}
+
+	if productAffiliateSettings == nil {
+		return nil, errors.NewDontWorryError(StringPtr("Configurações de afiliação não encontradas"))
+	}
+
+	if productAffiliateSettings.LastOffers == nil || !uc.contains(productAffiliateSettings.LastOffers, req.OfferUUID) {
+		return nil, errors.NewDontWorryError(StringPtr("Afiliado não autorizado"))
+	}
+
+	resolution.affiliateID = &affiliate.ID
+	resolution.userID = &affiliate.UserID
+	resolution.settings = productAffiliateSettings
+
+	return resolution, nil
+}
+
 // Helper methods
 
 func (uc *UseCase) extractPixelData(req *ShowCheckoutRequest) map[string]interface{} {
@@ -476,10 +634,6 @@ func (uc *UseCase) contains(slice []string, item string) bool {
 	return false
 }
 
-func (uc *UseCase) databaseToFloat(value int64) float64 {
-	return float64(value) / 100.0 // Convert cents to dollars
-}
-
 func (uc *UseCase) getGooglePayMerchantID(checkoutConfig *repositories.CheckoutConfig) *string {
 	if checkoutConfig.GooglePayMerchantID != "" {
 		return &checkoutConfig.GooglePayMerchantID
@@ -542,23 +696,54 @@ func (uc *UseCase) buildOrderBumps(ctx context.Context, offer *repositories.Offe
 		return nil, err
 	}
 
+	// Resolve the offers, products and formats of every bump in one batch
+	// lookup each, instead of three lookups per bump
+	offerIDs := make([]int, 0, len(orderBumps))
 	for _, orderBump := range orderBumps {
-		offeredOffer, err := uc.offersRepo.Find(ctx, orderBump.OfferedOfferID)
-		if err != nil || offeredOffer == nil || offeredOffer.Status != repositories.OfferStatusActive {
+		offerIDs = append(offerIDs, orderBump.OfferedOfferID)
+	}
+	offeredOffers, err := uc.offersRepo.FindMany(ctx, offerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]int, 0, len(offeredOffers))
+	for _, offeredOffer := range offeredOffers {
+		if offeredOffer.Status == repositories.OfferStatusActive {
+			productIDs = append(productIDs, offeredOffer.ProductID)
+		}
+	}
+	products, err := uc.productsRepo.FindMany(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	formatIDs := make([]int, 0, len(products))
+	for _, product := range products {
+		formatIDs = append(formatIDs, product.FormatID)
+	}
+	formats, err := uc.formatsRepo.FindMany(ctx, formatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, orderBump := range orderBumps {
+		offeredOffer := offeredOffers[orderBump.OfferedOfferID]
+		if offeredOffer == nil || offeredOffer.Status != repositories.OfferStatusActive {
 			continue
 		}
 
-		product, err := uc.productsRepo.Find(ctx, offeredOffer.ProductID)
-		if err != nil || product == nil || product.Status != repositories.ProductStatusActive || product.EvaluationStatus == repositories.ProductEvaluationStatusRefused {
+		product := products[offeredOffer.ProductID]
+		if product == nil || product.Status != repositories.ProductStatusActive || product.EvaluationStatus == repositories.ProductEvaluationStatusRefused {
 			continue
 		}
 
-		format, err := uc.formatsRepo.Find(ctx, product.FormatID)
-		if err != nil || format == nil {
+		format := formats[product.FormatID]
+		if format == nil {
 			continue
 		}
 
-		price := uc.databaseToFloat(offeredOffer.Price)
+		price := valueobjects.NewMoney(offeredOffer.Price, product.Currency)
 		photo := uc.getProductPhoto(product)
 
 		responseOrderBumps = append(responseOrderBumps, ResponseOrderBump{
@@ -577,8 +762,8 @@ func (uc *UseCase) buildOrderBumps(ctx context.Context, offer *repositories.Offe
 	return responseOrderBumps, nil
 }
 
-func (uc *UseCase) buildReviews(ctx context.Context, checkoutConfig *repositories.CheckoutConfig) ([]ResponseReview, error) {
-	reviews, err := uc.reviewsRepo.FindByCheckoutConfig(ctx, checkoutConfig.ID)
+func (uc *UseCase) buildReviews(ctx context.Context, checkoutConfigID int) ([]ResponseReview, error) {
+	reviews, err := uc.reviewsRepo.FindByCheckoutConfig(ctx, checkoutConfigID)
 	if err != nil {
 		return nil, err
 	}
@@ -641,12 +826,7 @@ func (uc *UseCase) buildPixels(ctx context.Context, userID, productID int) ([]Re
 	return responsePixels, nil
 }
 
-func (uc *UseCase) buildPlans(ctx context.Context, offerID int) ([]ResponsePlan, error) {
-	plans, err := uc.plansRepo.FindByOffer(ctx, offerID)
-	if err != nil {
-		return nil, err
-	}
-
+func (uc *UseCase) buildPlans(plans []*repositories.Plan, selected *repositories.Plan, currency string) []ResponsePlan {
 	var responsePlans []ResponsePlan
 	for _, plan := range plans {
 		var tag *string
@@ -658,15 +838,177 @@ func (uc *UseCase) buildPlans(ctx context.Context, offerID int) ([]ResponsePlan,
 			UUID:                    plan.UUID,
 			Title:                   plan.Title,
 			Tag:                     tag,
-			Price:                   uc.databaseToFloat(plan.Price),
-			PromotionalPrice:        uc.databaseToFloat(plan.PromotionalPrice),
+			Price:                   valueobjects.NewMoney(plan.Price, currency),
+			PromotionalPrice:        valueobjects.NewMoney(plan.PromotionalPrice, currency),
 			FirstChargePriceEnabled: plan.FirstChargePriceEnabled,
-			FirstChargePrice:        uc.databaseToFloat(plan.FirstChargePrice),
+			FirstChargePrice:        valueobjects.NewMoney(plan.FirstChargePrice, currency),
 			ChargeFrequency:         plan.ChargeFrequency,
 			IsDefault:               plan.IsDefault,
+			IsSelected:              selected != nil && plan.UUID == selected.UUID,
+		})
+	}
+
+	return responsePlans
+}
+
+// selectedPlan returns the plan the checkout charges: the plan chosen on the
+// checkout when it is still among plans, else the default one, or nil when
+// the offer has no default plan
+func selectedPlan(plans []*repositories.Plan, planUUID *string) *repositories.Plan {
+	if planUUID != nil {
+		for _, plan := range plans {
+			if plan.UUID == *planUUID {
+				return plan
+			}
+		}
+	}
+	for _, plan := range plans {
+		if plan.IsDefault {
+			return plan
+		}
+	}
+	return nil
+}
+
+// chargedPrice returns the price charged now in cents: the first charge of
+// the selected plan when enabled, else its promotional or regular price, or
+// the offer price without a plan
+func chargedPrice(offer *repositories.Offer, plan *repositories.Plan) int64 {
+	switch {
+	case plan == nil:
+		return offer.Price
+	case plan.FirstChargePriceEnabled:
+		return plan.FirstChargePrice
+	case plan.PromotionalPrice > 0 && plan.PromotionalPrice < plan.Price:
+		return plan.PromotionalPrice
+	default:
+		return plan.Price
+	}
+}
+
+// setPaymentOptions sets the payment methods the offer can be paid with and
+// their automatic discounts on config
+func (uc *UseCase) setPaymentOptions(config *CheckoutConfig, offer *repositories.Offer, checkoutConfig *repositories.CheckoutConfig, company *repositories.Company) {
+	oneTime := offer.BillingType == repositories.OfferBillingTypeOneTime
+
+	config.BankSlipEnabled = checkoutConfig.BankSlipEnabled && oneTime
+	config.CreditCardEnabled = checkoutConfig.CreditCardEnabled && (!uc.isProduction() || company.MovingpayEcID != "")
+	config.PixEnabled = checkoutConfig.PixEnabled
+	config.NupayEnabled = false // TODO: Enable Nupay
+	config.PicpayEnabled = checkoutConfig.PicpayEnabled && oneTime
+	config.ApplePayEnabled = checkoutConfig.ApplePayEnabled && oneTime
+	config.GooglePayEnabled = checkoutConfig.GooglePayEnabled && oneTime
+	config.AutomaticDiscountBankSlip = checkoutConfig.AutomaticDiscountBankSlip
+	config.AutomaticDiscountCreditCard = checkoutConfig.AutomaticDiscountCreditCard
+	config.AutomaticDiscountPix = checkoutConfig.AutomaticDiscountPix
+	config.AutomaticDiscountNupay = checkoutConfig.AutomaticDiscountNupay
+	config.AutomaticDiscountPicpay = checkoutConfig.AutomaticDiscountPicpay
+	config.AutomaticDiscountApplePay = checkoutConfig.AutomaticDiscountApplePay
+	config.AutomaticDiscountGooglePay = checkoutConfig.AutomaticDiscountGooglePay
+}
+
+// priceOrder fills the payment method prices of the response and its order
+// bumps, and the installment table of the credit card price. A valid coupon
+// of the response is taken off the price first.
+func (uc *UseCase) priceOrder(response *ShowCheckoutResponse, price valueobjects.Money, checkoutConfig *repositories.CheckoutConfig, company *repositories.Company) error {
+	methods := paymentMethodDiscounts(&response.Config)
+
+	if response.Coupon != nil && response.Coupon.Discount != nil {
+		discounted, err := price.Sub(*response.Coupon.Discount)
+		if err != nil {
+			return fmt.Errorf("failed to apply coupon discount: %w", err)
+		}
+		price = discounted
+	}
+	response.PaymentPrices, response.Installments = uc.quoteOrder(price, nil, methods, checkoutConfig, company)
+	for i := range response.OrderBumps {
+		orderBump := &response.OrderBumps[i]
+		orderBump.PaymentPrices = buildPaymentPrices(pricing.CalculatePaymentPrices(orderBump.Price.Amount(), nil, methods), orderBump.Price.Currency())
+	}
+	return nil
+}
+
+// quoteOrder prices price plus the selected order bumps for each payment
+// method, with the installment table of the credit card total
+func (uc *UseCase) quoteOrder(price valueobjects.Money, orderBumps []valueobjects.Money, methods []pricing.PaymentMethodDiscount, checkoutConfig *repositories.CheckoutConfig, company *repositories.Company) ([]ResponsePaymentPrice, []ResponseInstallment) {
+	bumpAmounts := make([]int64, 0, len(orderBumps))
+	for _, orderBump := range orderBumps {
+		bumpAmounts = append(bumpAmounts, orderBump.Amount())
+	}
+
+	prices := pricing.CalculatePaymentPrices(price.Amount(), bumpAmounts, methods)
+	var installments []ResponseInstallment
+	for _, methodPrice := range prices {
+		if methodPrice.Method == pricing.PaymentMethodCreditCard {
+			installments = uc.buildInstallments(valueobjects.NewMoney(methodPrice.Total, price.Currency()), checkoutConfig, company)
+		}
+	}
+	return buildPaymentPrices(prices, price.Currency()), installments
+}
+
+// paymentMethodDiscounts returns the enabled payment methods of config with
+// their automatic discounts
+func paymentMethodDiscounts(config *CheckoutConfig) []pricing.PaymentMethodDiscount {
+	candidates := []struct {
+		enabled bool
+		method  pricing.PaymentMethodDiscount
+	}{
+		{config.CreditCardEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodCreditCard, Percentage: config.AutomaticDiscountCreditCard}},
+		{config.PixEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodPix, Percentage: config.AutomaticDiscountPix}},
+		{config.BankSlipEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodBankSlip, Percentage: config.AutomaticDiscountBankSlip}},
+		{config.NupayEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodNupay, Percentage: config.AutomaticDiscountNupay}},
+		{config.PicpayEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodPicpay, Percentage: config.AutomaticDiscountPicpay}},
+		{config.ApplePayEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodApplePay, Percentage: config.AutomaticDiscountApplePay}},
+		{config.GooglePayEnabled, pricing.PaymentMethodDiscount{Method: pricing.PaymentMethodGooglePay, Percentage: config.AutomaticDiscountGooglePay}},
+	}
+
+	var methods []pricing.PaymentMethodDiscount
+	for _, candidate := range candidates {
+		if candidate.enabled {
+			methods = append(methods, candidate.method)
+		}
+	}
+	return methods
+}
+
+func buildPaymentPrices(prices []pricing.PaymentPrice, currency string) []ResponsePaymentPrice {
+	responsePrices := make([]ResponsePaymentPrice, 0, len(prices))
+	for _, price := range prices {
+		responsePrices = append(responsePrices, ResponsePaymentPrice{
+			Method:             price.Method,
+			DiscountPercentage: price.DiscountPercentage,
+			Price:              valueobjects.NewMoney(price.Price, currency),
+			Discount:           valueobjects.NewMoney(price.Discount, currency),
+			Total:              valueobjects.NewMoney(price.Total, currency),
 		})
 	}
+	return responsePrices
+}
 
-	return responsePlans, nil
+// buildInstallments returns the installment table of price using the
+// company's interest rate, or the configured default
+func (uc *UseCase) buildInstallments(price valueobjects.Money, checkoutConfig *repositories.CheckoutConfig, company *repositories.Company) []ResponseInstallment {
+	rate := uc.options.InstallmentInterestRate
+	if company.InstallmentInterestRate != nil {
+		rate = *company.InstallmentInterestRate
+	}
+
+	table := pricing.CalculateInstallments(price.Amount(), pricing.InstallmentOptions{
+		Limit:               checkoutConfig.InstallmentsLimit,
+		InterestFree:        checkoutConfig.InterestFreeInstallments,
+		MonthlyInterestRate: rate,
+	})
+
+	responseInstallments := make([]ResponseInstallment, 0, len(table))
+	for _, installment := range table {
+		responseInstallments = append(responseInstallments, ResponseInstallment{
+			Count:       installment.Count,
+			Amount:      valueobjects.NewMoney(installment.Amount, price.Currency()),
+			FirstAmount: valueobjects.NewMoney(installment.FirstAmount, price.Currency()),
+			Total:       valueobjects.NewMoney(installment.Total, price.Currency()),
+			HasInterest: installment.HasInterest,
+		})
+	}
+	return responseInstallments
 }
 